@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"project_template/backend/adapter/middleware"
+	"project_template/backend/usecase/dto"
+)
+
+// APIKeyInteractorInterface は API キーのインタラクターのインターフェースを定義します
+type APIKeyInteractorInterface interface {
+	CreateAPIKey(ctx context.Context, input *dto.CreateAPIKeyInput) (*dto.CreatedAPIKeyOutput, error)
+	ListAPIKeys(ctx context.Context) (*dto.APIKeysOutput, error)
+	RevokeAPIKey(ctx context.Context, input *dto.RevokeAPIKeyInput) error
+}
+
+// APIKeyHandler は API キーの管理に関するHTTPリクエストを処理します
+type APIKeyHandler struct {
+	apiKeyInteractor APIKeyInteractorInterface
+}
+
+// NewAPIKeyHandler はAPIKeyHandlerを生成します
+func NewAPIKeyHandler(apiKeyInteractor APIKeyInteractorInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyInteractor: apiKeyInteractor,
+	}
+}
+
+// CreateAPIKey は API キーを作成するハンドラーです
+// キーそのものはこのレスポンスでのみ返します
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateAPIKeyInput
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, r, errInvalidRequestBody.Wrap(err))
+		return
+	}
+
+	ctx := r.Context()
+	output, err := h.apiKeyInteractor.CreateAPIKey(ctx, &input)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	// キーを含むレスポンスはキャッシュさせない
+	w.Header().Set("Cache-Control", "no-store")
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusCreated, output)
+}
+
+// ListAPIKeys は API キーの一覧を返すハンドラーです
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	output, err := h.apiKeyInteractor.ListAPIKeys(ctx)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, output)
+}
+
+// RevokeAPIKey は API キーを失効させるハンドラーです
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	ctx := r.Context()
+	input := &dto.RevokeAPIKeyInput{ID: vars["id"]}
+	if err := h.apiKeyInteractor.RevokeAPIKey(ctx, input); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
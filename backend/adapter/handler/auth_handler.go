@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"project_template/backend/adapter/middleware"
+	"project_template/backend/usecase/dto"
+)
+
+// jwksCacheControl は公開鍵の一覧のキャッシュの指定です
+// 鍵のローテーションが検証側に反映されるまでの時間の上限になります
+const jwksCacheControl = "public, max-age=300"
+
+// AuthInteractorInterface は認証のインタラクターのインターフェースを定義します
+type AuthInteractorInterface interface {
+	Login(ctx context.Context, input *dto.LoginInput) (*dto.TokenOutput, error)
+	Refresh(ctx context.Context, input *dto.RefreshInput) (*dto.TokenOutput, error)
+	Logout(ctx context.Context, input *dto.LogoutInput) error
+}
+
+// JWKSProvider はアクセストークンの検証に使用する公開鍵の一覧を提供するインターフェースです
+type JWKSProvider interface {
+	JWKS() []byte
+}
+
+// AuthHandler は認証関連のHTTPリクエストを処理します
+type AuthHandler struct {
+	authInteractor AuthInteractorInterface
+	jwks           JWKSProvider
+}
+
+// NewAuthHandler はAuthHandlerを生成します
+func NewAuthHandler(authInteractor AuthInteractorInterface, jwks JWKSProvider) *AuthHandler {
+	return &AuthHandler{
+		authInteractor: authInteractor,
+		jwks:           jwks,
+	}
+}
+
+// Login はメールアドレスとパスワードでログインし、アクセストークンを発行するハンドラーです
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var input dto.LoginInput
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, r, errInvalidRequestBody.Wrap(err))
+		return
+	}
+
+	ctx := r.Context()
+	output, err := h.authInteractor.Login(ctx, &input)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	// アクセストークンを含むレスポンスはキャッシュさせない
+	w.Header().Set("Cache-Control", "no-store")
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, output)
+}
+
+// Refresh はリフレッシュトークンを新しいトークンに置き換え、アクセストークンを再発行するハンドラーです
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var input dto.RefreshInput
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, r, errInvalidRequestBody.Wrap(err))
+		return
+	}
+
+	ctx := r.Context()
+	output, err := h.authInteractor.Refresh(ctx, &input)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, output)
+}
+
+// Logout はリフレッシュトークンの系列を失効させるハンドラーです
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var input dto.LogoutInput
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, r, errInvalidRequestBody.Wrap(err))
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.authInteractor.Logout(ctx, &input); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS はアクセストークンの検証に使用する公開鍵の一覧を返すハンドラーです
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", jwksCacheControl)
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, json.RawMessage(h.jwks.JWKS()))
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/infrastructure/logger"
+)
+
+// problemContentType はRFC 7807のエラーレスポンスのContent-Typeです
+const problemContentType = "application/problem+json; charset=utf-8"
+
+// Problem はRFC 7807形式のエラーレスポンスです
+type Problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Errors   []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// statusByKind はエラーの種類とHTTPステータスコードの対応表です
+var statusByKind = map[apperror.Kind]int{
+	apperror.KindInternal:             http.StatusInternalServerError,
+	apperror.KindInvalidArgument:      http.StatusBadRequest,
+	apperror.KindValidation:           http.StatusUnprocessableEntity,
+	apperror.KindNotFound:             http.StatusNotFound,
+	apperror.KindConflict:             http.StatusConflict,
+	apperror.KindUnauthorized:         http.StatusUnauthorized,
+	apperror.KindForbidden:            http.StatusForbidden,
+	apperror.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperror.KindPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// NewProblem はエラーからレスポンス用のProblemを生成します
+// 内部エラーの詳細はクライアントに返しません
+func NewProblem(r *http.Request, err error) *Problem {
+	// リクエストの処理期限切れはサーバー側の一時的な問題として扱う
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Problem{
+			Type:     "/problems/timeout",
+			Title:    http.StatusText(http.StatusServiceUnavailable),
+			Status:   http.StatusServiceUnavailable,
+			Detail:   "request timed out",
+			Instance: r.URL.RequestURI(),
+		}
+	}
+
+	kind := apperror.KindOf(err)
+	status, ok := statusByKind[kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	p := &Problem{
+		Type:     "/problems/" + kind.String(),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: r.URL.RequestURI(),
+	}
+	// 対応表にない種類も含め、サーバー側のエラーはメッセージを返さない
+	if appErr, ok := apperror.As(err); ok && status < http.StatusInternalServerError {
+		p.Detail = appErr.Message
+		p.Errors = appErr.Fields
+	}
+	return p
+}
+
+// WriteError はエラーをRFC 7807形式のレスポンスとして書き込みます
+// すべてのハンドラーはこの関数を通してエラーを返します
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	p := NewProblem(r, err)
+	if p.Status >= http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Error("request failed", "error", err)
+	}
+	WriteProblem(w, p)
+}
+
+// WriteProblem はProblemをレスポンスとして書き込みます
+func WriteProblem(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
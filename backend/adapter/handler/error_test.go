@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project_template/backend/adapter/handler"
+	"project_template/backend/domain/apperror"
+	"project_template/backend/infrastructure/logger"
+)
+
+// secret はクライアントに返してはならない内部エラーの詳細です
+const secret = "dial tcp 10.0.0.5:3306: access denied for user 'app'"
+
+func TestWriteError(t *testing.T) {
+	fields := []apperror.FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}}
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantType   string
+		wantDetail string
+		wantFields []apperror.FieldError
+		wantLogged bool
+	}{
+		{"internal", apperror.Internal(errors.New(secret)), 500, "/problems/internal", "", nil, true},
+		{"internal message", apperror.New(apperror.KindInternal, secret), 500, "/problems/internal", "", nil, true},
+		{"invalid argument", apperror.InvalidArgument("invalid cursor"), 400, "/problems/invalid-argument", "invalid cursor", nil, false},
+		{"validation", apperror.Validation("validation failed", fields...), 422, "/problems/validation", "validation failed", fields, false},
+		{"not found", apperror.NotFound("user not found"), 404, "/problems/not-found", "user not found", nil, false},
+		{"conflict", apperror.Conflict("email already exists"), 409, "/problems/conflict", "email already exists", nil, false},
+		{"unauthorized", apperror.Unauthorized("authentication is required"), 401, "/problems/unauthorized", "authentication is required", nil, false},
+		{"forbidden", apperror.Forbidden("insufficient permissions"), 403, "/problems/forbidden", "insufficient permissions", nil, false},
+		{"precondition failed", apperror.PreconditionFailed("version mismatch"), 412, "/problems/precondition-failed", "version mismatch", nil, false},
+		{"precondition required", apperror.PreconditionRequired("If-Match is required"), 428, "/problems/precondition-required", "If-Match is required", nil, false},
+		// 原因をラップしても種類とメッセージのみを返す
+		{"wrapped app error", fmt.Errorf("update user: %w", apperror.Conflict("email already exists").Wrap(errors.New(secret))), 409, "/problems/conflict", "email already exists", nil, false},
+		// 型付きでないエラーは内部エラーとして扱う
+		{"plain error", errors.New(secret), 500, "/problems/internal", "", nil, true},
+		{"wrapped plain error", fmt.Errorf("query users: %w", errors.New(secret)), 500, "/problems/internal", "", nil, true},
+		{"canceled", context.Canceled, 500, "/problems/internal", "", nil, true},
+		{"unknown kind", apperror.New(apperror.Kind(99), secret), 500, "/problems/internal", "", nil, true},
+		// 処理期限切れはサーバー側の一時的な問題
+		{"deadline exceeded", context.DeadlineExceeded, 503, "/problems/timeout", "request timed out", nil, true},
+		{"wrapped deadline exceeded", fmt.Errorf("query users: %w", context.DeadlineExceeded), 503, "/problems/timeout", "request timed out", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			ctx := logger.WithContext(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users?limit=1", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			handler.WriteError(rec, req, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/problem+json; charset=utf-8" {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+			var p handler.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if p.Type != tt.wantType || p.Status != tt.wantStatus || p.Title != http.StatusText(tt.wantStatus) ||
+				p.Detail != tt.wantDetail || p.Instance != "/api/v1/users?limit=1" {
+				t.Errorf("problem = %+v, want type %s, status %d, detail %q", p, tt.wantType, tt.wantStatus, tt.wantDetail)
+			}
+			if len(p.Errors) != len(tt.wantFields) || (len(p.Errors) > 0 && p.Errors[0] != tt.wantFields[0]) {
+				t.Errorf("errors = %+v, want %+v", p.Errors, tt.wantFields)
+			}
+			// 内部エラーの詳細はレスポンスに含めず、ログにのみ記録する
+			if strings.Contains(rec.Body.String(), "10.0.0.5") {
+				t.Errorf("body leaks internal error: %s", rec.Body.String())
+			}
+			if logged := strings.Contains(logs.String(), "request failed"); logged != tt.wantLogged {
+				t.Errorf("logged = %v, want %v (%s)", logged, tt.wantLogged, logs.String())
+			}
+		})
+	}
+}
+
+func TestNewProblemCoversEveryKind(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	// 種類を追加した場合に対応表の更新漏れを検出する
+	for kind := apperror.KindInternal; kind <= apperror.KindPreconditionRequired; kind++ {
+		p := handler.NewProblem(req, apperror.New(kind, "message"))
+		if kind != apperror.KindInternal && p.Status == http.StatusInternalServerError {
+			t.Errorf("kind %s is mapped to 500", kind)
+		}
+		if p.Type != "/problems/"+kind.String() {
+			t.Errorf("kind %s type = %q", kind, p.Type)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"project_template/backend/domain/apperror"
+)
+
+var (
+	errPreconditionRequired = apperror.PreconditionRequired("If-Match header with the current ETag is required")
+	errPreconditionFailed   = apperror.PreconditionFailed("If-Match does not match the current ETag")
+)
+
+// versionETag はリソースのバージョンから強いETagを生成します
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch は If-Match ヘッダーから更新・削除の対象とするバージョンを取り出します
+//
+// 他の更新を上書きしないよう、取得時の ETag を1つだけ指定することを必須とします
+//   - ヘッダーがない場合や "*" の場合は 428 Precondition Required
+//   - 弱いETagや複数のETag、このAPIが発行していない値の場合は現在の ETag と一致しないため 412 Precondition Failed
+func parseIfMatch(r *http.Request) (int64, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, errPreconditionRequired
+	}
+
+	tag, ok := strings.CutPrefix(v, `"`)
+	if !ok {
+		return 0, errPreconditionFailed
+	}
+	tag, ok = strings.CutSuffix(tag, `"`)
+	if !ok {
+		return 0, errPreconditionFailed
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		return 0, errPreconditionFailed
+	}
+	return version, nil
+}
+
+// matchIfNoneMatch は If-None-Match ヘッダーが etag に一致するか判定します
+// If-None-Match は弱い比較を行うため、W/ の有無は区別しません
+func matchIfNoneMatch(r *http.Request, etag string) bool {
+	v := r.Header.Get("If-None-Match")
+	if v == "" {
+		return false
+	}
+	for _, tag := range strings.Split(v, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
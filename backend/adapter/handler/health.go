@@ -1,10 +1,123 @@
 package handler
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"net/http"
+	"sync"
+	"time"
+
+	"project_template/backend/adapter/middleware"
+	"project_template/backend/infrastructure/logger"
+)
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+
+	// readiness のレスポンスは認証なしで公開されるため、失敗の詳細はログにのみ記録し、
+	// レスポンスには次の固定の理由を返します
+	healthErrorTimeout     = "timeout"
+	healthErrorUnavailable = "unavailable"
+
+	// defaultReadinessTimeout は各依存先のチェックに許可する最大時間です
+	defaultReadinessTimeout = 2 * time.Second
 )
 
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	_, _ = fmt.Fprint(w, "ok")
+// HealthChecker は readiness の判定対象となる依存先のインターフェースです
+// 新しい依存先を追加する場合はこのインターフェースを実装して登録します
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// ComponentStatus は依存先ごとのチェック結果です
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	// Error は失敗の理由です（timeout または unavailable）
+	Error string `json:"error,omitempty"`
+}
+
+// HealthOutput はヘルスチェックのレスポンスです
+type HealthOutput struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// HealthHandler は liveness / readiness のハンドラーです
+type HealthHandler struct {
+	checkers []HealthChecker
+	timeout  time.Duration
+}
+
+// NewHealthHandler はHealthHandlerを生成します
+func NewHealthHandler(checkers ...HealthChecker) *HealthHandler {
+	return &HealthHandler{
+		checkers: checkers,
+		timeout:  defaultReadinessTimeout,
+	}
+}
+
+// Register は readiness の判定対象を追加します
+func (h *HealthHandler) Register(checker HealthChecker) {
+	h.checkers = append(h.checkers, checker)
+}
+
+// Liveness はプロセスが応答可能であることを返すハンドラーです
+// 依存先の状態には影響されません
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, HealthOutput{Status: healthStatusOK})
+}
+
+// Readiness は登録されたすべての依存先をチェックし、リクエストを受け付け可能か返すハンドラーです
+// 1つでも失敗した場合は 503 を返します
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	output := HealthOutput{
+		Status:     healthStatusOK,
+		Components: make(map[string]ComponentStatus, len(h.checkers)),
+	}
+
+	// 依存先は並行してチェックする
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.checkers {
+		wg.Add(1)
+		go func(c HealthChecker) {
+			defer wg.Done()
+			start := time.Now()
+			err := c.Check(ctx)
+			status := ComponentStatus{
+				Status:    healthStatusOK,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				logger.FromContext(ctx).Warn("Readiness check failed", "component", c.Name(), "error", err)
+				status.Status = healthStatusFail
+				status.Error = healthErrorUnavailable
+				if errors.Is(err, context.DeadlineExceeded) {
+					status.Error = healthErrorTimeout
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			output.Components[c.Name()] = status
+			if err != nil {
+				output.Status = healthStatusFail
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	if output.Status != healthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(code, output)
 }
@@ -0,0 +1,50 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project_template/backend/adapter/handler"
+)
+
+// stubChecker は決まった結果を返す HealthChecker です
+type stubChecker struct {
+	name string
+	err  error
+}
+
+func (c stubChecker) Name() string                    { return c.name }
+func (c stubChecker) Check(ctx context.Context) error { return c.err }
+
+func TestReadinessHidesErrorDetails(t *testing.T) {
+	h := handler.NewHealthHandler(
+		stubChecker{name: "ok"},
+		stubChecker{name: "database", err: errors.New("dial tcp 10.0.0.5:3306: connect: connection refused")},
+		stubChecker{name: "slow", err: context.DeadlineExceeded},
+	)
+	rec := httptest.NewRecorder()
+	h.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+	// 内部のアドレスなどの失敗の詳細はレスポンスに含めない
+	if strings.Contains(rec.Body.String(), "10.0.0.5") {
+		t.Errorf("body exposes error details: %s", rec.Body.String())
+	}
+	var out handler.HealthOutput
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := map[string]string{"ok": "", "database": "unavailable", "slow": "timeout"}
+	for name, reason := range want {
+		if got := out.Components[name].Error; got != reason {
+			t.Errorf("components[%s].error = %q, want %q", name, got, reason)
+		}
+	}
+}
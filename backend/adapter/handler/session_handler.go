@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"project_template/backend/adapter/middleware"
+	"project_template/backend/domain/auth"
+	"project_template/backend/usecase/dto"
+)
+
+// SessionInteractorInterface はセッションのインタラクターのインターフェースを定義します
+type SessionInteractorInterface interface {
+	CreateSession(ctx context.Context, input *dto.CreateSessionInput) (*dto.CreatedSessionOutput, error)
+	ListSessions(ctx context.Context) (*dto.SessionsOutput, error)
+	GetCurrentSession(ctx context.Context) (*dto.CurrentSessionOutput, error)
+	RevokeSession(ctx context.Context, input *dto.RevokeSessionInput) error
+}
+
+// SessionHandler は Cookie 認証のセッション関連のHTTPリクエストを処理します
+type SessionHandler struct {
+	sessionInteractor SessionInteractorInterface
+	cookie            *middleware.SessionCookie
+}
+
+// NewSessionHandler はSessionHandlerを生成します
+func NewSessionHandler(sessionInteractor SessionInteractorInterface, cookie *middleware.SessionCookie) *SessionHandler {
+	return &SessionHandler{
+		sessionInteractor: sessionInteractor,
+		cookie:            cookie,
+	}
+}
+
+// CreateSession はメールアドレスとパスワードでログインし、セッションの Cookie を設定するハンドラーです
+// レスポンスの CSRF トークンは、以降の状態を変更するリクエストの X-CSRF-Token ヘッダーに指定させます
+func (h *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateSessionInput
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, r, errInvalidRequestBody.Wrap(err))
+		return
+	}
+	input.UserAgent = r.UserAgent()
+	input.IPAddress = remoteIP(r)
+
+	ctx := r.Context()
+	output, err := h.sessionInteractor.CreateSession(ctx, &input)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	h.cookie.Set(w, output.Token, output.Session.ExpiresAt)
+	// CSRF トークンを含むレスポンスはキャッシュさせない
+	w.Header().Set("Cache-Control", "no-store")
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusCreated, output)
+}
+
+// ListSessions は認証されたユーザーのセッションの一覧を返すハンドラーです
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	output, err := h.sessionInteractor.ListSessions(ctx)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, output)
+}
+
+// GetCurrentSession はリクエストの認証に使用しているセッションと CSRF トークンを返すハンドラーです
+// CSRF トークンはログインのレスポンスでも返しますが、ページの再読み込み後などはここから取得させます
+func (h *SessionHandler) GetCurrentSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	output, err := h.sessionInteractor.GetCurrentSession(ctx)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	// CSRF トークンを含むレスポンスはキャッシュさせない
+	w.Header().Set("Cache-Control", "no-store")
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, output)
+}
+
+// RevokeSession は認証されたユーザーのセッションを失効させるハンドラーです
+// リクエストの認証に使用しているセッションを失効させた場合は Cookie も削除します（ログアウト）
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	ctx := r.Context()
+	input := &dto.RevokeSessionInput{ID: vars["id"]}
+	if err := h.sessionInteractor.RevokeSession(ctx, input); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	if principal, ok := auth.PrincipalFrom(ctx); ok && principal.SessionID == input.ID {
+		h.cookie.Clear(w)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// remoteIP はリクエストの接続元のIPアドレスを返します
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -4,20 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"project_template/backend/adapter/middleware"
-	"project_template/backend/domain/services"
+	"project_template/backend/domain/apperror"
 	"project_template/backend/usecase/dto"
-	"project_template/backend/usecase/interactor"
+)
+
+// maxRequestBodyBytes はリクエストボディの最大サイズです
+const maxRequestBodyBytes = 1 << 20
+
+var (
+	errInvalidRequestBody    = apperror.InvalidArgument("invalid request body")
+	errInvalidQueryParameter = apperror.InvalidArgument("invalid query parameter")
+	errIncompleteReplacement = apperror.InvalidArgument("name and email are required")
 )
 
 // UserInteractorInterface はユーザーインタラクターのインターフェースを定義します
 type UserInteractorInterface interface {
 	GetUser(ctx context.Context, input *dto.GetUserInput) (*dto.UserOutput, error)
-	GetUsers(ctx context.Context) (*dto.UsersOutput, error)
+	GetUsers(ctx context.Context, input *dto.GetUsersInput) (*dto.UsersOutput, error)
 	CreateUser(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error)
+	UpdateUser(ctx context.Context, input *dto.UpdateUserInput) (*dto.UserOutput, error)
+	DeleteUser(ctx context.Context, input *dto.DeleteUserInput) error
+	RestoreUser(ctx context.Context, input *dto.RestoreUserInput) (*dto.UserOutput, error)
 }
 
 // UserHandler はユーザー関連のHTTPリクエストを処理します
@@ -33,23 +47,27 @@ func NewUserHandler(userInteractor UserInteractorInterface) *UserHandler {
 }
 
 // GetUser はユーザー情報を取得するハンドラーです
+// レスポンスの ETag が If-None-Match に一致する場合は 304 Not Modified を返します
+// クエリパラメータ include_deleted=true で削除済みのユーザーも取得できます
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["id"]
 
+	includeDeleted, err := parseIncludeDeleted(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, errInvalidQueryParameter.Wrap(err))
+		return
+	}
+
 	ctx := r.Context()
 	input := &dto.GetUserInput{
-		ID: userID,
+		ID:             userID,
+		IncludeDeleted: includeDeleted,
 	}
 
 	output, err := h.userInteractor.GetUser(ctx, input)
 	if err != nil {
-		resp := middleware.NewJSONResponse(w)
-		if err == interactor.ErrUserNotFound {
-			resp.Encode(http.StatusNotFound, map[string]string{"error": "user not found"})
-			return
-		}
-		resp.Encode(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		WriteError(w, r, err)
 		return
 	}
 
@@ -57,19 +75,33 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	if output != nil {
 		output.Name = middleware.SanitizeString(output.Name)
 	}
+
+	etag := versionETag(output.Version)
+	w.Header().Set("ETag", etag)
+	if matchIfNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	
 	resp := middleware.NewJSONResponse(w)
 	resp.Encode(http.StatusOK, output)
 }
 
 // GetUsers はユーザー一覧を取得するハンドラーです
+// クエリパラメータ limit, cursor, sort, order, email_domain, name_prefix,
+// created_after, created_before で取得件数・並び順・絞り込み条件を指定できます
+// include_deleted=true の場合は削除済みのユーザーも含めます
 func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	input, err := parseGetUsersInput(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, errInvalidQueryParameter.Wrap(err))
+		return
+	}
 
-	output, err := h.userInteractor.GetUsers(ctx)
+	ctx := r.Context()
+	output, err := h.userInteractor.GetUsers(ctx, input)
 	if err != nil {
-		resp := middleware.NewJSONResponse(w)
-		resp.Encode(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		WriteError(w, r, err)
 		return
 	}
 
@@ -86,12 +118,62 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 	resp.Encode(http.StatusOK, output)
 }
 
+// parseGetUsersInput はクエリパラメータから一覧取得の入力データを生成します
+func parseGetUsersInput(q url.Values) (*dto.GetUsersInput, error) {
+	input := &dto.GetUsersInput{
+		Cursor:      q.Get("cursor"),
+		Sort:        q.Get("sort"),
+		Order:       q.Get("order"),
+		EmailDomain: q.Get("email_domain"),
+		NamePrefix:  middleware.SanitizeString(q.Get("name_prefix")),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		input.Limit = limit
+	}
+	if v := q.Get("created_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, err
+		}
+		input.CreatedAfter = &t
+	}
+	if v := q.Get("created_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, err
+		}
+		input.CreatedBefore = &t
+	}
+	includeDeleted, err := parseIncludeDeleted(q)
+	if err != nil {
+		return nil, err
+	}
+	input.IncludeDeleted = includeDeleted
+
+	return input, nil
+}
+
+// parseIncludeDeleted はクエリパラメータ include_deleted を解析します
+// 未指定の場合は削除済みのユーザーを含めません
+func parseIncludeDeleted(q url.Values) (bool, error) {
+	v := q.Get("include_deleted")
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
 // CreateUser は新規ユーザーを作成するハンドラーです
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var input dto.CreateUserInput
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		resp := middleware.NewJSONResponse(w)
-		resp.Encode(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		WriteError(w, r, errInvalidRequestBody.Wrap(err))
 		return
 	}
 
@@ -101,16 +183,112 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	output, err := h.userInteractor.CreateUser(ctx, &input)
 	if err != nil {
-		resp := middleware.NewJSONResponse(w)
-		// エラーの種類によって適切なステータスコードを返す
-		if err == services.ErrEmailAlreadyExists {
-			resp.Encode(http.StatusBadRequest, map[string]string{"error": "email already exists"})
-			return
-		}
-		resp.Encode(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		WriteError(w, r, err)
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(output.Version))
 	resp := middleware.NewJSONResponse(w)
 	resp.Encode(http.StatusCreated, output)
 }
+
+// UpdateUser はユーザー情報を更新するハンドラーです
+// PUT は全項目の置き換え、PATCH は指定された項目のみの部分更新として扱います
+// If-Match には取得時の ETag を指定する必要があります
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	var input dto.UpdateUserInput
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		WriteError(w, r, errInvalidRequestBody.Wrap(err))
+		return
+	}
+	input.ID = vars["id"]
+	input.Version = version
+
+	// PUTの場合は全項目の指定を必須とする
+	if r.Method == http.MethodPut && (input.Name == nil || input.Email == nil) {
+		WriteError(w, r, errIncompleteReplacement)
+		return
+	}
+
+	// 入力データの正規化
+	if input.Name != nil {
+		name := middleware.SanitizeString(*input.Name)
+		input.Name = &name
+	}
+
+	ctx := r.Context()
+	output, err := h.userInteractor.UpdateUser(ctx, &input)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(output.Version))
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, output)
+}
+
+// DeleteUser はユーザーを削除するハンドラーです
+// If-Match には取得時の ETag を指定する必要があります
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	input := &dto.DeleteUserInput{
+		ID:      vars["id"],
+		Version: version,
+	}
+
+	ctx := r.Context()
+	if err := h.userInteractor.DeleteUser(ctx, input); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreUser は削除済みのユーザーを復元するハンドラーです
+// If-Match には削除済みのユーザーを取得した際の ETag を指定する必要があります
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	input := &dto.RestoreUserInput{
+		ID:      vars["id"],
+		Version: version,
+	}
+
+	ctx := r.Context()
+	output, err := h.userInteractor.RestoreUser(ctx, input)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	// 名前のUTF-8検証と正規化
+	output.Name = middleware.SanitizeString(output.Name)
+
+	w.Header().Set("ETag", versionETag(output.Version))
+	resp := middleware.NewJSONResponse(w)
+	resp.Encode(http.StatusOK, output)
+}
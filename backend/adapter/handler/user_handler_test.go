@@ -0,0 +1,182 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"project_template/backend/adapter/handler"
+	"project_template/backend/domain/repository"
+	"project_template/backend/usecase/dto"
+)
+
+// stubUserInteractor はバージョンを照合して更新する1件のユーザーを持つ UserInteractorInterface です
+type stubUserInteractor struct {
+	user  dto.UserOutput
+	calls int
+}
+
+func newStubUserInteractor() *stubUserInteractor {
+	return &stubUserInteractor{user: dto.UserOutput{ID: "user-1", Name: "User", Email: "user@example.com", Version: 3}}
+}
+
+// update はバージョンが一致する場合のみ更新し、バージョンを進めます
+func (s *stubUserInteractor) update(version int64) (*dto.UserOutput, error) {
+	s.calls++
+	if version != s.user.Version {
+		return nil, repository.ErrUserVersionConflict
+	}
+	s.user.Version++
+	out := s.user
+	return &out, nil
+}
+
+func (s *stubUserInteractor) GetUser(ctx context.Context, input *dto.GetUserInput) (*dto.UserOutput, error) {
+	out := s.user
+	return &out, nil
+}
+
+func (s *stubUserInteractor) GetUsers(ctx context.Context, input *dto.GetUsersInput) (*dto.UsersOutput, error) {
+	out := s.user
+	return &dto.UsersOutput{Users: []*dto.UserOutput{&out}}, nil
+}
+
+func (s *stubUserInteractor) CreateUser(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error) {
+	out := s.user
+	return &out, nil
+}
+
+func (s *stubUserInteractor) UpdateUser(ctx context.Context, input *dto.UpdateUserInput) (*dto.UserOutput, error) {
+	return s.update(input.Version)
+}
+
+func (s *stubUserInteractor) DeleteUser(ctx context.Context, input *dto.DeleteUserInput) error {
+	_, err := s.update(input.Version)
+	return err
+}
+
+func (s *stubUserInteractor) RestoreUser(ctx context.Context, input *dto.RestoreUserInput) (*dto.UserOutput, error) {
+	return s.update(input.Version)
+}
+
+// serveUser はパスパラメータ id を設定してハンドラーを呼び出します
+func serveUser(h http.HandlerFunc, method, ifMatch, ifNoneMatch string) *httptest.ResponseRecorder {
+	var body *strings.Reader
+	if method == http.MethodPut {
+		body = strings.NewReader(`{"name":"Renamed","email":"user@example.com"}`)
+	} else {
+		body = strings.NewReader("")
+	}
+	req := httptest.NewRequest(method, "/api/v1/users/user-1", body)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	req = mux.SetURLVars(req, map[string]string{"id": "user-1"})
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestGetUserETag(t *testing.T) {
+	h := handler.NewUserHandler(newStubUserInteractor())
+
+	rec := serveUser(h.GetUser, http.MethodGet, "", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"3"` {
+		t.Fatalf("GET = %d, ETag %q, want 200 with \"3\"", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{name: "match", ifNoneMatch: `"3"`, want: http.StatusNotModified},
+		{name: "weak match", ifNoneMatch: `W/"3"`, want: http.StatusNotModified},
+		{name: "one of several", ifNoneMatch: `"1", "3"`, want: http.StatusNotModified},
+		{name: "wildcard", ifNoneMatch: `*`, want: http.StatusNotModified},
+		{name: "stale", ifNoneMatch: `"2"`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveUser(h.GetUser, http.MethodGet, "", tt.ifNoneMatch)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Header().Get("ETag") != `"3"` {
+				t.Errorf("ETag = %q, want \"3\"", rec.Header().Get("ETag"))
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 body = %q, want empty", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestConditionalWrites(t *testing.T) {
+	writes := []struct {
+		name   string
+		method string
+		serve  func(*handler.UserHandler) http.HandlerFunc
+		ok     int
+	}{
+		{name: "put", method: http.MethodPut, serve: func(h *handler.UserHandler) http.HandlerFunc { return h.UpdateUser }, ok: http.StatusOK},
+		{name: "delete", method: http.MethodDelete, serve: func(h *handler.UserHandler) http.HandlerFunc { return h.DeleteUser }, ok: http.StatusNoContent},
+		{name: "restore", method: http.MethodPost, serve: func(h *handler.UserHandler) http.HandlerFunc { return h.RestoreUser }, ok: http.StatusOK},
+	}
+	preconditions := []struct {
+		name     string
+		ifMatch  string
+		want     int
+		wantType string
+	}{
+		{name: "missing", ifMatch: "", want: http.StatusPreconditionRequired, wantType: "/problems/precondition-required"},
+		{name: "wildcard", ifMatch: "*", want: http.StatusPreconditionRequired, wantType: "/problems/precondition-required"},
+		{name: "unquoted", ifMatch: "3", want: http.StatusPreconditionFailed, wantType: "/problems/precondition-failed"},
+		{name: "weak", ifMatch: `W/"3"`, want: http.StatusPreconditionFailed, wantType: "/problems/precondition-failed"},
+		{name: "several", ifMatch: `"2", "3"`, want: http.StatusPreconditionFailed, wantType: "/problems/precondition-failed"},
+		{name: "not a version", ifMatch: `"abc"`, want: http.StatusPreconditionFailed, wantType: "/problems/precondition-failed"},
+		{name: "stale", ifMatch: `"2"`, want: http.StatusPreconditionFailed, wantType: "/problems/precondition-failed"},
+	}
+
+	for _, w := range writes {
+		t.Run(w.name, func(t *testing.T) {
+			for _, p := range preconditions {
+				t.Run(p.name, func(t *testing.T) {
+					stub := newStubUserInteractor()
+					rec := serveUser(w.serve(handler.NewUserHandler(stub)), w.method, p.ifMatch, "")
+					if rec.Code != p.want {
+						t.Fatalf("status = %d, want %d: %s", rec.Code, p.want, rec.Body.String())
+					}
+					if !strings.Contains(rec.Body.String(), `"type":"`+p.wantType+`"`) {
+						t.Errorf("body = %s, want type %s", rec.Body.String(), p.wantType)
+					}
+					if rec.Header().Get("ETag") != "" {
+						t.Errorf("ETag = %q on failure, want none", rec.Header().Get("ETag"))
+					}
+					// If-Match が不正な場合はユースケースを呼び出さない
+					if p.name != "stale" && stub.calls != 0 {
+						t.Errorf("interactor called %d times, want 0", stub.calls)
+					}
+				})
+			}
+
+			t.Run("match", func(t *testing.T) {
+				rec := serveUser(w.serve(handler.NewUserHandler(newStubUserInteractor())), w.method, `"3"`, "")
+				if rec.Code != w.ok {
+					t.Fatalf("status = %d, want %d: %s", rec.Code, w.ok, rec.Body.String())
+				}
+				// 更新後のリソースを返す場合は新しい ETag を返す
+				if want := `"4"`; w.ok == http.StatusOK && rec.Header().Get("ETag") != want {
+					t.Errorf("ETag = %q, want %s", rec.Header().Get("ETag"), want)
+				}
+			})
+		})
+	}
+}
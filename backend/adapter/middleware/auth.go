@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+	"project_template/backend/infrastructure/logger"
+)
+
+var (
+	errInvalidAccessToken    = apperror.Unauthorized("invalid access token")
+	errAuthenticationMissing = apperror.Unauthorized("authentication is required")
+)
+
+// TokenVerifier はアクセストークンを検証し、認証された主体を返すインターフェースです
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+// APIKeyVerifier は API キーを検証し、認証された主体を返すインターフェースです
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// Authenticate はリクエストを認証し、認証された主体をコンテキストに設定するミドルウェアです
+//
+// Authorization ヘッダーがある場合は Bearer トークンを検証し、不正な場合は 401 を返します
+// トークンが entity.APIKeyPrefix で始まる場合は API キー、それ以外はアクセストークンとして検証します
+// ヘッダーがない場合はセッションの Cookie で認証します（CSRF 対策の検証を含みます）
+// どちらもないリクエストはそのまま次のハンドラーに渡し、認証が必要かどうかは RequireAuth で判定します
+func Authenticate(verifier TokenVerifier, apiKeys APIKeyVerifier, sessions SessionVerifier, cookie *SessionCookie, writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		withSession := authenticateSession(sessions, cookie, writeError, next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Bearer トークンはブラウザが自動で送信しないため、CSRF 対策は不要
+			if r.Header.Get("Authorization") == "" {
+				withSession.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := BearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_request"`)
+				writeError(w, r, errInvalidAccessToken)
+				return
+			}
+			var principal *auth.Principal
+			var err error
+			if strings.HasPrefix(token, entity.APIKeyPrefix) {
+				// API キーの検証はストレージを参照するため、不正なキー以外のエラーはそのまま返す
+				principal, err = apiKeys.VerifyAPIKey(r.Context(), token)
+				if err != nil && apperror.KindOf(err) == apperror.KindUnauthorized {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				}
+			} else {
+				principal, err = verifier.Verify(r.Context(), token)
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					err = errInvalidAccessToken.Wrap(err)
+				}
+			}
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireAuth は認証されていないリクエストに 401 を返すミドルウェアです
+// Authenticate の内側で使用します
+func RequireAuth(writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := auth.PrincipalFrom(r.Context()); !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, r, errAuthenticationMissing)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BearerToken は Authorization ヘッダーから Bearer 認証のトークンを取り出します
+// 認証方式の名前は大文字小文字を区別しません
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// withPrincipal は認証された主体をコンテキストに設定し、以降のログとリクエストのスパンに主体を含めます
+// API キーの場合はユーザーIDがないため、キーのIDを含めます
+func withPrincipal(ctx context.Context, p *auth.Principal) context.Context {
+	ctx = auth.WithPrincipal(ctx, p)
+	span := trace.SpanFromContext(ctx)
+	if p.APIKeyID != "" {
+		span.SetAttributes(attribute.String("api_key.id", p.APIKeyID))
+		return logger.With(ctx, "api_key_id", p.APIKeyID)
+	}
+	span.SetAttributes(attribute.String("user.id", p.UserID))
+	return logger.With(ctx, "user_id", p.UserID)
+}
@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"project_template/backend/adapter/middleware"
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+	"project_template/backend/infrastructure/logger"
+)
+
+// stubVerifier は決まった主体を返すアクセストークンと API キーの検証です
+type stubVerifier struct {
+	principal *auth.Principal
+}
+
+func (v stubVerifier) Verify(ctx context.Context, token string) (*auth.Principal, error) {
+	return v.principal, nil
+}
+
+func (v stubVerifier) VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	return v.principal, nil
+}
+
+func TestAuthenticateAddsPrincipalToLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		principal *auth.Principal
+		key       string
+		want      string
+	}{
+		{
+			name:      "access token",
+			token:     "eyJ.token",
+			principal: &auth.Principal{UserID: "00000000-0000-0000-0000-000000000001", Role: entity.RoleMember},
+			key:       "user_id",
+			want:      "00000000-0000-0000-0000-000000000001",
+		},
+		{
+			name:      "API key",
+			token:     entity.APIKeyPrefix + "0123456789ab_secret",
+			principal: &auth.Principal{APIKeyID: "key-1", Scopes: []entity.APIKeyScope{entity.ScopeUsersRead}},
+			key:       "api_key_id",
+			want:      "key-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			base := slog.New(slog.NewJSONHandler(&buf, nil))
+			verifier := stubVerifier{principal: tt.principal}
+			authenticate := middleware.Authenticate(verifier, verifier, nil, &middleware.SessionCookie{Name: "session"},
+				func(w http.ResponseWriter, r *http.Request, err error) {
+					t.Errorf("unexpected error: %v", err)
+				})
+			handler := authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logger.FromContext(r.Context()).Info("handled")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			req = req.WithContext(logger.WithContext(req.Context(), base))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("decode log %q: %v", buf.String(), err)
+			}
+			if entry[tt.key] != tt.want {
+				t.Errorf("log %s = %v, want %q", tt.key, entry[tt.key], tt.want)
+			}
+		})
+	}
+}
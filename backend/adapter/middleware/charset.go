@@ -2,10 +2,19 @@ package middleware
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
+	"strings"
 	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"project_template/backend/domain/apperror"
 )
 
+// ErrUnsupportedCharset はリクエストボディの文字コードがUTF-8以外の場合のエラーです
+var ErrUnsupportedCharset = apperror.InvalidArgument("request body must be encoded in UTF-8")
+
 // JSONResponse はJSON形式のレスポンスを表します
 type JSONResponse struct {
 	writer http.ResponseWriter
@@ -25,11 +34,23 @@ func (r *JSONResponse) Encode(statusCode int, data interface{}) error {
 	return json.NewEncoder(r.writer).Encode(data)
 }
 
-// CharsetMiddleware は文字コードを処理するミドルウェアです
-func CharsetMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		next.ServeHTTP(w, r)
-	})
+// Charset はリクエストボディの文字コードを検証するミドルウェアです
+// Content-Type で UTF-8 以外の charset が指定されたリクエストを拒否します
+func Charset(writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ct := r.Header.Get("Content-Type"); ct != "" {
+				_, params, err := mime.ParseMediaType(ct)
+				if err == nil {
+					if cs, ok := params["charset"]; ok && !strings.EqualFold(cs, "utf-8") && !strings.EqualFold(cs, "utf8") {
+						writeError(w, r, ErrUnsupportedCharset)
+						return
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // SanitizeString は文字列を正規化します
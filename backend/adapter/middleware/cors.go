@@ -2,23 +2,48 @@ package middleware
 
 import (
 	"net/http"
-)
 
-// CORS はCORSヘッダーを設定するミドルウェアです
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// CORSヘッダーを設定
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	"github.com/gorilla/mux"
+)
 
-		// OPTIONSリクエスト（プリフライトリクエスト）の場合は早期リターン
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// CORS はCORSヘッダーを設定するミドルウェアを返します
+// allowedOrigins に含まれるオリジン（"*" はすべてのオリジン）からのリクエストにのみ許可を返します
+// 認証情報（Cookie）の送信は明示的に列挙したオリジンにのみ許可し、"*" による許可では許可しません
+func CORS(allowedOrigins []string) mux.MiddlewareFunc {
+	allowAll := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAll = true
 		}
+		allowed[origin] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 許可するオリジンはリクエストごとに異なるため、キャッシュにオリジンを考慮させる
+			w.Header().Add("Vary", "Origin")
+
+			// CORSヘッダーを設定
+			if origin := r.Header.Get("Origin"); origin != "" && (allowAll || allowed[origin]) {
+				if allowed[origin] {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				}
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, If-None-Match, X-CSRF-Token")
+				w.Header().Set("Access-Control-Expose-Headers", "ETag")
+			}
+
+			// OPTIONSリクエスト（プリフライトリクエスト）の場合は早期リターン
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
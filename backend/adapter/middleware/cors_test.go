@@ -0,0 +1,43 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"project_template/backend/adapter/middleware"
+)
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name            string
+		allowed         []string
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{name: "listed origin", allowed: []string{"https://app.example.com"}, origin: "https://app.example.com",
+			wantOrigin: "https://app.example.com", wantCredentials: "true"},
+		{name: "unlisted origin", allowed: []string{"https://app.example.com"}, origin: "https://evil.example.com"},
+		// "*" による許可では認証情報の送信を許可しない
+		{name: "wildcard", allowed: []string{"*"}, origin: "https://evil.example.com", wantOrigin: "*"},
+		{name: "listed with wildcard", allowed: []string{"*", "https://app.example.com"}, origin: "https://app.example.com",
+			wantOrigin: "https://app.example.com", wantCredentials: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.CORS(tt.allowed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
+
+	"project_template/backend/infrastructure/logger"
+)
+
+// RequestLogger はリクエストスコープのロガーをコンテキストに設定するミドルウェアです
+// ロガーにはリクエストID、メソッド、ルートテンプレート、トレースIDが付与されます
+// RequestID と Tracing より内側で、ルートテンプレートを取得するため mux.Router.Use で登録する必要があります
+func RequestLogger(base *slog.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := base.With(
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+				slog.String("method", r.Method),
+				slog.String("route", routeTemplate(r)),
+			)
+			if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+				l = l.With(slog.String("trace_id", sc.TraceID().String()))
+			}
+			next.ServeHTTP(w, r.WithContext(logger.WithContext(r.Context(), l)))
+		})
+	}
+}
+
+// AccessLog はリクエストごとにステータス、バイト数、処理時間をログに出力するミドルウェアです
+// RequestLogger より内側に登録します
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := newResponseRecorder(w)
+
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.FromContext(r.Context()).Log(r.Context(), level, "access",
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+		)
+	})
+}
+
+// routeTemplate はリクエストに一致したルートのテンプレートを返します
+// 一致するルートがない場合はパスをそのまま返します
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return r.URL.Path
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// HTTPMetrics はHTTPリクエストのメトリクスを記録するインターフェースです
+type HTTPMetrics interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// Metrics はリクエストの件数と処理時間をルートテンプレートごとに記録するミドルウェアです
+// ラベルの種類が増えすぎないよう、パスではなくルートテンプレートを使用します
+func Metrics(m HTTPMetrics) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := newResponseRecorder(w)
+
+			next.ServeHTTP(rec, r)
+
+			m.ObserveRequest(r.Method, routeTemplate(r), rec.status, time.Since(start))
+		})
+	}
+}
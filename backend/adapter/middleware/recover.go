@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gorilla/mux"
+
+	"project_template/backend/infrastructure/logger"
+)
+
+// ErrorWriter はエラーをレスポンスとして書き込む関数です
+// handler.WriteError を渡すことで、エラーレスポンスの形式を統一します
+type ErrorWriter func(w http.ResponseWriter, r *http.Request, err error)
+
+// Recover はハンドラー内のパニックを回復し、スタックトレースをログに出力して 500 を返すミドルウェアです
+// レスポンスの書き込みが始まっている場合はステータスを変更できないため、ログ出力のみ行います
+func Recover(writeError ErrorWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := newResponseRecorder(w)
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// http.ErrAbortHandler は意図的な中断のため再度パニックさせる
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				logger.FromContext(r.Context()).Error("panic recovered",
+					"panic", fmt.Sprint(v),
+					"stack", string(debug.Stack()),
+				)
+				if !rec.wroteHeader {
+					writeError(rec, r, fmt.Errorf("panic: %v", v))
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
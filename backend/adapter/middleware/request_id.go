@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader はリクエストIDを受け渡すヘッダー名です
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern はクライアントから受け取るリクエストIDとして許可する形式です
+// ログやヘッダーへの不正な値の混入を防ぐため、英数字と一部の記号のみ許可します
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// requestIDKey はコンテキストにリクエストIDを格納するためのキーです
+type requestIDKey struct{}
+
+// RequestID はリクエストIDを割り当てるミドルウェアです
+// 有効な X-Request-ID ヘッダーがあればそれを引き継ぎ、なければ新たに生成します
+// リクエストIDはコンテキストとレスポンスヘッダーに設定されます
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext はコンテキストに格納されたリクエストIDを返します
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// responseRecorder はステータスコードと書き込んだバイト数を記録する ResponseWriter です
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// newResponseRecorder はresponseRecorderを生成します
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+	}
+}
+
+// WriteHeader はステータスコードを記録して書き込みます
+func (rw *responseRecorder) WriteHeader(status int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.status = status
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write はボディを書き込み、バイト数を記録します
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
+}
+
+// Flush は下位の ResponseWriter が対応していればフラッシュします
+func (rw *responseRecorder) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		rw.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap は http.ResponseController のために下位の ResponseWriter を返します
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
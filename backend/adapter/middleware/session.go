@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/auth"
+)
+
+// CSRFHeader は Cookie のセッションで認証されたリクエストで CSRF トークンを送信させるヘッダーです
+const CSRFHeader = "X-CSRF-Token"
+
+var errInvalidCSRFToken = apperror.Forbidden("missing or invalid CSRF token")
+
+// SessionVerifier はセッショントークンを検証し、認証された主体とセッションの CSRF トークンを返すインターフェースです
+// 存在しない・失効したセッションの場合は Unauthorized のエラーを返します
+type SessionVerifier interface {
+	VerifySession(ctx context.Context, token string) (*auth.Principal, string, error)
+}
+
+// SessionCookie はセッショントークンを保存する Cookie の設定です
+// Cookie は常に HttpOnly で、JavaScript からは読み取れません
+type SessionCookie struct {
+	Name     string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// Set はセッショントークンを Cookie に設定します
+func (c *SessionCookie) Set(w http.ResponseWriter, token string, expiresAt time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.Name,
+		Value:    token,
+		Path:     "/",
+		Domain:   c.Domain,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		Secure:   c.Secure,
+		HttpOnly: true,
+		SameSite: c.SameSite,
+	})
+}
+
+// Clear はブラウザのセッションの Cookie を削除します
+func (c *SessionCookie) Clear(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.Name,
+		Value:    "",
+		Path:     "/",
+		Domain:   c.Domain,
+		MaxAge:   -1,
+		Secure:   c.Secure,
+		HttpOnly: true,
+		SameSite: c.SameSite,
+	})
+}
+
+// Token はリクエストの Cookie からセッショントークンを取り出します
+func (c *SessionCookie) Token(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(c.Name)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+// isSafeMethod は状態を変更しないHTTPメソッドか判定します（CSRF 対策の対象外）
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// authenticateSession は Cookie のセッションでリクエストを認証します
+// セッションが無効な場合は Cookie を削除して認証されていないリクエストとして扱い、
+// 状態を変更するリクエストでは CSRF トークン（同期トークン）を検証します
+func authenticateSession(sessions SessionVerifier, cookie *SessionCookie, writeError ErrorWriter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := cookie.Token(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, csrfToken, err := sessions.VerifySession(r.Context(), token)
+		if err != nil {
+			if apperror.KindOf(err) != apperror.KindUnauthorized {
+				writeError(w, r, err)
+				return
+			}
+			cookie.Clear(w)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !isSafeMethod(r.Method) {
+			sent := r.Header.Get(CSRFHeader)
+			if sent == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(csrfToken)) != 1 {
+				writeError(w, r, errInvalidCSRFToken)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+	})
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Timeout はリクエストのコンテキストに処理の期限を設定するミドルウェアです
+// routeTimeouts にルート名ごとの期限を指定でき、指定のないルートには defaultTimeout を使用します
+// 期限を超えた処理はコンテキスト経由でDBアクセスなどが中断され、エラーとして返されます
+func Timeout(defaultTimeout time.Duration, routeTimeouts map[string]time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := defaultTimeout
+			if route := mux.CurrentRoute(r); route != nil {
+				if d, ok := routeTimeouts[route.GetName()]; ok {
+					timeout = d
+				}
+			}
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"project_template/backend/adapter/middleware"
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+)
+
+func TestTracingRecordsAuthenticatedUser(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	verifier := stubVerifier{principal: &auth.Principal{UserID: "user-1", Role: entity.RoleMember}}
+	router := mux.NewRouter()
+	router.Use(
+		middleware.Tracing(tp.Tracer("test"), propagation.TraceContext{}),
+		middleware.Authenticate(verifier, verifier, nil, &middleware.SessionCookie{Name: "session"},
+			func(w http.ResponseWriter, r *http.Request, err error) {
+				t.Errorf("unexpected error: %v", err)
+			}),
+	)
+	router.HandleFunc("/api/v1/auth/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodDelete)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/auth/sessions/session-1", nil)
+	req.Header.Set("Authorization", "Bearer eyJ.token")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	// パスの ID ではなく、認証されたユーザーの ID を記録する
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["user.id"] != "user-1" {
+		t.Errorf("user.id = %q, want %q (attributes %v)", attrs["user.id"], "user-1", attrs)
+	}
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"project_template/backend/adapter/repository/transaction"
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// apiKeyColumns は API キーの取得で選択するカラムです（scanAPIKey の順序と一致させる）
+const apiKeyColumns = "id, name, prefix, secret_hash, scopes, created_by, created_at, expires_at, last_used_at, revoked_at"
+
+// APIKeyRepository は API キーのリポジトリ実装です
+// スコープは空白区切りの文字列として1つのカラムに保存します
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository はAPIKeyRepositoryを生成します
+func NewAPIKeyRepository(db *sql.DB) domainRepo.APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+// conn は ctx のトランザクション、またはトランザクション外の場合は接続プールを返します
+func (r *APIKeyRepository) conn(ctx context.Context) transaction.Querier {
+	return transaction.Conn(ctx, r.db)
+}
+
+// scanAPIKey は1行を API キーに変換します
+func scanAPIKey(row rowScanner) (*entity.APIKey, error) {
+	var (
+		key    entity.APIKey
+		scopes string
+	)
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.SecretHash,
+		&scopes,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = splitScopes(scopes)
+	return &key, nil
+}
+
+// FindByPrefix は識別用の接頭辞による検索を実装します
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = ?"
+
+	key, err := scanAPIKey(r.conn(ctx).QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // キーが見つからない場合
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// List はすべてのキーの一覧を実装します
+func (r *APIKeyRepository) List(ctx context.Context) ([]*entity.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY created_at DESC, id"
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Create はキーの保存を実装します
+func (r *APIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	query := `INSERT INTO api_keys (id, name, prefix, secret_hash, scopes, created_by, created_at, expires_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var expiresAt interface{}
+	if key.ExpiresAt != nil {
+		expiresAt = *key.ExpiresAt
+	}
+	_, err := r.conn(ctx).ExecContext(
+		ctx,
+		query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.SecretHash,
+		joinScopes(key.Scopes),
+		key.CreatedBy,
+		key.CreatedAt,
+		expiresAt,
+	)
+	return err
+}
+
+// Touch はキーの最終使用日時の更新を実装します
+func (r *APIKeyRepository) Touch(ctx context.Context, id string, lastUsedAt time.Time) error {
+	query := "UPDATE api_keys SET last_used_at = ? WHERE id = ?"
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, lastUsedAt, id)
+	return err
+}
+
+// Revoke はキーの失効を実装します
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	query := "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, revokedAt, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// 既に失効している場合は何もしない
+	var exists bool
+	err = r.conn(ctx).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM api_keys WHERE id = ?)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domainRepo.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// joinScopes はスコープを保存する形式（空白区切り）に変換します
+func joinScopes(scopes []entity.APIKeyScope) string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return strings.Join(s, " ")
+}
+
+// splitScopes は保存されたスコープを変換します
+func splitScopes(s string) []entity.APIKeyScope {
+	fields := strings.Fields(s)
+	scopes := make([]entity.APIKeyScope, len(fields))
+	for i, f := range fields {
+		scopes[i] = entity.APIKeyScope(f)
+	}
+	return scopes
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// APIKeyRepository は API キーをメモリ上に保持するリポジトリ実装です
+type APIKeyRepository struct {
+	mu       sync.RWMutex
+	keys     map[string]*entity.APIKey
+	prefixes map[string]string // 識別用の接頭辞 -> キーID
+}
+
+// NewAPIKeyRepository はAPIKeyRepositoryを生成します
+func NewAPIKeyRepository() domainRepo.APIKeyRepository {
+	return &APIKeyRepository{
+		keys:     make(map[string]*entity.APIKey),
+		prefixes: make(map[string]string),
+	}
+}
+
+// copyAPIKey は保持しているキーのコピーを返します
+func copyAPIKey(key *entity.APIKey) *entity.APIKey {
+	k := *key
+	k.Scopes = append([]entity.APIKeyScope(nil), key.Scopes...)
+	for _, t := range []**time.Time{&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt} {
+		if *t != nil {
+			v := **t
+			*t = &v
+		}
+	}
+	return &k
+}
+
+// FindByPrefix は識別用の接頭辞による検索を実装します
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.prefixes[prefix]
+	if !ok {
+		return nil, nil // キーが見つからない場合
+	}
+	return copyAPIKey(r.keys[id]), nil
+}
+
+// List はすべてのキーの一覧を実装します
+func (r *APIKeyRepository) List(ctx context.Context) ([]*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*entity.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, copyAPIKey(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+// Create はキーの保存を実装します
+func (r *APIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.ID]; ok {
+		return fmt.Errorf("duplicate API key id: %q", key.ID)
+	}
+	if _, ok := r.prefixes[key.Prefix]; ok {
+		return fmt.Errorf("duplicate API key prefix: %q", key.Prefix)
+	}
+
+	stored := copyAPIKey(key)
+	stored.CreatedAt = stored.CreatedAt.Round(time.Second).UTC()
+	if stored.ExpiresAt != nil {
+		expiresAt := stored.ExpiresAt.Round(time.Second).UTC()
+		stored.ExpiresAt = &expiresAt
+	}
+	r.keys[stored.ID] = stored
+	r.prefixes[stored.Prefix] = stored.ID
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.keys, stored.ID)
+		delete(r.prefixes, stored.Prefix)
+	})
+	return nil
+}
+
+// Touch はキーの最終使用日時を更新します
+func (r *APIKeyRepository) Touch(ctx context.Context, id string, lastUsedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil
+	}
+	previous := key.LastUsedAt
+	at := lastUsedAt.Round(time.Second).UTC()
+	key.LastUsedAt = &at
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		key.LastUsedAt = previous
+	})
+	return nil
+}
+
+// Revoke はキーを失効させます
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return domainRepo.ErrAPIKeyNotFound
+	}
+	if key.IsRevoked() {
+		return nil
+	}
+	at := revokedAt.Round(time.Second).UTC()
+	key.RevokedAt = &at
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		key.RevokedAt = nil
+	})
+	return nil
+}
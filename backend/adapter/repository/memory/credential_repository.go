@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// CredentialRepository はユーザーの認証情報をメモリ上に保持するリポジトリ実装です
+//
+// データベースの外部キー制約に相当する確認のため、同じストレージの UserRepository を参照します
+//   - 存在しないユーザーの認証情報の Save は ErrUserNotFound を返す
+//   - 完全に削除されたユーザーの認証情報は返さない
+type CredentialRepository struct {
+	mu          sync.RWMutex
+	users       domainRepo.UserRepository
+	credentials map[string]*entity.Credential
+}
+
+// NewCredentialRepository はCredentialRepositoryを生成します
+func NewCredentialRepository(users domainRepo.UserRepository) domainRepo.CredentialRepository {
+	return &CredentialRepository{
+		users:       users,
+		credentials: make(map[string]*entity.Credential),
+	}
+}
+
+// FindByUserID はユーザーIDによる認証情報の検索を実装します
+func (r *CredentialRepository) FindByUserID(ctx context.Context, userID string) (*entity.Credential, error) {
+	user, err := r.users.FindByID(ctx, userID, domainRepo.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	credential, ok := r.credentials[userID]
+	if !ok || user == nil {
+		return nil, nil // 認証情報が見つからない場合
+	}
+	c := *credential
+	return &c, nil
+}
+
+// Save は認証情報の保存を実装します
+// 既に認証情報が存在する場合は、作成日時を除いて置き換えます
+func (r *CredentialRepository) Save(ctx context.Context, credential *entity.Credential) error {
+	user, err := r.users.FindByID(ctx, credential.UserID, domainRepo.IncludeDeleted)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domainRepo.ErrUserNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *credential
+	stored.CreatedAt = stored.CreatedAt.Round(time.Second).UTC()
+	stored.UpdatedAt = stored.UpdatedAt.Round(time.Second).UTC()
+	current, ok := r.credentials[credential.UserID]
+	if ok {
+		stored.CreatedAt = current.CreatedAt
+	}
+	r.credentials[credential.UserID] = &stored
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if ok {
+			r.credentials[credential.UserID] = current
+		} else {
+			delete(r.credentials, credential.UserID)
+		}
+	})
+	return nil
+}
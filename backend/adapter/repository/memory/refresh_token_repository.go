@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// RefreshTokenRepository はリフレッシュトークンをメモリ上に保持するリポジトリ実装です
+//
+// データベースの外部キー制約に相当する確認のため、同じストレージの UserRepository を参照します
+//   - 存在しないユーザーのトークンの Create は ErrUserNotFound を返す
+//   - 完全に削除されたユーザーのトークンは返さない
+type RefreshTokenRepository struct {
+	mu     sync.RWMutex
+	users  domainRepo.UserRepository
+	tokens map[string]*entity.RefreshToken
+	hashes map[string]string // トークンのハッシュ値 -> トークンID
+}
+
+// NewRefreshTokenRepository はRefreshTokenRepositoryを生成します
+func NewRefreshTokenRepository(users domainRepo.UserRepository) domainRepo.RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		users:  users,
+		tokens: make(map[string]*entity.RefreshToken),
+		hashes: make(map[string]string),
+	}
+}
+
+// copyToken は保持しているトークンのコピーを返します
+func copyToken(token *entity.RefreshToken) *entity.RefreshToken {
+	t := *token
+	if token.UsedAt != nil {
+		usedAt := *token.UsedAt
+		t.UsedAt = &usedAt
+	}
+	if token.RevokedAt != nil {
+		revokedAt := *token.RevokedAt
+		t.RevokedAt = &revokedAt
+	}
+	return &t
+}
+
+// FindByHash はトークンのハッシュ値による検索を実装します
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	r.mu.RLock()
+	id, ok := r.hashes[tokenHash]
+	var token *entity.RefreshToken
+	if ok {
+		token = copyToken(r.tokens[id])
+	}
+	r.mu.RUnlock()
+	if token == nil {
+		return nil, nil // トークンが見つからない場合
+	}
+
+	user, err := r.users.FindByID(ctx, token.UserID, domainRepo.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+	return token, nil
+}
+
+// Create はトークンの保存を実装します
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	user, err := r.users.FindByID(ctx, token.UserID, domainRepo.IncludeDeleted)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domainRepo.ErrUserNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := copyToken(token)
+	stored.ExpiresAt = stored.ExpiresAt.Round(time.Second).UTC()
+	stored.CreatedAt = stored.CreatedAt.Round(time.Second).UTC()
+	r.tokens[stored.ID] = stored
+	r.hashes[stored.TokenHash] = stored.ID
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.tokens, stored.ID)
+		delete(r.hashes, stored.TokenHash)
+	})
+	return nil
+}
+
+// MarkUsed はトークンを使用済みにします
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.IsUsed() || token.IsRevoked() {
+		return domainRepo.ErrRefreshTokenNotActive
+	}
+	at := usedAt.Round(time.Second).UTC()
+	token.UsedAt = &at
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		token.UsedAt = nil
+	})
+	return nil
+}
+
+// RevokeFamily は系列の失効していないトークンをすべて失効させます
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	at := revokedAt.Round(time.Second).UTC()
+	var revoked []*entity.RefreshToken
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && !token.IsRevoked() {
+			token.RevokedAt = &at
+			revoked = append(revoked, token)
+		}
+	}
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, token := range revoked {
+			token.RevokedAt = nil
+		}
+	})
+	return nil
+}
+
+// DeleteExpired は有効期限切れのトークンを削除します
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted []*entity.RefreshToken
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			delete(r.hashes, token.TokenHash)
+			deleted = append(deleted, token)
+		}
+	}
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, token := range deleted {
+			r.tokens[token.ID] = token
+			r.hashes[token.TokenHash] = token.ID
+		}
+	})
+	return int64(len(deleted)), nil
+}
@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// SessionRepository はサーバー側セッションをメモリ上に保持するリポジトリ実装です
+//
+// データベースの外部キー制約に相当する確認のため、同じストレージの UserRepository を参照します
+//   - 存在しないユーザーのセッションの Create は ErrUserNotFound を返す
+//   - 完全に削除されたユーザーのセッションは返さない
+type SessionRepository struct {
+	mu       sync.RWMutex
+	users    domainRepo.UserRepository
+	sessions map[string]*entity.Session
+	hashes   map[string]string // セッショントークンのハッシュ値 -> セッションID
+}
+
+// NewSessionRepository はSessionRepositoryを生成します
+func NewSessionRepository(users domainRepo.UserRepository) domainRepo.SessionRepository {
+	return &SessionRepository{
+		users:    users,
+		sessions: make(map[string]*entity.Session),
+		hashes:   make(map[string]string),
+	}
+}
+
+// userExists はユーザーが完全に削除されていないか確認します
+func (r *SessionRepository) userExists(ctx context.Context, userID string) (bool, error) {
+	user, err := r.users.FindByID(ctx, userID, domainRepo.IncludeDeleted)
+	if err != nil {
+		return false, err
+	}
+	return user != nil, nil
+}
+
+// FindByTokenHash はセッショントークンのハッシュ値による検索を実装します
+func (r *SessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Session, error) {
+	r.mu.RLock()
+	var session *entity.Session
+	if id, ok := r.hashes[tokenHash]; ok {
+		s := *r.sessions[id]
+		session = &s
+	}
+	r.mu.RUnlock()
+	if session == nil {
+		return nil, nil // セッションが見つからない場合
+	}
+
+	exists, err := r.userExists(ctx, session.UserID)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ListByUserID はユーザーのセッションの一覧を実装します
+func (r *SessionRepository) ListByUserID(ctx context.Context, userID string) ([]*entity.Session, error) {
+	exists, err := r.userExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*entity.Session{}
+	if !exists {
+		return sessions, nil
+	}
+
+	r.mu.RLock()
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			s := *session
+			sessions = append(sessions, &s)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastSeenAt.Equal(sessions[j].LastSeenAt) {
+			return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, nil
+}
+
+// Create はセッションの保存を実装します
+func (r *SessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	exists, err := r.userExists(ctx, session.UserID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domainRepo.ErrUserNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *session
+	stored.CreatedAt = stored.CreatedAt.Round(time.Second).UTC()
+	stored.LastSeenAt = stored.LastSeenAt.Round(time.Second).UTC()
+	stored.IdleExpiresAt = stored.IdleExpiresAt.Round(time.Second).UTC()
+	stored.ExpiresAt = stored.ExpiresAt.Round(time.Second).UTC()
+	r.sessions[stored.ID] = &stored
+	r.hashes[stored.TokenHash] = stored.ID
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.sessions, stored.ID)
+		delete(r.hashes, stored.TokenHash)
+	})
+	return nil
+}
+
+// Touch はセッションの最終アクセス日時と無操作による有効期限の更新を実装します
+func (r *SessionRepository) Touch(ctx context.Context, id string, lastSeenAt, idleExpiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil
+	}
+	prevLastSeenAt, prevIdleExpiresAt := session.LastSeenAt, session.IdleExpiresAt
+	session.LastSeenAt = lastSeenAt.Round(time.Second).UTC()
+	session.IdleExpiresAt = idleExpiresAt.Round(time.Second).UTC()
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		session.LastSeenAt, session.IdleExpiresAt = prevLastSeenAt, prevIdleExpiresAt
+	})
+	return nil
+}
+
+// Delete はユーザーのセッションの削除を実装します
+func (r *SessionRepository) Delete(ctx context.Context, userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID {
+		return domainRepo.ErrSessionNotFound
+	}
+	delete(r.sessions, id)
+	delete(r.hashes, session.TokenHash)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.sessions[id] = session
+		r.hashes[session.TokenHash] = id
+	})
+	return nil
+}
+
+// DeleteExpired は失効したセッションを削除します
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted []*entity.Session
+	for id, session := range r.sessions {
+		if session.IsExpired(before) {
+			delete(r.sessions, id)
+			delete(r.hashes, session.TokenHash)
+			deleted = append(deleted, session)
+		}
+	}
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, session := range deleted {
+			r.sessions[session.ID] = session
+			r.hashes[session.TokenHash] = session.ID
+		}
+	})
+	return int64(len(deleted)), nil
+}
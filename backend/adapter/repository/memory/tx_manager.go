@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// txKey は context に実行中のトランザクションの取り消し記録を格納するキーです
+type txKey struct{}
+
+// undoLog はトランザクション内の変更を取り消す処理を変更順に記録します
+type undoLog struct {
+	undo []func()
+}
+
+// rollback は mark 以降に記録した変更を新しい順に取り消します
+func (l *undoLog) rollback(mark int) {
+	for i := len(l.undo) - 1; i >= mark; i-- {
+		l.undo[i]()
+	}
+	l.undo = l.undo[:mark]
+}
+
+// onRollback は ctx がトランザクション内の場合に、ロールバック時に実行する処理を記録します
+// リポジトリは変更を加えるたびに、その変更を元に戻す処理を記録します
+func onRollback(ctx context.Context, undo func()) {
+	if l, ok := ctx.Value(txKey{}).(*undoLog); ok {
+		l.undo = append(l.undo, undo)
+	}
+}
+
+// TxManager はメモリ上のリポジトリ向けの domainRepo.TxManager の実装です
+//
+// トランザクションは1つずつ順に実行し、fn がエラーを返した場合は変更を取り消します
+// トランザクション外からの変更とは分離されないため、データベースの実装と同じ分離レベルは保証しません
+type TxManager struct {
+	mu sync.Mutex
+}
+
+// NewTxManager はTxManagerを生成します
+func NewTxManager() domainRepo.TxManager {
+	return &TxManager{}
+}
+
+// WithinTx は fn をトランザクション内で実行します
+// すでにトランザクション内の場合は、fn がエラーを返したときに fn の変更のみを取り消します
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if l, ok := ctx.Value(txKey{}).(*undoLog); ok {
+		return withinSavepoint(ctx, l, fn)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l := &undoLog{}
+	return withinSavepoint(context.WithValue(ctx, txKey{}, l), l, fn)
+}
+
+// withinSavepoint は fn を実行し、エラーまたはパニックの場合は fn の変更を取り消します
+func withinSavepoint(ctx context.Context, l *undoLog, fn func(ctx context.Context) error) error {
+	mark := len(l.undo)
+	defer func() {
+		if p := recover(); p != nil {
+			l.rollback(mark)
+			panic(p)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		l.rollback(mark)
+		return err
+	}
+	return nil
+}
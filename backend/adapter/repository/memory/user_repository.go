@@ -0,0 +1,368 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/cases"
+
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// UserRepository はユーザーをメモリ上に保持するリポジトリ実装です
+// データベースを用意できないテストやフロントエンド開発向けで、プロセスの終了とともにデータは失われます
+//
+// MySQL実装と同じ振る舞いになるよう、次の点を合わせています
+//   - メールアドレスの一意性と検索は大文字小文字を区別しない
+//   - 日時は秒単位に丸めて保存する（TIMESTAMP型）
+//   - 名前・メールアドレスによる並び替えと絞り込みは大文字小文字を区別しない
+//   - 存在しないユーザーの Update / Delete は ErrUserNotFound、バージョンが異なる場合は ErrUserVersionConflict を返す
+//   - 削除はソフトデリートで、メールアドレスは削除されていないユーザーの間でのみ一意とする
+//   - TxManager のトランザクション内の変更は、ロールバック時に取り消す
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[string]*entity.User
+	// emails は削除されていないユーザーの正規化したメールアドレスからユーザーIDへの索引です（一意制約に相当）
+	emails map[string]string
+}
+
+// NewUserRepository はUserRepositoryを生成します
+func NewUserRepository() domainRepo.UserRepository {
+	return &UserRepository{
+		users:  make(map[string]*entity.User),
+		emails: make(map[string]string),
+	}
+}
+
+// fold は照合順序（大文字小文字を区別しない）に合わせて文字列を正規化します
+func fold(s string) string {
+	return cases.Fold().String(s)
+}
+
+// clone は保存・返却するユーザーの複製を返します
+// 呼び出し元での変更が保存済みのデータに影響しないようにします
+func clone(user *entity.User) *entity.User {
+	u := *user
+	return &u
+}
+
+// FindByID はIDによるユーザー検索を実装します
+func (r *UserRepository) FindByID(ctx context.Context, id string, opts ...domainRepo.FindOption) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || !visible(user, opts) {
+		return nil, nil // ユーザーが見つからない場合
+	}
+	return clone(user), nil
+}
+
+// FindByEmail はメールアドレスによるユーザー検索を実装します
+func (r *UserRepository) FindByEmail(ctx context.Context, email string, opts ...domainRepo.FindOption) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := fold(email)
+	if id, ok := r.emails[key]; ok {
+		return clone(r.users[id]), nil
+	}
+	// 削除済みのユーザーは索引に含まれないため、すべてのユーザーから探す
+	if domainRepo.HasOption(opts, domainRepo.IncludeDeleted) {
+		for _, user := range r.users {
+			if fold(user.Email) == key {
+				return clone(user), nil
+			}
+		}
+	}
+	return nil, nil // ユーザーが見つからない場合
+}
+
+// FindAll はすべてのユーザーを作成日時の新しい順に取得します
+func (r *UserRepository) FindAll(ctx context.Context, opts ...domainRepo.FindOption) ([]*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*entity.User, 0, len(r.users))
+	for _, user := range r.users {
+		if visible(user, opts) {
+			users = append(users, clone(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return compareUsers(users[i], users[j], domainRepo.UserSortByCreatedAt) > 0
+	})
+	return users, nil
+}
+
+// FindPage は条件に一致するユーザーをキーセットページネーションで取得します
+func (r *UserRepository) FindPage(ctx context.Context, q domainRepo.UserPageQuery) ([]*entity.User, error) {
+	if !q.Sort.Valid() {
+		return nil, fmt.Errorf("unsupported sort key: %q", q.Sort)
+	}
+	sign := -1
+	if q.Order == domainRepo.SortAsc {
+		sign = 1
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var after *entity.User
+	if q.After != nil {
+		after = cursorUser(q.After, q.Sort)
+	}
+
+	var users []*entity.User
+	for _, user := range r.users {
+		if user.IsDeleted() && !q.Filter.IncludeDeleted {
+			continue
+		}
+		if !matchFilter(user, q.Filter) {
+			continue
+		}
+		// 前ページの最終行より後ろの行のみを対象とする
+		if after != nil && sign*compareUsers(user, after, q.Sort) <= 0 {
+			continue
+		}
+		users = append(users, clone(user))
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return sign*compareUsers(users[i], users[j], q.Sort) < 0
+	})
+	if q.Limit >= 0 && len(users) > q.Limit {
+		users = users[:q.Limit]
+	}
+	return users, nil
+}
+
+// Create は新規ユーザーの保存を実装します
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := fold(user.Email)
+	if _, ok := r.emails[key]; ok {
+		return domainRepo.ErrEmailAlreadyExists
+	}
+	if _, ok := r.users[user.ID]; ok {
+		return fmt.Errorf("duplicate user id: %q", user.ID)
+	}
+
+	r.put(normalizeTimes(clone(user)))
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.remove(user.ID)
+	})
+	return nil
+}
+
+// Update はユーザー情報の更新を実装します
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.users[user.ID]
+	if !ok || current.IsDeleted() {
+		return domainRepo.ErrUserNotFound
+	}
+	if current.Version != user.Version {
+		return domainRepo.ErrUserVersionConflict
+	}
+	key := fold(user.Email)
+	if id, ok := r.emails[key]; ok && id != user.ID {
+		return domainRepo.ErrEmailAlreadyExists
+	}
+
+	// 作成日時は更新しない
+	stored := normalizeTimes(clone(user))
+	stored.CreatedAt = current.CreatedAt
+	stored.Version = current.Version + 1
+	r.put(stored)
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.put(current)
+	})
+	user.Version = stored.Version
+	return nil
+}
+
+// Delete はユーザーのソフトデリートを実装します
+func (r *UserRepository) Delete(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.users[id]
+	if !ok || current.IsDeleted() {
+		return domainRepo.ErrUserNotFound
+	}
+	if current.Version != version {
+		return domainRepo.ErrUserVersionConflict
+	}
+
+	now := time.Now()
+	stored := clone(current)
+	stored.DeletedAt = &now
+	stored.UpdatedAt = now
+	stored.Version++
+	r.put(normalizeTimes(stored))
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.put(current)
+	})
+	return nil
+}
+
+// Restore はソフトデリートされたユーザーの復元を実装します
+func (r *UserRepository) Restore(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.users[id]
+	if !ok {
+		return domainRepo.ErrUserNotFound
+	}
+	if !current.IsDeleted() {
+		return domainRepo.ErrUserNotDeleted
+	}
+	if current.Version != version {
+		return domainRepo.ErrUserVersionConflict
+	}
+	// 削除後に同じメールアドレスのユーザーが作成されている場合
+	if _, ok := r.emails[fold(current.Email)]; ok {
+		return domainRepo.ErrEmailAlreadyExists
+	}
+
+	stored := clone(current)
+	stored.DeletedAt = nil
+	stored.UpdatedAt = time.Now()
+	stored.Version++
+	r.put(normalizeTimes(stored))
+	onRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.put(current)
+	})
+	return nil
+}
+
+// Purge はソフトデリートから一定期間が経過したユーザーを完全に削除します
+func (r *UserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, user := range r.users {
+		if user.IsDeleted() && user.DeletedAt.Before(deletedBefore) {
+			r.remove(id)
+			onRollback(ctx, func() {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				r.put(user)
+			})
+			n++
+		}
+	}
+	return n, nil
+}
+
+// put はユーザーを保存し、メールアドレスの索引を更新します
+// 呼び出し元は書き込みロックを取得している必要があります
+func (r *UserRepository) put(user *entity.User) {
+	if current, ok := r.users[user.ID]; ok && !current.IsDeleted() {
+		delete(r.emails, fold(current.Email))
+	}
+	r.users[user.ID] = user
+	if !user.IsDeleted() {
+		r.emails[fold(user.Email)] = user.ID
+	}
+}
+
+// remove はユーザーとメールアドレスの索引を削除します
+// 呼び出し元は書き込みロックを取得している必要があります
+func (r *UserRepository) remove(id string) {
+	if user, ok := r.users[id]; ok {
+		if !user.IsDeleted() {
+			delete(r.emails, fold(user.Email))
+		}
+		delete(r.users, id)
+	}
+}
+
+// visible は opts に従ってユーザーを検索結果に含めるか判定します
+func visible(user *entity.User, opts []domainRepo.FindOption) bool {
+	return !user.IsDeleted() || domainRepo.HasOption(opts, domainRepo.IncludeDeleted)
+}
+
+// normalizeTimes は日時をデータベースに保存した場合と同じ精度・タイムゾーンに揃えます
+func normalizeTimes(user *entity.User) *entity.User {
+	user.CreatedAt = user.CreatedAt.Round(time.Second).UTC()
+	user.UpdatedAt = user.UpdatedAt.Round(time.Second).UTC()
+	if user.DeletedAt != nil {
+		deletedAt := user.DeletedAt.Round(time.Second).UTC()
+		user.DeletedAt = &deletedAt
+	}
+	return user
+}
+
+// cursorUser はカーソルを並び替え項目の比較に使用できるユーザーに変換します
+func cursorUser(c *domainRepo.UserCursor, key domainRepo.UserSortKey) *entity.User {
+	u := &entity.User{ID: c.ID}
+	switch key {
+	case domainRepo.UserSortByName:
+		u.Name = c.StringKey
+	case domainRepo.UserSortByEmail:
+		u.Email = c.StringKey
+	case domainRepo.UserSortByCreatedAt:
+		u.CreatedAt = c.TimeKey
+	case domainRepo.UserSortByUpdatedAt:
+		u.UpdatedAt = c.TimeKey
+	}
+	return u
+}
+
+// compareUsers は並び替え項目、同じ値の場合はIDで2人のユーザーを比較します
+// a が b より前（昇順）の場合は負、後の場合は正の値を返します
+func compareUsers(a, b *entity.User, key domainRepo.UserSortKey) int {
+	var c int
+	switch key {
+	case domainRepo.UserSortByName:
+		c = strings.Compare(fold(a.Name), fold(b.Name))
+	case domainRepo.UserSortByEmail:
+		c = strings.Compare(fold(a.Email), fold(b.Email))
+	case domainRepo.UserSortByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case domainRepo.UserSortByUpdatedAt:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// matchFilter はユーザーが絞り込み条件に一致するか判定します
+func matchFilter(user *entity.User, f domainRepo.UserFilter) bool {
+	if f.EmailDomain != "" && !strings.HasSuffix(fold(user.Email), "@"+fold(f.EmailDomain)) {
+		return false
+	}
+	if f.NamePrefix != "" && !strings.HasPrefix(fold(user.Name), fold(f.NamePrefix)) {
+		return false
+	}
+	if f.CreatedAfter != nil && user.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !user.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// MySQLのエラー番号
+const (
+	// mysqlErrDuplicateEntry は一意制約違反を表します
+	mysqlErrDuplicateEntry = 1062
+	// mysqlErrNoReferencedRow は外部キー制約で参照先の行が存在しないことを表します
+	mysqlErrNoReferencedRow = 1452
+	// mysqlErrLockWaitTimeout は行ロックの待ち時間の超過を表します
+	mysqlErrLockWaitTimeout = 1205
+	// mysqlErrDeadlock はデッドロックによるトランザクションのロールバックを表します
+	mysqlErrDeadlock = 1213
+)
+
+// isDuplicateKey はエラーが指定したインデックスの一意制約違反か判定します
+// MySQL 8.0.19以降はキー名が "テーブル名.インデックス名" の形式で返されるため両方に対応します
+func isDuplicateKey(err error, table, key string) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) || myErr.Number != mysqlErrDuplicateEntry {
+		return false
+	}
+	return strings.HasSuffix(myErr.Message, "'"+key+"'") ||
+		strings.HasSuffix(myErr.Message, "'"+table+"."+key+"'")
+}
+
+// translateUserError はユーザーテーブルへの書き込みエラーをドメインのエラーに変換します
+func translateUserError(err error) error {
+	// メールアドレスは削除されていないユーザーの間でのみ一意（削除済みの場合は NULL になる生成列の一意制約）
+	if isDuplicateKey(err, "users", "uq_users_active_email") {
+		return domainRepo.ErrEmailAlreadyExists.Wrap(err)
+	}
+	return err
+}
+
+// translateUserRefError はユーザーを参照するテーブル（認証情報など）への書き込みエラーをドメインのエラーに変換します
+func translateUserRefError(err error) error {
+	// 参照するユーザーが存在しない（外部キー制約違反）
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == mysqlErrNoReferencedRow {
+		return domainRepo.ErrUserNotFound.Wrap(err)
+	}
+	return err
+}
+
+// IsRetryable はトランザクションを再試行することで解消できるエラーか判定します
+func IsRetryable(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+	return myErr.Number == mysqlErrDeadlock || myErr.Number == mysqlErrLockWaitTimeout
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+
+	"project_template/backend/domain/apperror"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+func TestTranslateUserError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		// MySQL 8.0.19 以降はテーブル名を含むキー名を返す
+		{"duplicate email", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@example.com' for key 'users.uq_users_active_email'"}, true},
+		{"duplicate email (before 8.0.19)", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@example.com' for key 'uq_users_active_email'"}, true},
+		{"wrapped duplicate email", fmt.Errorf("insert user: %w", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.uq_users_active_email'"}), true},
+		{"duplicate primary key", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.PRIMARY'"}, false},
+		{"other mysql error", &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}, false},
+		{"plain error", errors.New("connection reset"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateUserError(tt.err)
+			got := errors.Is(err, domainRepo.ErrEmailAlreadyExists) && apperror.KindOf(err) == apperror.KindConflict
+			if got != tt.want {
+				t.Errorf("translateUserError = %v, want ErrEmailAlreadyExists: %v", err, tt.want)
+			}
+			// 原因となったドライバーのエラーはラップして保持する
+			if !errors.Is(err, tt.err) {
+				t.Errorf("translateUserError lost the cause: %v", err)
+			}
+		})
+	}
+}
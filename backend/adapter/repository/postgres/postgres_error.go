@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// PostgreSQLのエラーコード（SQLSTATE）
+const (
+	// pgErrUniqueViolation は一意制約違反を表します
+	pgErrUniqueViolation = "23505"
+	// pgErrForeignKeyViolation は外部キー制約違反を表します
+	pgErrForeignKeyViolation = "23503"
+	// pgErrSerializationFailure は同時実行のトランザクションとの競合を表します
+	pgErrSerializationFailure = "40001"
+	// pgErrDeadlockDetected はデッドロックを表します
+	pgErrDeadlockDetected = "40P01"
+)
+
+// isUniqueViolation はエラーが指定した制約の一意制約違反か判定します
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgErrUniqueViolation {
+		return false
+	}
+	return pgErr.ConstraintName == constraint
+}
+
+// translateUserError はユーザーテーブルへの書き込みエラーをドメインのエラーに変換します
+func translateUserError(err error) error {
+	if isUniqueViolation(err, "users_email_key") {
+		return domainRepo.ErrEmailAlreadyExists.Wrap(err)
+	}
+	return err
+}
+
+// translateUserRefError はユーザーを参照するテーブル（認証情報など）への書き込みエラーをドメインのエラーに変換します
+func translateUserRefError(err error) error {
+	// 参照するユーザーが存在しない
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgErrForeignKeyViolation {
+		return domainRepo.ErrUserNotFound.Wrap(err)
+	}
+	return err
+}
+
+// IsRetryable はトランザクションを再試行することで解消できるエラーか判定します
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgErrSerializationFailure || pgErr.Code == pgErrDeadlockDetected
+}
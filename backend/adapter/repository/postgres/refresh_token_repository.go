@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"project_template/backend/adapter/repository/transaction"
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// RefreshTokenRepository はPostgreSQLを使用したリフレッシュトークンのリポジトリ実装です
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository はRefreshTokenRepositoryを生成します
+func NewRefreshTokenRepository(db *sql.DB) domainRepo.RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// conn は ctx のトランザクション、またはトランザクション外の場合は接続プールを返します
+func (r *RefreshTokenRepository) conn(ctx context.Context) transaction.Querier {
+	return transaction.Conn(ctx, r.db)
+}
+
+// FindByHash はトークンのハッシュ値による検索を実装します
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	query := `SELECT id, family_id, user_id, token_hash, expires_at, created_at, used_at, revoked_at
+			  FROM refresh_tokens WHERE token_hash = $1`
+
+	var token entity.RefreshToken
+	err := r.conn(ctx).QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.FamilyID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // トークンが見つからない場合
+		}
+		return nil, err
+	}
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.CreatedAt = token.CreatedAt.UTC()
+	if token.UsedAt != nil {
+		usedAt := token.UsedAt.UTC()
+		token.UsedAt = &usedAt
+	}
+	if token.RevokedAt != nil {
+		revokedAt := token.RevokedAt.UTC()
+		token.RevokedAt = &revokedAt
+	}
+
+	return &token, nil
+}
+
+// Create はトークンの保存を実装します
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, family_id, user_id, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.conn(ctx).ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.FamilyID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return translateUserRefError(err)
+	}
+
+	return nil
+}
+
+// MarkUsed はトークンを使用済みにします
+// 同じトークンによる同時のローテーションは、条件付きの更新によりいずれか1つのみが成功します
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	query := "UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domainRepo.ErrRefreshTokenNotActive
+	}
+
+	return nil
+}
+
+// RevokeFamily は系列の失効していないトークンをすべて失効させます
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	query := "UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL"
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, revokedAt, familyID)
+	return err
+}
+
+// DeleteExpired は有効期限切れのトークンを削除します
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := "DELETE FROM refresh_tokens WHERE expires_at < $1"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"project_template/backend/adapter/repository/transaction"
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// CredentialRepository はSQLiteを使用したユーザーの認証情報のリポジトリ実装です
+type CredentialRepository struct {
+	db *sql.DB
+}
+
+// NewCredentialRepository はCredentialRepositoryを生成します
+func NewCredentialRepository(db *sql.DB) domainRepo.CredentialRepository {
+	return &CredentialRepository{
+		db: db,
+	}
+}
+
+// conn は ctx のトランザクション、またはトランザクション外の場合は接続プールを返します
+func (r *CredentialRepository) conn(ctx context.Context) transaction.Querier {
+	return transaction.Conn(ctx, r.db)
+}
+
+// FindByUserID はユーザーIDによる認証情報の検索を実装します
+func (r *CredentialRepository) FindByUserID(ctx context.Context, userID string) (*entity.Credential, error) {
+	query := "SELECT user_id, password_hash, created_at, updated_at FROM user_credentials WHERE user_id = ?"
+
+	var credential entity.Credential
+	err := r.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&credential.UserID,
+		&credential.PasswordHash,
+		&credential.CreatedAt,
+		&credential.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // 認証情報が見つからない場合
+		}
+		return nil, err
+	}
+	credential.CreatedAt = credential.CreatedAt.UTC()
+	credential.UpdatedAt = credential.UpdatedAt.UTC()
+
+	return &credential, nil
+}
+
+// Save は認証情報の保存を実装します
+// 既に認証情報が存在する場合は、作成日時を除いて置き換えます
+func (r *CredentialRepository) Save(ctx context.Context, credential *entity.Credential) error {
+	query := `INSERT INTO user_credentials (user_id, password_hash, created_at, updated_at)
+			  VALUES (?, ?, ?, ?)
+			  ON CONFLICT (user_id) DO UPDATE SET password_hash = excluded.password_hash, updated_at = excluded.updated_at`
+
+	_, err := r.conn(ctx).ExecContext(
+		ctx,
+		query,
+		credential.UserID,
+		credential.PasswordHash,
+		dbTime(credential.CreatedAt),
+		dbTime(credential.UpdatedAt),
+	)
+	if err != nil {
+		return translateUserRefError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"project_template/backend/adapter/repository/transaction"
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// sessionColumns はセッションの取得で選択するカラムです（scanSession の順序と一致させる）
+const sessionColumns = "id, user_id, token_hash, csrf_token, user_agent, ip_address, created_at, last_seen_at, idle_expires_at, expires_at"
+
+// SessionRepository はSQLiteを使用したサーバー側セッションのリポジトリ実装です
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository はSessionRepositoryを生成します
+func NewSessionRepository(db *sql.DB) domainRepo.SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// conn は ctx のトランザクション、またはトランザクション外の場合は接続プールを返します
+func (r *SessionRepository) conn(ctx context.Context) transaction.Querier {
+	return transaction.Conn(ctx, r.db)
+}
+
+// scanSession は1行をセッションに変換します
+func scanSession(row rowScanner) (*entity.Session, error) {
+	var session entity.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.CSRFToken,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.IdleExpiresAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	session.CreatedAt = session.CreatedAt.UTC()
+	session.LastSeenAt = session.LastSeenAt.UTC()
+	session.IdleExpiresAt = session.IdleExpiresAt.UTC()
+	session.ExpiresAt = session.ExpiresAt.UTC()
+	return &session, nil
+}
+
+// FindByTokenHash はセッショントークンのハッシュ値による検索を実装します
+func (r *SessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Session, error) {
+	query := "SELECT " + sessionColumns + " FROM sessions WHERE token_hash = ?"
+
+	session, err := scanSession(r.conn(ctx).QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // セッションが見つからない場合
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// ListByUserID はユーザーのセッションの一覧を実装します
+func (r *SessionRepository) ListByUserID(ctx context.Context, userID string) ([]*entity.Session, error) {
+	query := "SELECT " + sessionColumns + " FROM sessions WHERE user_id = ? ORDER BY last_seen_at DESC, id"
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*entity.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Create はセッションの保存を実装します
+func (r *SessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	query := `INSERT INTO sessions (id, user_id, token_hash, csrf_token, user_agent, ip_address, created_at, last_seen_at, idle_expires_at, expires_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.conn(ctx).ExecContext(
+		ctx,
+		query,
+		session.ID,
+		session.UserID,
+		session.TokenHash,
+		session.CSRFToken,
+		session.UserAgent,
+		session.IPAddress,
+		dbTime(session.CreatedAt),
+		dbTime(session.LastSeenAt),
+		dbTime(session.IdleExpiresAt),
+		dbTime(session.ExpiresAt),
+	)
+	if err != nil {
+		return translateUserRefError(err)
+	}
+
+	return nil
+}
+
+// Touch はセッションの最終アクセス日時と無操作による有効期限の更新を実装します
+func (r *SessionRepository) Touch(ctx context.Context, id string, lastSeenAt, idleExpiresAt time.Time) error {
+	query := "UPDATE sessions SET last_seen_at = ?, idle_expires_at = ? WHERE id = ?"
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, dbTime(lastSeenAt), dbTime(idleExpiresAt), id)
+	return err
+}
+
+// Delete はユーザーのセッションの削除を実装します
+func (r *SessionRepository) Delete(ctx context.Context, userID, id string) error {
+	query := "DELETE FROM sessions WHERE id = ? AND user_id = ?"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domainRepo.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpired は失効したセッションを削除します
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := "DELETE FROM sessions WHERE idle_expires_at <= ? OR expires_at <= ?"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, dbTime(before), dbTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
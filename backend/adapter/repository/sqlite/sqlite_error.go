@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"errors"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// isUniqueViolation はエラーが指定したカラムの一意制約違反か判定します
+// SQLiteは "UNIQUE constraint failed: テーブル名.カラム名" の形式でカラムを返します
+func isUniqueViolation(err error, table, column string) bool {
+	var liteErr *sqlite.Error
+	if !errors.As(err, &liteErr) || liteErr.Code() != sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return false
+	}
+	return strings.Contains(liteErr.Error(), table+"."+column)
+}
+
+// translateUserError はユーザーテーブルへの書き込みエラーをドメインのエラーに変換します
+func translateUserError(err error) error {
+	if isUniqueViolation(err, "users", "email") {
+		return domainRepo.ErrEmailAlreadyExists.Wrap(err)
+	}
+	return err
+}
+
+// translateUserRefError はユーザーを参照するテーブル（認証情報など）への書き込みエラーをドメインのエラーに変換します
+func translateUserRefError(err error) error {
+	// 参照するユーザーが存在しない（外部キー制約違反）
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) && liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY {
+		return domainRepo.ErrUserNotFound.Wrap(err)
+	}
+	return err
+}
+
+// IsRetryable はトランザクションを再試行することで解消できるエラーか判定します
+// 拡張エラーコードの下位8ビットが基本のエラーコードです
+func IsRetryable(err error) bool {
+	var liteErr *sqlite.Error
+	if !errors.As(err, &liteErr) {
+		return false
+	}
+	code := liteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
@@ -0,0 +1,347 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"project_template/backend/adapter/repository/transaction"
+	"project_template/backend/domain/entity"
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// userSortColumns は並び替え項目とカラム名の対応表です
+// ORDER BY句にはこの表に含まれるカラム名のみを埋め込みます
+var userSortColumns = map[domainRepo.UserSortKey]string{
+	domainRepo.UserSortByName:      "name",
+	domainRepo.UserSortByEmail:     "email",
+	domainRepo.UserSortByCreatedAt: "created_at",
+	domainRepo.UserSortByUpdatedAt: "updated_at",
+}
+
+// likeEscaper はLIKE句のワイルドカードをエスケープします
+// SQLiteにはエスケープ文字の既定値がないため、LIKE句には ESCAPE '\' を指定します
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// UserRepository はSQLiteを使用したユーザーのリポジトリ実装です
+//
+// MySQL実装と同じ振る舞いになるよう、次の点を合わせています
+//   - name, email カラムは大文字小文字を区別しない照合順序（NOCASE）で比較する
+//   - 日時はUTCに変換し、秒単位に丸めて保存する（TIMESTAMP型）
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository はUserRepositoryを生成します
+func NewUserRepository(db *sql.DB) domainRepo.UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+// conn は ctx のトランザクション、またはトランザクション外の場合は接続プールを返します
+func (r *UserRepository) conn(ctx context.Context) transaction.Querier {
+	return transaction.Conn(ctx, r.db)
+}
+
+// dbTime は日時を保存・比較する形式に変換します
+// 文字列として保存されるため、すべての値を同じタイムゾーンと精度に揃えて順序を保ちます
+func dbTime(t time.Time) time.Time {
+	return t.Round(time.Second).UTC()
+}
+
+// rowScanner は *sql.Row と *sql.Rows に共通する読み込みのメソッドです
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser は1行をユーザーに変換します
+func scanUser(row rowScanner) (*entity.User, error) {
+	var user entity.User
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	user.CreatedAt = user.CreatedAt.UTC()
+	user.UpdatedAt = user.UpdatedAt.UTC()
+	if user.DeletedAt != nil {
+		deletedAt := user.DeletedAt.UTC()
+		user.DeletedAt = &deletedAt
+	}
+	return &user, nil
+}
+
+// FindByID はIDによるユーザー検索を実装します
+func (r *UserRepository) FindByID(ctx context.Context, id string, opts ...domainRepo.FindOption) (*entity.User, error) {
+	query := "SELECT id, name, email, role, version, created_at, updated_at, deleted_at FROM users WHERE id = ?" + activeCond(opts)
+
+	user, err := scanUser(r.conn(ctx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // ユーザーが見つからない場合
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// FindByEmail はメールアドレスによるユーザー検索を実装します
+func (r *UserRepository) FindByEmail(ctx context.Context, email string, opts ...domainRepo.FindOption) (*entity.User, error) {
+	// emailカラムは大文字小文字を区別しない照合順序のため、一意インデックスで検索できる
+	query := "SELECT id, name, email, role, version, created_at, updated_at, deleted_at FROM users WHERE email = ?" + activeCond(opts)
+
+	user, err := scanUser(r.conn(ctx).QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // ユーザーが見つからない場合
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// FindAll はすべてのユーザーを取得します
+func (r *UserRepository) FindAll(ctx context.Context, opts ...domainRepo.FindOption) ([]*entity.User, error) {
+	query := "SELECT id, name, email, role, version, created_at, updated_at, deleted_at FROM users"
+	if !domainRepo.HasOption(opts, domainRepo.IncludeDeleted) {
+		query += " WHERE deleted_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	return r.query(ctx, query)
+}
+
+// FindPage は条件に一致するユーザーをキーセットページネーションで取得します
+func (r *UserRepository) FindPage(ctx context.Context, q domainRepo.UserPageQuery) ([]*entity.User, error) {
+	column, ok := userSortColumns[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort key: %q", q.Sort)
+	}
+	direction, cmp := "DESC", "<"
+	if q.Order == domainRepo.SortAsc {
+		direction, cmp = "ASC", ">"
+	}
+
+	var conds []string
+	var args []interface{}
+
+	// 絞り込み条件
+	if !q.Filter.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	if q.Filter.EmailDomain != "" {
+		conds = append(conds, `email LIKE ? ESCAPE '\'`)
+		args = append(args, "%@"+likeEscaper.Replace(q.Filter.EmailDomain))
+	}
+	if q.Filter.NamePrefix != "" {
+		conds = append(conds, `name LIKE ? ESCAPE '\'`)
+		args = append(args, likeEscaper.Replace(q.Filter.NamePrefix)+"%")
+	}
+	if q.Filter.CreatedAfter != nil {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, q.Filter.CreatedAfter.UTC())
+	}
+	if q.Filter.CreatedBefore != nil {
+		conds = append(conds, "created_at < ?")
+		args = append(args, q.Filter.CreatedBefore.UTC())
+	}
+
+	// 前ページの最終行より後ろの行のみを対象とする
+	if q.After != nil {
+		var key interface{} = q.After.StringKey
+		if q.Sort == domainRepo.UserSortByCreatedAt || q.Sort == domainRepo.UserSortByUpdatedAt {
+			key = q.After.TimeKey.UTC()
+		}
+		conds = append(conds, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, cmp))
+		args = append(args, key, key, q.After.ID)
+	}
+
+	query := "SELECT id, name, email, role, version, created_at, updated_at, deleted_at FROM users"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, id %[2]s LIMIT ?", column, direction)
+	args = append(args, q.Limit)
+
+	return r.query(ctx, query, args...)
+}
+
+// query は複数行を取得してユーザーに変換します
+func (r *UserRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.User, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*entity.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Create は新規ユーザーの保存を実装します
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	query := `INSERT INTO users (id, name, email, role, version, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.conn(ctx).ExecContext(
+		ctx,
+		query,
+		user.ID,
+		user.Name,
+		user.Email,
+		user.Role,
+		user.Version,
+		dbTime(user.CreatedAt),
+		dbTime(user.UpdatedAt),
+	)
+
+	if err != nil {
+		return translateUserError(err)
+	}
+
+	return nil
+}
+
+// Update はユーザー情報の更新を実装します
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	query := `UPDATE users
+			  SET name = ?, email = ?, role = ?, version = version + 1, updated_at = ?
+			  WHERE id = ? AND version = ? AND deleted_at IS NULL`
+
+	result, err := r.conn(ctx).ExecContext(
+		ctx,
+		query,
+		user.Name,
+		user.Email,
+		user.Role,
+		dbTime(user.UpdatedAt),
+		user.ID,
+		user.Version,
+	)
+
+	if err != nil {
+		return translateUserError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.staleOrMissing(ctx, user.ID, false)
+	}
+	user.Version++
+
+	return nil
+}
+
+// Delete はユーザーのソフトデリートを実装します
+func (r *UserRepository) Delete(ctx context.Context, id string, version int64) error {
+	query := `UPDATE users
+			  SET deleted_at = ?, updated_at = ?, version = version + 1
+			  WHERE id = ? AND version = ? AND deleted_at IS NULL`
+
+	now := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, dbTime(now), dbTime(now), id, version)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.staleOrMissing(ctx, id, false)
+	}
+
+	return nil
+}
+
+// Restore はソフトデリートされたユーザーの復元を実装します
+func (r *UserRepository) Restore(ctx context.Context, id string, version int64) error {
+	query := `UPDATE users
+			  SET deleted_at = NULL, updated_at = ?, version = version + 1
+			  WHERE id = ? AND version = ? AND deleted_at IS NOT NULL`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, dbTime(time.Now()), id, version)
+	if err != nil {
+		// 削除後に同じメールアドレスのユーザーが作成されている場合
+		return translateUserError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.staleOrMissing(ctx, id, true)
+	}
+
+	return nil
+}
+
+// Purge はソフトデリートから一定期間が経過したユーザーを完全に削除します
+func (r *UserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := "DELETE FROM users WHERE deleted_at < ?"
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, dbTime(deletedBefore))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// staleOrMissing は条件付きの更新で対象の行がなかった理由を返します
+// deleted は更新の対象とした状態（ソフトデリート済みのユーザーか）です
+//   - ユーザーが存在しない場合、削除されていないユーザーを対象として削除済みだった場合は ErrUserNotFound
+//   - 削除済みのユーザーを対象として削除されていなかった場合は ErrUserNotDeleted
+//   - それ以外の場合はバージョンが異なるため ErrUserVersionConflict
+func (r *UserRepository) staleOrMissing(ctx context.Context, id string, deleted bool) error {
+	var isDeleted bool
+	err := r.conn(ctx).QueryRowContext(ctx, "SELECT deleted_at IS NOT NULL FROM users WHERE id = ?", id).Scan(&isDeleted)
+	switch {
+	case err == sql.ErrNoRows:
+		return domainRepo.ErrUserNotFound
+	case err != nil:
+		return err
+	case isDeleted != deleted && deleted:
+		return domainRepo.ErrUserNotDeleted
+	case isDeleted != deleted:
+		return domainRepo.ErrUserNotFound
+	}
+	return domainRepo.ErrUserVersionConflict
+}
+
+// activeCond は opts に IncludeDeleted が含まれない場合に、削除済みのユーザーを除外する条件を返します
+func activeCond(opts []domainRepo.FindOption) string {
+	if domainRepo.HasOption(opts, domainRepo.IncludeDeleted) {
+		return ""
+	}
+	return " AND deleted_at IS NULL"
+}
@@ -0,0 +1,142 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	domainRepo "project_template/backend/domain/repository"
+)
+
+// retryBaseDelay は再試行までの待ち時間の基準値です（再試行のたびに倍にします）
+const retryBaseDelay = 10 * time.Millisecond
+
+// Querier は *sql.DB と *sql.Tx に共通するクエリの実行メソッドです
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// RetryPolicy はエラーがトランザクションの再試行で解消できるか判定します
+type RetryPolicy func(err error) bool
+
+// txKey は context に実行中のトランザクションを格納するキーです
+type txKey struct{}
+
+// txState は実行中のトランザクションと、作成したセーブポイントの数です
+type txState struct {
+	db         *sql.DB
+	tx         *sql.Tx
+	savepoints int
+}
+
+// Conn は ctx に db のトランザクションがあればそれを、なければ db を返します
+// リポジトリはクエリの実行にこの戻り値を使用することで、トランザクションの有無を意識せずに済みます
+func Conn(ctx context.Context, db *sql.DB) Querier {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok && st.db == db {
+		return st.tx
+	}
+	return db
+}
+
+// Manager は database/sql のトランザクションによる domainRepo.TxManager の実装です
+type Manager struct {
+	db          *sql.DB
+	retryable   RetryPolicy
+	maxAttempts int
+}
+
+// NewManager はManagerを生成します
+// retryable が true を返すエラーの場合、トランザクションを最大 maxAttempts 回まで実行します
+func NewManager(db *sql.DB, retryable RetryPolicy, maxAttempts int) domainRepo.TxManager {
+	return &Manager{
+		db:          db,
+		retryable:   retryable,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// WithinTx は fn をトランザクション内で実行します
+func (m *Manager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok && st.db == m.db {
+		return m.withinSavepoint(ctx, st, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := m.run(ctx, fn)
+		if err == nil || attempt >= m.maxAttempts || !m.retryable(err) {
+			return err
+		}
+
+		// 他のトランザクションと競合しないよう、ジッターを加えて待ってから再試行する
+		delay := retryBaseDelay << (attempt - 1)
+		delay = delay/2 + rand.N(delay/2+1)
+		slog.WarnContext(ctx, "Retrying transaction", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// run はトランザクションを開始して fn を1回実行します
+func (m *Manager) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{db: m.db, tx: tx})); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// withinSavepoint は実行中のトランザクションにセーブポイントを作成して fn を実行します
+// fn がエラーを返した場合はセーブポイントまで戻し、外側のトランザクションは継続できるようにします
+func (m *Manager) withinSavepoint(ctx context.Context, st *txState, fn func(ctx context.Context) error) (err error) {
+	st.savepoints++
+	name := fmt.Sprintf("sp_%d", st.savepoints)
+	if _, err := st.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			st.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := st.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back to savepoint: %w", rbErr))
+		}
+		return err
+	}
+
+	if _, err := st.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
@@ -1,46 +1,146 @@
 package router
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	"project_template/backend/adapter/handler"
 	"project_template/backend/adapter/middleware"
 )
 
+// Options はルーターとミドルウェアの設定です
+type Options struct {
+	Logger *slog.Logger
+	// Metrics はHTTPリクエストのメトリクスの記録先です
+	Metrics middleware.HTTPMetrics
+	// Tracer と Propagator はリクエストのトレースに使用します
+	Tracer     trace.Tracer
+	Propagator propagation.TextMapPropagator
+	// RequestTimeout はルートごとの指定がない場合のリクエスト処理の期限です
+	RequestTimeout time.Duration
+	// RouteTimeouts はルート名ごとのリクエスト処理の期限です
+	RouteTimeouts map[string]time.Duration
+	// CORSAllowedOrigins はクロスオリジンのリクエストを許可するオリジンの一覧です
+	CORSAllowedOrigins []string
+	// TokenVerifier はリクエストのアクセストークンの検証に使用します
+	TokenVerifier middleware.TokenVerifier
+	// APIKeyVerifier はサービス間の呼び出しに使用する API キーの検証に使用します
+	APIKeyVerifier middleware.APIKeyVerifier
+	// SessionVerifier と SessionCookie は Cookie のセッションによる認証に使用します
+	SessionVerifier middleware.SessionVerifier
+	SessionCookie   *middleware.SessionCookie
+}
+
 // Router はアプリケーションのルーターを設定します
 type Router struct {
-	userHandler *handler.UserHandler
+	userHandler    *handler.UserHandler
+	authHandler    *handler.AuthHandler
+	sessionHandler *handler.SessionHandler
+	apiKeyHandler  *handler.APIKeyHandler
+	healthHandler  *handler.HealthHandler
+	opts           Options
 }
 
 // NewRouter はRouterを生成します
-func NewRouter(userHandler *handler.UserHandler) *Router {
+func NewRouter(
+	userHandler *handler.UserHandler,
+	authHandler *handler.AuthHandler,
+	sessionHandler *handler.SessionHandler,
+	apiKeyHandler *handler.APIKeyHandler,
+	healthHandler *handler.HealthHandler,
+	opts Options,
+) *Router {
 	return &Router{
-		userHandler: userHandler,
+		userHandler:    userHandler,
+		authHandler:    authHandler,
+		sessionHandler: sessionHandler,
+		apiKeyHandler:  apiKeyHandler,
+		healthHandler:  healthHandler,
+		opts:           opts,
+	}
+}
+
+// middlewares はすべてのルートに適用するミドルウェアを外側から順に返します
+// ミドルウェアの追加・並び替えはここで行います
+func (r *Router) middlewares() []mux.MiddlewareFunc {
+	return []mux.MiddlewareFunc{
+		// リクエストIDの割り当て（以降のログ・レスポンスに使用）
+		middleware.RequestID,
+		// トレースの開始（traceparent の受け取りと返却）
+		middleware.Tracing(r.opts.Tracer, r.opts.Propagator),
+		// リクエストスコープのロガーを設定
+		middleware.RequestLogger(r.opts.Logger),
+		// アクセスログとメトリクス（パニックによる 500 も記録するため Recover より外側）
+		middleware.AccessLog,
+		middleware.Metrics(r.opts.Metrics),
+		// パニックの回復
+		middleware.Recover(handler.WriteError),
+		// CORSヘッダーの設定とプリフライトリクエストの応答
+		middleware.CORS(r.opts.CORSAllowedOrigins),
+		// リクエストボディの文字コードの検証
+		middleware.Charset(handler.WriteError),
+		// アクセストークン・API キーまたはセッションの検証と認証された主体の設定（セッションの場合は CSRF 対策を含む）
+		middleware.Authenticate(r.opts.TokenVerifier, r.opts.APIKeyVerifier, r.opts.SessionVerifier, r.opts.SessionCookie, handler.WriteError),
+		// リクエスト処理の期限
+		middleware.Timeout(r.opts.RequestTimeout, r.opts.RouteTimeouts),
 	}
 }
 
 // Setup はルーターの設定を行います
 func (r *Router) Setup() *mux.Router {
 	router := mux.NewRouter()
-
-	// CORSミドルウェアを適用
-	router.Use(middleware.CORS)
+	router.Use(r.middlewares()...)
 
 	// APIのバージョンプレフィックス
 	api := router.PathPrefix("/api/v1").Subrouter()
+	// protected は認証が必要なハンドラーを返します
+	requireAuth := middleware.RequireAuth(handler.WriteError)
+	protected := func(h http.HandlerFunc) http.Handler {
+		return requireAuth(h)
+	}
 
-	// ユーザー関連のエンドポイント
-	api.HandleFunc("/users", r.userHandler.GetUsers).Methods(http.MethodGet, http.MethodOptions)
-	api.HandleFunc("/users", r.userHandler.CreateUser).Methods(http.MethodPost, http.MethodOptions)
-	api.HandleFunc("/users/{id}", r.userHandler.GetUser).Methods(http.MethodGet, http.MethodOptions)
+	// ユーザー関連のエンドポイント（ロールによる権限の確認はユースケースで行います）
+	api.Handle("/users", protected(r.userHandler.GetUsers)).Methods(http.MethodGet, http.MethodOptions).Name("users.list")
+	api.Handle("/users", protected(r.userHandler.CreateUser)).Methods(http.MethodPost, http.MethodOptions).Name("users.create")
+	api.Handle("/users/{id}", protected(r.userHandler.GetUser)).Methods(http.MethodGet, http.MethodOptions).Name("users.get")
+	api.Handle("/users/{id}", protected(r.userHandler.UpdateUser)).Methods(http.MethodPut, http.MethodPatch, http.MethodOptions).Name("users.update")
+	api.Handle("/users/{id}", protected(r.userHandler.DeleteUser)).Methods(http.MethodDelete, http.MethodOptions).Name("users.delete")
+	api.Handle("/users/{id}/restore", protected(r.userHandler.RestoreUser)).Methods(http.MethodPost, http.MethodOptions).Name("users.restore")
+
+	// 認証関連のエンドポイント
+	api.HandleFunc("/auth/login", r.authHandler.Login).Methods(http.MethodPost, http.MethodOptions).Name("auth.login")
+	api.HandleFunc("/auth/refresh", r.authHandler.Refresh).Methods(http.MethodPost, http.MethodOptions).Name("auth.refresh")
+	api.HandleFunc("/auth/logout", r.authHandler.Logout).Methods(http.MethodPost, http.MethodOptions).Name("auth.logout")
+
+	// Cookie 認証のセッション関連のエンドポイント
+	api.HandleFunc("/auth/sessions", r.sessionHandler.CreateSession).Methods(http.MethodPost, http.MethodOptions).Name("sessions.create")
+	api.Handle("/auth/sessions", protected(r.sessionHandler.ListSessions)).Methods(http.MethodGet, http.MethodOptions).Name("sessions.list")
+	api.Handle("/auth/sessions/current", protected(r.sessionHandler.GetCurrentSession)).Methods(http.MethodGet, http.MethodOptions).Name("sessions.current")
+	api.Handle("/auth/sessions/{id}", protected(r.sessionHandler.RevokeSession)).Methods(http.MethodDelete, http.MethodOptions).Name("sessions.revoke")
+
+	// サービス間の呼び出しに使用する API キーの管理（管理者のみ）
+	api.Handle("/api-keys", protected(r.apiKeyHandler.CreateAPIKey)).Methods(http.MethodPost, http.MethodOptions).Name("api_keys.create")
+	api.Handle("/api-keys", protected(r.apiKeyHandler.ListAPIKeys)).Methods(http.MethodGet, http.MethodOptions).Name("api_keys.list")
+	api.Handle("/api-keys/{id}", protected(r.apiKeyHandler.RevokeAPIKey)).Methods(http.MethodDelete, http.MethodOptions).Name("api_keys.revoke")
+
+	// アクセストークンの検証に使用する公開鍵の一覧
+	router.HandleFunc("/.well-known/jwks.json", r.authHandler.JWKS).Methods(http.MethodGet).Name("jwks")
 
 	// ヘルスチェック
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	}).Methods(http.MethodGet)
+	router.HandleFunc("/healthz", r.healthHandler.Liveness).Methods(http.MethodGet).Name("healthz")
+	router.HandleFunc("/readyz", r.healthHandler.Readiness).Methods(http.MethodGet).Name("readyz")
+
+	// 存在しないルート名の期限は適用されないため、設定の誤りとして警告する
+	for name := range r.opts.RouteTimeouts {
+		if router.Get(name) == nil && r.opts.Logger != nil {
+			r.opts.Logger.Warn("Route timeout configured for unknown route", "route", name)
+		}
+	}
 
 	return router
 }
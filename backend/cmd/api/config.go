@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"project_template/backend/infrastructure/config"
+)
+
+const configUsage = `usage: api [flags] config <command>
+
+commands:
+  print [--redacted]  有効な設定値と読み込み元を表示する（--redacted で機密情報をマスクする）`
+
+// runConfig は config サブコマンドを実行します
+// 設定の検証に失敗した場合も、読み込んだ値を表示してから問題を報告します
+func runConfig(cfg *config.Config, loadErr error, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing config command\n%s", configUsage)
+	}
+
+	switch args[0] {
+	case "print":
+		redacted := false
+		for _, arg := range args[1:] {
+			switch arg {
+			case "--redacted", "-redacted":
+				redacted = true
+			default:
+				return fmt.Errorf("unknown option: %q\n%s", arg, configUsage)
+			}
+		}
+
+		var validationErr *config.ValidationError
+		if loadErr != nil && !errors.As(loadErr, &validationErr) {
+			return loadErr
+		}
+		if err := cfg.Print(os.Stdout, redacted); err != nil {
+			return err
+		}
+		return loadErr
+	}
+	return fmt.Errorf("unknown config command: %q\n%s", args[0], configUsage)
+}
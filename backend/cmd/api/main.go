@@ -1,50 +1,250 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.opentelemetry.io/otel"
 
 	"project_template/backend/adapter/handler"
-	"project_template/backend/adapter/repository"
+	"project_template/backend/adapter/middleware"
 	"project_template/backend/adapter/router"
 	"project_template/backend/domain/services"
-	"project_template/backend/infrastructure/bootstrap"
 	"project_template/backend/infrastructure/config"
+	"project_template/backend/infrastructure/health"
+	"project_template/backend/infrastructure/logger"
+	"project_template/backend/infrastructure/metrics"
+	"project_template/backend/infrastructure/password"
+	"project_template/backend/infrastructure/server"
+	"project_template/backend/infrastructure/token"
+	"project_template/backend/infrastructure/tracing"
+	"project_template/backend/usecase/dto"
 	"project_template/backend/usecase/interactor"
 )
 
 func main() {
-	// 設定の読み込み
-	cfg, err := config.NewConfig()
+	// os.Exit は defer を実行しないため、終了処理は run の中で完結させる
+	if err := run(); err != nil {
+		slog.Error("Application exited with error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run はアプリケーションを起動し、停止シグナルを受け取るまで実行します
+func run() error {
+	// SIGINT/SIGTERM で停止処理を開始する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 設定の読み込み（フラグ以外の引数はサブコマンドとして扱う）
+	cfg, args, err := config.Load(os.Args[1:])
+	if len(args) > 0 && args[0] == "config" {
+		return runConfig(cfg, err, args[1:])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// ロガーの初期化
+	appLogger, err := logger.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	slog.SetDefault(appLogger)
+
+	// トレーシングの初期化
+	tp, shutdownTracing, err := tracing.Setup(tracing.Config{
+		Exporter:    cfg.TraceExporter,
+		FilePath:    cfg.TraceFile,
+		SampleRatio: cfg.TraceSampleRatio,
+		ServiceName: cfg.ServiceName,
+	})
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		return fmt.Errorf("failed to initialize tracing: %w", err)
 	}
+	defer shutdownTracing(context.Background())
+	tracer := tp.Tracer(tracing.InstrumentationName)
 
-	// データベース接続の初期化
-	db := bootstrap.InitDB(cfg)
-	defer db.Close()
+	// マイグレーションのサブコマンド
+	if len(args) > 0 && args[0] == "migrate" {
+		db, m, err := openDatabase(ctx, cfg, tp)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if err := runMigrate(ctx, m, args[1:]); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		return nil
+	}
+
+	// ストレージの初期化（データベースの場合は起動時のマイグレーションを含む）
+	st, err := openStorage(ctx, cfg, tp)
+	if err != nil {
+		return err
+	}
+	// サーバーに停止処理を登録するまでに失敗した場合はここで接続を閉じる
+	closeStorage := true
+	defer func() {
+		if closeStorage {
+			st.Close(context.Background())
+		}
+	}()
+
+	// サーバーの初期化
+	srv := server.New(cfg)
+
+	// メトリクスの初期化（管理用ポートで /metrics を提供する）
+	appMetrics := metrics.New()
+	if st.db != nil {
+		appMetrics.RegisterDB(st.db, st.name)
+	}
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", appMetrics.Handler())
+	srv.AddWorker("admin", server.AdminWorker(fmt.Sprintf(":%s", cfg.AdminPort), adminMux))
 
 	// リポジトリの初期化
-	userRepo := repository.NewUserRepository(db)
+	userRepo := tracing.NewUserRepository(st.userRepo, tracer)
+	credentialRepo := tracing.NewCredentialRepository(st.credentialRepo, tracer)
+	refreshRepo := tracing.NewRefreshTokenRepository(st.refreshRepo, tracer)
+	sessionRepo := tracing.NewSessionRepository(st.sessionRepo, tracer)
+	apiKeyRepo := tracing.NewAPIKeyRepository(st.apiKeyRepo, tracer)
+	txManager := tracing.NewTxManager(st.txManager, tracer)
 
 	// ドメインサービスの初期化
-	userService := services.NewUserService(userRepo)
+	userService := tracing.NewUserService(services.NewUserService(userRepo), tracer)
+	passwordPolicy := services.NewPasswordPolicy(cfg.PasswordMinLength, cfg.PasswordMaxLength)
+
+	// パスワードのハッシュ化とアクセストークンの発行
+	hasher := password.NewArgon2id(password.Params{
+		Memory:      uint32(cfg.Argon2Memory),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+	keys, err := loadSigningKeys(cfg.JWTSigningKeyFiles)
+	if err != nil {
+		return err
+	}
+	tokens := token.NewIssuer(keys, cfg.JWTIssuer, cfg.JWTAudience, cfg.AccessTokenTTL)
 
 	// ユースケースの初期化
-	userInteractor := interactor.NewUserInteractor(userRepo, userService)
+	userInteractor := tracing.NewUserInteractor(
+		interactor.NewUserInteractor(userRepo, credentialRepo, userService, passwordPolicy, hasher, txManager, appMetrics),
+		tracer,
+	)
+	passwordAuth := interactor.NewAuthInteractor(userRepo, credentialRepo, refreshRepo, hasher, tokens, txManager, cfg.RefreshTokenTTL)
+	authInteractor := tracing.NewAuthInteractor(passwordAuth, tracer)
+	sessionInteractor := tracing.NewSessionInteractor(
+		interactor.NewSessionInteractor(userRepo, sessionRepo, passwordAuth, cfg.SessionIdleTimeout, cfg.SessionAbsoluteTimeout),
+		tracer,
+	)
+	apiKeyInteractor := tracing.NewAPIKeyInteractor(interactor.NewAPIKeyInteractor(apiKeyRepo), tracer)
+
+	// 最初の管理者を作成する
+	if cfg.BootstrapAdminEmail != "" {
+		admin, err := userInteractor.EnsureAdmin(ctx, &dto.CreateUserInput{
+			Name:     cfg.BootstrapAdminName,
+			Email:    cfg.BootstrapAdminEmail,
+			Password: cfg.BootstrapAdminPassword,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create bootstrap admin: %w", err)
+		}
+		if admin != nil {
+			// メールアドレスは個人情報のためログに出力しない
+			slog.InfoContext(ctx, "Created bootstrap admin", "user_id", admin.ID)
+		}
+	}
+
+	// 保持期間を過ぎた削除済みユーザーを定期的に完全に削除する
+	srv.AddWorker("user-purge", server.IntervalWorker(cfg.UserPurgeInterval, func(ctx context.Context) error {
+		n, err := userInteractor.PurgeDeletedUsers(ctx, cfg.UserPurgeRetention)
+		if err != nil {
+			return fmt.Errorf("failed to purge deleted users: %w", err)
+		}
+		if n > 0 {
+			slog.InfoContext(ctx, "Purged deleted users", "count", n, "retention", cfg.UserPurgeRetention)
+		}
+		return nil
+	}))
+
+	// 有効期限切れのリフレッシュトークンを定期的に削除する
+	srv.AddWorker("refresh-token-cleanup", server.IntervalWorker(cfg.RefreshTokenCleanupInterval, func(ctx context.Context) error {
+		n, err := authInteractor.PurgeExpiredRefreshTokens(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to purge expired refresh tokens: %w", err)
+		}
+		if n > 0 {
+			slog.InfoContext(ctx, "Purged expired refresh tokens", "count", n)
+		}
+		return nil
+	}))
+
+	// 失効したセッションを定期的に削除する
+	srv.AddWorker("session-cleanup", server.IntervalWorker(cfg.SessionCleanupInterval, func(ctx context.Context) error {
+		n, err := sessionInteractor.PurgeExpiredSessions(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to purge expired sessions: %w", err)
+		}
+		if n > 0 {
+			slog.InfoContext(ctx, "Purged expired sessions", "count", n)
+		}
+		return nil
+	}))
 
 	// ハンドラーの初期化
 	userHandler := handler.NewUserHandler(userInteractor)
+	authHandler := handler.NewAuthHandler(authInteractor, tokens)
+	sessionCookie := &middleware.SessionCookie{
+		Name:     cfg.SessionCookieName,
+		Domain:   cfg.SessionCookieDomain,
+		Secure:   cfg.SessionCookieSecure,
+		SameSite: cfg.SessionSameSite(),
+	}
+	sessionHandler := handler.NewSessionHandler(sessionInteractor, sessionCookie)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyInteractor)
+	healthHandler := handler.NewHealthHandler(
+		append([]handler.HealthChecker{health.NewShutdownChecker(srv.Ready)}, st.checkers...)...,
+	)
 
 	// ルーターの設定
-	r := router.NewRouter(userHandler)
+	r := router.NewRouter(userHandler, authHandler, sessionHandler, apiKeyHandler, healthHandler, router.Options{
+		Logger:             appLogger,
+		Metrics:            appMetrics,
+		Tracer:             tracer,
+		Propagator:         otel.GetTextMapPropagator(),
+		RequestTimeout:     cfg.RequestTimeout,
+		RouteTimeouts:      cfg.RouteTimeoutMap(),
+		CORSAllowedOrigins: cfg.CORSAllowedOrigins,
+		TokenVerifier:      tokens,
+		APIKeyVerifier:     apiKeyInteractor,
+		SessionVerifier:    sessionInteractor,
+		SessionCookie:      sessionCookie,
+	})
 	muxRouter := r.Setup()
 
-	// サーバーの起動
-	addr := fmt.Sprintf(":%s", cfg.ServerPort)
-	log.Printf("Server starting on %s", addr)
-	if err := http.ListenAndServe(addr, muxRouter); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	// サーバーの起動（以降のストレージの接続はサーバーの停止処理で閉じる）
+	srv.OnShutdown(st.Close)
+	closeStorage = false
+	if err := srv.Run(ctx, muxRouter); err != nil {
+		return fmt.Errorf("server stopped with error: %w", err)
+	}
+	return nil
+}
+
+// loadSigningKeys はアクセストークンの署名鍵を読み込みます
+// 鍵ファイルが指定されていない場合は一時的な鍵を生成します（再起動すると発行済みのトークンは無効になります）
+func loadSigningKeys(paths []string) (*token.KeySet, error) {
+	if len(paths) > 0 {
+		return token.LoadKeySet(paths)
 	}
+	slog.Warn("JWT_SIGNING_KEY_FILES is not set; using an ephemeral signing key")
+	return token.GenerateKeySet()
 }
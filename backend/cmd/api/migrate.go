@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"project_template/backend/infrastructure/db/migrator"
+)
+
+const migrateUsage = `usage: api migrate <command>
+
+commands:
+  up             未適用のマイグレーションをすべて適用する
+  down [N]       適用済みのマイグレーションを新しい順に N 件取り消す（デフォルト 1）
+  status         マイグレーションの適用状況を表示する
+  redo           最後に適用したマイグレーションを取り消して再適用する
+  force VERSION  指定したバージョンまでを適用済みとして記録し dirty 状態を解除する`
+
+// runMigrate は migrate サブコマンドを実行します
+func runMigrate(ctx context.Context, m *migrator.Migrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n%s", migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", n)
+		return nil
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			var err error
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid down count: %q", args[1])
+			}
+		}
+		return m.Down(ctx, n)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(statuses)
+		return nil
+	case "redo":
+		return m.Redo(ctx)
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("missing version\n%s", migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version: %q", args[1])
+		}
+		return m.Force(ctx, version)
+	}
+	return fmt.Errorf("unknown migrate command: %q\n%s", args[0], migrateUsage)
+}
+
+// printMigrationStatus はマイグレーションの適用状況を表形式で出力します
+func printMigrationStatus(statuses []migrator.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if s.Dirty {
+			state = "dirty"
+		} else if s.Modified {
+			state = "modified"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+}
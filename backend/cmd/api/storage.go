@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"project_template/backend/adapter/handler"
+	"project_template/backend/adapter/repository"
+	"project_template/backend/adapter/repository/memory"
+	"project_template/backend/adapter/repository/postgres"
+	"project_template/backend/adapter/repository/sqlite"
+	"project_template/backend/adapter/repository/transaction"
+	domainRepo "project_template/backend/domain/repository"
+	"project_template/backend/infrastructure/bootstrap"
+	"project_template/backend/infrastructure/config"
+	"project_template/backend/infrastructure/db/migration"
+	"project_template/backend/infrastructure/db/migrator"
+	"project_template/backend/infrastructure/health"
+)
+
+// errNoDatabase はデータベースを使用しないストレージでデータベースの操作を行った場合のエラーです
+var errNoDatabase = errors.New("the selected storage does not use a database")
+
+// storage はユーザーの永続化先と、その死活監視に使用するチェッカーです
+type storage struct {
+	// db はデータベースを使用しないストレージの場合は nil です
+	db *sql.DB
+	// name はメトリクスに記録するデータベース名です
+	name           string
+	txManager      domainRepo.TxManager
+	userRepo       domainRepo.UserRepository
+	credentialRepo domainRepo.CredentialRepository
+	refreshRepo    domainRepo.RefreshTokenRepository
+	sessionRepo    domainRepo.SessionRepository
+	apiKeyRepo     domainRepo.APIKeyRepository
+	checkers       []handler.HealthChecker
+}
+
+// openStorage は cfg.Storage に応じたストレージを初期化します
+// データベースを使用する場合は、設定に応じて起動時のマイグレーションも行います
+func openStorage(ctx context.Context, cfg *config.Config, tp trace.TracerProvider) (*storage, error) {
+	if cfg.Storage == "memory" {
+		slog.Warn("Using in-memory storage; data will be lost when the server stops")
+		userRepo := memory.NewUserRepository()
+		return &storage{
+			txManager:      memory.NewTxManager(),
+			userRepo:       userRepo,
+			credentialRepo: memory.NewCredentialRepository(userRepo),
+			refreshRepo:    memory.NewRefreshTokenRepository(userRepo),
+			sessionRepo:    memory.NewSessionRepository(userRepo),
+			apiKeyRepo:     memory.NewAPIKeyRepository(),
+		}, nil
+	}
+
+	db, m, err := openDatabase(ctx, cfg, tp)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MigrateOnStart {
+		n, err := m.Up(ctx)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		slog.Info("Applied migrations", "count", n)
+	}
+
+	st := &storage{
+		db:   db,
+		name: cfg.DatabaseName(),
+		checkers: []handler.HealthChecker{
+			health.NewDBChecker(db),
+			health.NewMigrationChecker(m),
+		},
+	}
+	switch cfg.Storage {
+	case "sqlite":
+		st.txManager = transaction.NewManager(db, sqlite.IsRetryable, cfg.DBTxMaxAttempts)
+		st.userRepo = sqlite.NewUserRepository(db)
+		st.credentialRepo = sqlite.NewCredentialRepository(db)
+		st.refreshRepo = sqlite.NewRefreshTokenRepository(db)
+		st.sessionRepo = sqlite.NewSessionRepository(db)
+		st.apiKeyRepo = sqlite.NewAPIKeyRepository(db)
+	case "postgres":
+		st.txManager = transaction.NewManager(db, postgres.IsRetryable, cfg.DBTxMaxAttempts)
+		st.userRepo = postgres.NewUserRepository(db)
+		st.credentialRepo = postgres.NewCredentialRepository(db)
+		st.refreshRepo = postgres.NewRefreshTokenRepository(db)
+		st.sessionRepo = postgres.NewSessionRepository(db)
+		st.apiKeyRepo = postgres.NewAPIKeyRepository(db)
+	default:
+		st.txManager = transaction.NewManager(db, repository.IsRetryable, cfg.DBTxMaxAttempts)
+		st.userRepo = repository.NewUserRepository(db)
+		st.credentialRepo = repository.NewCredentialRepository(db)
+		st.refreshRepo = repository.NewRefreshTokenRepository(db)
+		st.sessionRepo = repository.NewSessionRepository(db)
+		st.apiKeyRepo = repository.NewAPIKeyRepository(db)
+	}
+	return st, nil
+}
+
+// openDatabase は cfg.Storage に応じたデータベースに接続し、そのデータベース向けのMigratorを返します
+func openDatabase(ctx context.Context, cfg *config.Config, tp trace.TracerProvider) (*sql.DB, *migrator.Migrator, error) {
+	var (
+		db      *sql.DB
+		fsys    fs.FS
+		dialect migrator.Dialect
+		err     error
+	)
+	switch cfg.Storage {
+	case "mysql":
+		db, err = bootstrap.InitDB(ctx, cfg, tp)
+		fsys, dialect = migration.MySQL, migrator.MySQL{}
+	case "sqlite":
+		db, err = bootstrap.InitSQLite(ctx, cfg, tp)
+		fsys, dialect = migration.SQLite, migrator.SQLite{}
+	case "postgres":
+		db, err = bootstrap.InitPostgres(ctx, cfg, tp)
+		fsys, dialect = migration.Postgres, migrator.Postgres{}
+	default:
+		return nil, nil, errNoDatabase
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := migrator.New(db, fsys, dialect)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return db, m, nil
+}
+
+// Close はストレージの接続を閉じます
+func (s *storage) Close(ctx context.Context) error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
@@ -0,0 +1,166 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind はエラーの種類を表します
+// アダプター層はこの種類をもとにレスポンスのステータスコードを決定します
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalidArgument
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindUnauthorized
+	KindForbidden
+	KindPreconditionFailed
+	KindPreconditionRequired
+)
+
+// String はエラーの種類を識別子として返します
+func (k Kind) String() string {
+	switch k {
+	case KindInvalidArgument:
+		return "invalid-argument"
+	case KindValidation:
+		return "validation"
+	case KindNotFound:
+		return "not-found"
+	case KindConflict:
+		return "conflict"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindPreconditionFailed:
+		return "precondition-failed"
+	case KindPreconditionRequired:
+		return "precondition-required"
+	}
+	return "internal"
+}
+
+// FieldError は入力項目ごとの検証エラーです
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error はアプリケーション全体で共有する型付きエラーです
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// Error はエラーメッセージを返します
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap は原因となったエラーを返します
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is は同じ種類・メッセージのエラーを同一とみなします
+// これにより原因をラップした後でも errors.Is で判定できます
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Kind == t.Kind && e.Message == t.Message
+}
+
+// Wrap は原因となったエラーをラップした同じ種類のエラーを返します
+func (e *Error) Wrap(cause error) *Error {
+	return &Error{
+		Kind:    e.Kind,
+		Message: e.Message,
+		Fields:  e.Fields,
+		Err:     cause,
+	}
+}
+
+// New は指定した種類のエラーを生成します
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap は原因となったエラーをラップして指定した種類のエラーを生成します
+func Wrap(kind Kind, message string, cause error) *Error {
+	return &Error{Kind: kind, Message: message, Err: cause}
+}
+
+// InvalidArgument は不正なリクエストを表すエラーを生成します
+func InvalidArgument(message string) *Error {
+	return New(KindInvalidArgument, message)
+}
+
+// Validation は入力項目の検証エラーを生成します
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+// NotFound はリソースが存在しないことを表すエラーを生成します
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// Conflict はリソースの状態と競合することを表すエラーを生成します
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+// Unauthorized は認証されていないことを表すエラーを生成します
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+// Forbidden は権限がないことを表すエラーを生成します
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+// PreconditionFailed は更新の前提としたリソースの状態が現在の状態と異なることを表すエラーを生成します
+func PreconditionFailed(message string) *Error {
+	return New(KindPreconditionFailed, message)
+}
+
+// PreconditionRequired は条件付きリクエストが必要であることを表すエラーを生成します
+func PreconditionRequired(message string) *Error {
+	return New(KindPreconditionRequired, message)
+}
+
+// Internal は内部エラーとして原因をラップします
+func Internal(cause error) *Error {
+	return Wrap(KindInternal, "internal error", cause)
+}
+
+// As はエラーチェーンから型付きエラーを取り出します
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// KindOf はエラーの種類を返します
+// 型付きエラーを含まない場合は KindInternal を返します
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+)
+
+var (
+	// ErrUnauthenticated は認証が必要な操作を認証されていない主体が実行しようとした場合のエラーです
+	ErrUnauthenticated = apperror.Unauthorized("authentication is required")
+	// ErrForbidden は主体のロールで許可されていない操作を実行しようとした場合のエラーです
+	ErrForbidden = apperror.Forbidden("insufficient permissions")
+	// ErrCannotChangeOwnRole は自身のロールを変更しようとした場合のエラーです
+	// 管理者が自身の権限を失い、ユーザーを管理できなくなることを防ぎます
+	ErrCannotChangeOwnRole = apperror.Forbidden("cannot change your own role")
+)
+
+// Action は認可の対象とするユーザー管理の操作です
+type Action string
+
+const (
+	ActionReadUser    Action = "users.read"
+	ActionListUsers   Action = "users.list"
+	ActionCreateUser  Action = "users.create"
+	ActionUpdateUser  Action = "users.update"
+	ActionDeleteUser  Action = "users.delete"
+	ActionRestoreUser Action = "users.restore"
+	// ActionChangeRole はユーザーのロールの指定・変更です（作成時の指定を含みます）
+	ActionChangeRole Action = "users.change_role"
+	// ActionReadDeleted は削除済みのユーザーの参照です
+	ActionReadDeleted Action = "users.read_deleted"
+	// ActionManageAPIKeys は API キーの作成・一覧の取得・失効です
+	ActionManageAPIKeys Action = "api_keys.manage"
+)
+
+// Scope は操作を許可する対象の範囲です
+type Scope int
+
+const (
+	// ScopeNone は操作を許可しません
+	ScopeNone Scope = iota
+	// ScopeOwn は主体自身のユーザーに対する操作のみを許可します
+	ScopeOwn
+	// ScopeAll はすべてのユーザーに対する操作を許可します
+	ScopeAll
+)
+
+// policy はロールごとに各操作を許可する範囲を定義する表です
+// 表にないロールと操作の組み合わせは許可しません
+var policy = map[entity.Role]map[Action]Scope{
+	entity.RoleAdmin: {
+		ActionReadUser:    ScopeAll,
+		ActionListUsers:   ScopeAll,
+		ActionCreateUser:  ScopeAll,
+		ActionUpdateUser:  ScopeAll,
+		ActionDeleteUser:  ScopeAll,
+		ActionRestoreUser: ScopeAll,
+		ActionChangeRole:  ScopeAll,
+		ActionReadDeleted: ScopeAll,
+
+		ActionManageAPIKeys: ScopeAll,
+	},
+	entity.RoleMember: {
+		ActionReadUser:   ScopeOwn,
+		ActionUpdateUser: ScopeOwn,
+	},
+	entity.RoleReadOnly: {
+		ActionReadUser:  ScopeAll,
+		ActionListUsers: ScopeAll,
+	},
+}
+
+// apiKeyPolicy は API キーのスコープごとに各操作を許可する範囲を定義する表です
+// ロールの変更、削除済みのユーザーの参照、API キーの管理はどのスコープにも許可しません
+var apiKeyPolicy = map[entity.APIKeyScope]map[Action]Scope{
+	entity.ScopeUsersRead: {
+		ActionReadUser:  ScopeAll,
+		ActionListUsers: ScopeAll,
+	},
+	entity.ScopeUsersWrite: {
+		ActionCreateUser:  ScopeAll,
+		ActionUpdateUser:  ScopeAll,
+		ActionDeleteUser:  ScopeAll,
+		ActionRestoreUser: ScopeAll,
+	},
+}
+
+// ScopeOf はロールに操作を許可する範囲を返します
+func ScopeOf(role entity.Role, action Action) Scope {
+	return policy[role][action]
+}
+
+// ScopeOfAPIKey は API キーのスコープに操作を許可する範囲を返します（複数のスコープのうち最も広い範囲）
+func ScopeOfAPIKey(scopes []entity.APIKeyScope, action Action) Scope {
+	scope := ScopeNone
+	for _, s := range scopes {
+		if sc := apiKeyPolicy[s][action]; sc > scope {
+			scope = sc
+		}
+	}
+	return scope
+}
+
+// Can は主体が操作を実行できるか判定します
+// ownerID は操作の対象のユーザーのIDで、一覧の取得やユーザーの作成のように
+// 特定のユーザーを対象としない操作では空にします（ScopeOwn では許可されません）
+func (p *Principal) Can(action Action, ownerID string) bool {
+	scope := ScopeOf(p.Role, action)
+	if p.APIKeyID != "" {
+		scope = ScopeOfAPIKey(p.Scopes, action)
+	}
+	switch scope {
+	case ScopeAll:
+		return true
+	case ScopeOwn:
+		return ownerID != "" && ownerID == p.UserID
+	default:
+		return false
+	}
+}
+
+// AuthorizeRoleChange は主体が ownerID のユーザーのロールを変更できるか確認します
+// ロールの変更が許可されていない場合は ErrForbidden、自身のロールの場合は ErrCannotChangeOwnRole を返します
+func (p *Principal) AuthorizeRoleChange(ownerID string) error {
+	if !p.Can(ActionChangeRole, ownerID) {
+		return ErrForbidden
+	}
+	if ownerID == p.UserID {
+		return ErrCannotChangeOwnRole
+	}
+	return nil
+}
+
+// Authorize は ctx の主体が操作を実行できるか確認します
+// 認証されていない場合は ErrUnauthenticated、許可されていない場合は ErrForbidden を返します
+func Authorize(ctx context.Context, action Action, ownerID string) (*Principal, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if !p.Can(action, ownerID) {
+		return nil, ErrForbidden
+	}
+	return p, nil
+}
@@ -0,0 +1,191 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+)
+
+const (
+	selfID  = "00000000-0000-0000-0000-000000000001"
+	otherID = "00000000-0000-0000-0000-000000000002"
+)
+
+// allActions は認可の対象とするすべての操作です
+var allActions = []auth.Action{
+	auth.ActionReadUser,
+	auth.ActionListUsers,
+	auth.ActionCreateUser,
+	auth.ActionUpdateUser,
+	auth.ActionDeleteUser,
+	auth.ActionRestoreUser,
+	auth.ActionChangeRole,
+	auth.ActionReadDeleted,
+	auth.ActionManageAPIKeys,
+}
+
+// allowed は操作の対象ごとに許可されるかどうかです
+// self は主体自身、other は他のユーザー、none は特定のユーザーを対象としない操作です
+type allowed struct {
+	self, other, none bool
+}
+
+var (
+	allowAll  = allowed{self: true, other: true, none: true}
+	allowOwn  = allowed{self: true}
+	allowNone = allowed{}
+)
+
+func TestCan(t *testing.T) {
+	tests := map[entity.Role]map[auth.Action]allowed{
+		entity.RoleAdmin: {
+			auth.ActionReadUser:      allowAll,
+			auth.ActionListUsers:     allowAll,
+			auth.ActionCreateUser:    allowAll,
+			auth.ActionUpdateUser:    allowAll,
+			auth.ActionDeleteUser:    allowAll,
+			auth.ActionRestoreUser:   allowAll,
+			auth.ActionChangeRole:    allowAll,
+			auth.ActionReadDeleted:   allowAll,
+			auth.ActionManageAPIKeys: allowAll,
+		},
+		entity.RoleMember: {
+			auth.ActionReadUser:      allowOwn,
+			auth.ActionListUsers:     allowNone,
+			auth.ActionCreateUser:    allowNone,
+			auth.ActionUpdateUser:    allowOwn,
+			auth.ActionDeleteUser:    allowNone,
+			auth.ActionRestoreUser:   allowNone,
+			auth.ActionChangeRole:    allowNone,
+			auth.ActionReadDeleted:   allowNone,
+			auth.ActionManageAPIKeys: allowNone,
+		},
+		entity.RoleReadOnly: {
+			auth.ActionReadUser:      allowAll,
+			auth.ActionListUsers:     allowAll,
+			auth.ActionCreateUser:    allowNone,
+			auth.ActionUpdateUser:    allowNone,
+			auth.ActionDeleteUser:    allowNone,
+			auth.ActionRestoreUser:   allowNone,
+			auth.ActionChangeRole:    allowNone,
+			auth.ActionReadDeleted:   allowNone,
+			auth.ActionManageAPIKeys: allowNone,
+		},
+		// 表にないロールにはどの操作も許可しない
+		entity.Role("unknown"): {},
+	}
+
+	for role, actions := range tests {
+		p := &auth.Principal{UserID: selfID, Role: role}
+		for _, action := range allActions {
+			want, ok := actions[action]
+			if !ok && role.Valid() {
+				t.Errorf("no expectation for %s/%s", role, action)
+				continue
+			}
+			t.Run(string(role)+"/"+string(action), func(t *testing.T) {
+				if got := p.Can(action, selfID); got != want.self {
+					t.Errorf("Can(self) = %v, want %v", got, want.self)
+				}
+				if got := p.Can(action, otherID); got != want.other {
+					t.Errorf("Can(other) = %v, want %v", got, want.other)
+				}
+				if got := p.Can(action, ""); got != want.none {
+					t.Errorf("Can(none) = %v, want %v", got, want.none)
+				}
+			})
+		}
+	}
+}
+
+func TestCanWithAPIKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []entity.APIKeyScope
+		want   map[auth.Action]bool
+	}{
+		{
+			name:   "users:read",
+			scopes: []entity.APIKeyScope{entity.ScopeUsersRead},
+			want:   map[auth.Action]bool{auth.ActionReadUser: true, auth.ActionListUsers: true},
+		},
+		{
+			name:   "users:write",
+			scopes: []entity.APIKeyScope{entity.ScopeUsersWrite},
+			want: map[auth.Action]bool{
+				auth.ActionCreateUser:  true,
+				auth.ActionUpdateUser:  true,
+				auth.ActionDeleteUser:  true,
+				auth.ActionRestoreUser: true,
+			},
+		},
+		{
+			name:   "users:read and users:write",
+			scopes: []entity.APIKeyScope{entity.ScopeUsersRead, entity.ScopeUsersWrite},
+			want: map[auth.Action]bool{
+				auth.ActionReadUser:    true,
+				auth.ActionListUsers:   true,
+				auth.ActionCreateUser:  true,
+				auth.ActionUpdateUser:  true,
+				auth.ActionDeleteUser:  true,
+				auth.ActionRestoreUser: true,
+			},
+		},
+		{name: "no scopes", want: map[auth.Action]bool{}},
+	}
+
+	for _, tt := range tests {
+		// API キーの主体はロールではなくスコープで判定する
+		p := &auth.Principal{APIKeyID: "key-1", Role: entity.RoleAdmin, Scopes: tt.scopes}
+		for _, action := range allActions {
+			t.Run(tt.name+"/"+string(action), func(t *testing.T) {
+				if got := p.Can(action, otherID); got != tt.want[action] {
+					t.Errorf("Can = %v, want %v", got, tt.want[action])
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizeRoleChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    entity.Role
+		ownerID string
+		want    error
+	}{
+		{"admin changes other user", entity.RoleAdmin, otherID, nil},
+		// 管理者が自身を降格させて、ユーザーを管理できなくなることを防ぐ
+		{"admin changes own role", entity.RoleAdmin, selfID, auth.ErrCannotChangeOwnRole},
+		{"member changes own role", entity.RoleMember, selfID, auth.ErrForbidden},
+		{"member changes other user", entity.RoleMember, otherID, auth.ErrForbidden},
+		{"read_only changes own role", entity.RoleReadOnly, selfID, auth.ErrForbidden},
+		{"read_only changes other user", entity.RoleReadOnly, otherID, auth.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &auth.Principal{UserID: selfID, Role: tt.role}
+			if err := p.AuthorizeRoleChange(tt.ownerID); !errors.Is(err, tt.want) {
+				t.Errorf("AuthorizeRoleChange = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ctx := context.Background()
+	if _, err := auth.Authorize(ctx, auth.ActionReadUser, selfID); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("Authorize(unauthenticated) = %v, want ErrUnauthenticated", err)
+	}
+
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{UserID: selfID, Role: entity.RoleMember})
+	if p, err := auth.Authorize(ctx, auth.ActionReadUser, selfID); err != nil || p.UserID != selfID {
+		t.Errorf("Authorize(own) = (%v, %v), want principal", p, err)
+	}
+	if _, err := auth.Authorize(ctx, auth.ActionReadUser, otherID); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("Authorize(other) = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+
+	"project_template/backend/domain/entity"
+)
+
+// Principal は認証されたリクエストの主体です
+type Principal struct {
+	// UserID は認証されたユーザーのIDです
+	UserID string
+	// SessionID は Cookie のセッションで認証された場合のセッションIDです（トークンで認証された場合は空）
+	SessionID string
+	// Role は認証されたユーザーのロールです（API キーで認証された場合は空）
+	Role entity.Role
+	// APIKeyID は API キーで認証された場合のキーのIDです（ユーザーとして認証された場合は空）
+	// API キーの主体には UserID がなく、許可される操作は Role ではなく Scopes で判定します
+	APIKeyID string
+	// Scopes は API キーに許可されたスコープです
+	Scopes []entity.APIKeyScope
+}
+
+// principalKey は context に認証された主体を格納するキーです
+type principalKey struct{}
+
+// WithPrincipal は認証された主体を設定した context を返します
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom は context から認証された主体を取り出します
+// 認証されていないリクエストの場合は false を返します
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
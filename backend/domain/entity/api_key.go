@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"project_template/backend/domain/apperror"
+)
+
+// APIKeyPrefix は API キーの先頭に付ける識別子です
+// Authorization ヘッダーの Bearer トークンがこの接頭辞で始まる場合は、アクセストークンではなく API キーとして扱います
+const APIKeyPrefix = "ak_"
+
+// MaxAPIKeyNameLength は API キーの名前の最大文字数です（VARCHAR(255)に対応）
+const MaxAPIKeyNameLength = 255
+
+// ErrInvalidAPIKey は API キーの不変条件を満たさない場合のエラーです
+var ErrInvalidAPIKey = apperror.Validation("invalid API key")
+
+// APIKeyScope は API キーに許可する操作の範囲です
+// 各スコープで許可される操作は auth パッケージのポリシーで定義します
+type APIKeyScope string
+
+const (
+	// ScopeUsersRead はユーザーの参照を許可します
+	ScopeUsersRead APIKeyScope = "users:read"
+	// ScopeUsersWrite はユーザーの作成・更新・削除・復元を許可します
+	ScopeUsersWrite APIKeyScope = "users:write"
+)
+
+// Valid は定義されたスコープか判定します
+func (s APIKeyScope) Valid() bool {
+	switch s {
+	case ScopeUsersRead, ScopeUsersWrite:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIKey はバッチ処理や他のサービスからの呼び出しに使用する API キーです
+//
+// キーは "ak_<Prefix>_<秘密の値>" の形式で、作成時に一度だけ呼び出し元に返します
+// 保存するのは識別用の Prefix とキー全体のハッシュ値のみです
+type APIKey struct {
+	ID   string
+	Name string
+	// Prefix はキーを識別するための公開してもよい値です（一覧での表示とキーの検索に使用します）
+	Prefix string
+	// SecretHash はキー全体のハッシュ値です（キーそのものは保持しません）
+	SecretHash string
+	Scopes     []APIKeyScope
+	// CreatedBy はキーを作成した管理者のユーザーIDです（記録のみで、ユーザーの削除後も残ります）
+	CreatedBy string
+	CreatedAt time.Time
+	// ExpiresAt は有効期限です（期限がない場合は nil）
+	ExpiresAt *time.Time
+	// LastUsedAt は最後に認証に使用された日時です（未使用の場合は nil）
+	LastUsedAt *time.Time
+	// RevokedAt は失効させた日時です（有効な場合は nil）
+	RevokedAt *time.Time
+}
+
+// NewAPIKey は API キーを生成します
+// 名前・スコープ・有効期限が不変条件を満たさない場合はエラーを返します
+func NewAPIKey(id, name, prefix, secretHash string, scopes []APIKeyScope, createdBy string, expiresAt *time.Time) (*APIKey, error) {
+	now := time.Now()
+	var fields []apperror.FieldError
+	switch {
+	case strings.TrimSpace(name) == "":
+		fields = append(fields, apperror.FieldError{Field: "name", Rule: "required", Message: "must not be empty"})
+	case utf8.RuneCountInString(name) > MaxAPIKeyNameLength:
+		fields = append(fields, apperror.FieldError{Field: "name", Rule: "max", Message: "must be at most 255"})
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		fields = append(fields, apperror.FieldError{Field: "name", Rule: "printable", Message: "must not contain control characters"})
+	}
+	if f, ok := validateScopes(scopes); !ok {
+		fields = append(fields, f)
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		fields = append(fields, apperror.FieldError{Field: "expires_at", Rule: "future", Message: "must be in the future"})
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Validation(ErrInvalidAPIKey.Message, fields...)
+	}
+
+	return &APIKey{
+		ID:         id,
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     dedupScopes(scopes),
+		CreatedBy:  createdBy,
+		CreatedAt:  now,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// IsExpired はキーの有効期限が切れているか判定します
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// IsRevoked はキーが失効しているか判定します
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// validateScopes はスコープが1つ以上あり、すべて定義されたスコープか検証します
+func validateScopes(scopes []APIKeyScope) (apperror.FieldError, bool) {
+	if len(scopes) == 0 {
+		return apperror.FieldError{Field: "scopes", Rule: "required", Message: "must not be empty"}, false
+	}
+	for _, s := range scopes {
+		if !s.Valid() {
+			return apperror.FieldError{Field: "scopes", Rule: "oneof", Message: "must be one of users:read users:write"}, false
+		}
+	}
+	return apperror.FieldError{}, true
+}
+
+// dedupScopes は重複したスコープを取り除きます（順序は保ちます）
+func dedupScopes(scopes []APIKeyScope) []APIKeyScope {
+	out := make([]APIKeyScope, 0, len(scopes))
+	seen := make(map[APIKeyScope]bool, len(scopes))
+	for _, s := range scopes {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
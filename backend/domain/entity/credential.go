@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Credential はユーザーのパスワード認証情報です
+// パスワードそのものは保持せず、ハッシュ化した値のみを保持します
+type Credential struct {
+	UserID string
+	// PasswordHash はハッシュ化したパスワードです（アルゴリズムとパラメータを含む PHC 文字列形式）
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NewCredential はユーザーの認証情報を生成します
+func NewCredential(userID, passwordHash string) *Credential {
+	now := time.Now()
+	return &Credential{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// ChangePasswordHash はハッシュ化したパスワードを置き換えます
+// パスワードの変更や、ハッシュのパラメータ変更に伴う再ハッシュで使用します
+func (c *Credential) ChangePasswordHash(passwordHash string) {
+	c.PasswordHash = passwordHash
+	c.UpdatedAt = time.Now()
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// RefreshToken はアクセストークンの再発行に使用するリフレッシュトークンです
+//
+// リフレッシュトークンは使用するたびに新しいトークンに置き換え（ローテーション）、
+// ログインごとに発行した系列（FamilyID）で引き継ぎます
+// 使用済みのトークンが再び使用された場合は漏洩とみなし、系列のすべてのトークンを失効させます
+type RefreshToken struct {
+	ID       string
+	FamilyID string
+	UserID   string
+	// TokenHash はトークンのハッシュ値です（トークンそのものは保持しません）
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	// UsedAt はローテーションで使用された日時です（未使用の場合は nil）
+	UsedAt *time.Time
+	// RevokedAt はログアウトや再利用の検知で失効した日時です（有効な場合は nil）
+	RevokedAt *time.Time
+}
+
+// NewRefreshToken はリフレッシュトークンを生成します
+func NewRefreshToken(id, familyID, userID, tokenHash string, ttl time.Duration) *RefreshToken {
+	now := time.Now()
+	return &RefreshToken{
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// IsExpired はトークンの有効期限が切れているか判定します
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsUsed はトークンがローテーションで使用済みか判定します
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsRevoked はトークンが失効しているか判定します
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+// Session は Cookie 認証のサーバー側セッションです
+//
+// セッションは最終アクセスから一定時間操作がない場合（IdleExpiresAt）と、
+// ログインから一定時間が経過した場合（ExpiresAt）のいずれか早い方で失効します
+type Session struct {
+	ID     string
+	UserID string
+	// TokenHash は Cookie に保存するセッショントークンのハッシュ値です（トークンそのものは保持しません）
+	TokenHash string
+	// CSRFToken は状態を変更するリクエストで送信させる CSRF 対策のトークンです
+	CSRFToken string
+	// UserAgent と IPAddress はセッション一覧で端末を識別するためのログイン時の情報です
+	UserAgent     string
+	IPAddress     string
+	CreatedAt     time.Time
+	LastSeenAt    time.Time
+	IdleExpiresAt time.Time
+	ExpiresAt     time.Time
+}
+
+// NewSession はセッションを生成します
+func NewSession(id, userID, tokenHash, csrfToken, userAgent, ipAddress string, idleTimeout, absoluteTimeout time.Duration) *Session {
+	now := time.Now()
+	s := &Session{
+		ID:         id,
+		UserID:     userID,
+		TokenHash:  tokenHash,
+		CSRFToken:  csrfToken,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(absoluteTimeout),
+	}
+	s.IdleExpiresAt = s.idleExpiry(now, idleTimeout)
+	return s
+}
+
+// IsExpired はセッションが失効しているか判定します
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.IdleExpiresAt) || !now.Before(s.ExpiresAt)
+}
+
+// Touch はセッションへのアクセスを記録し、無操作による有効期限を延長します
+// 延長した有効期限はログインからの有効期限を超えません
+func (s *Session) Touch(now time.Time, idleTimeout time.Duration) {
+	s.LastSeenAt = now
+	s.IdleExpiresAt = s.idleExpiry(now, idleTimeout)
+}
+
+// idleExpiry は now から無操作で失効する日時を返します
+func (s *Session) idleExpiry(now time.Time, idleTimeout time.Duration) time.Time {
+	expiry := now.Add(idleTimeout)
+	if expiry.After(s.ExpiresAt) {
+		return s.ExpiresAt
+	}
+	return expiry
+}
@@ -1,38 +1,143 @@
 package entity
 
 import (
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"project_template/backend/domain/apperror"
+)
+
+const (
+	// MaxUserNameLength はユーザー名の最大文字数です（VARCHAR(255)に対応）
+	MaxUserNameLength = 255
+	// MaxUserEmailLength はメールアドレスの最大文字数です（VARCHAR(255)に対応）
+	MaxUserEmailLength = 255
+)
+
+// ErrInvalidUser はユーザーの不変条件を満たさない場合のエラーです
+var ErrInvalidUser = apperror.Validation("invalid user")
+
+// Role はユーザーに与えられた権限の種類です
+// 各ロールで許可される操作は auth パッケージのポリシーで定義します
+type Role string
+
+const (
+	// RoleAdmin はすべてのユーザーを管理できる管理者です
+	RoleAdmin Role = "admin"
+	// RoleMember は自身の情報のみを参照・更新できる一般ユーザーです
+	RoleMember Role = "member"
+	// RoleReadOnly はユーザーの情報を参照のみできるユーザーです
+	RoleReadOnly Role = "read_only"
 )
 
+// Valid は定義されたロールか判定します
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleMember, RoleReadOnly:
+		return true
+	default:
+		return false
+	}
+}
+
 // User はユーザーを表すエンティティです
 type User struct {
-	ID        string
-	Name      string
-	Email     string
+	ID    string
+	Name  string
+	Email string
+	// Role はユーザーの権限の種類です
+	Role Role
+	// Version は保存されるたびに増える版番号です
+	// 同じユーザーへの同時の更新で、他の更新を上書きしないために使用します
+	Version   int64
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// DeletedAt はソフトデリートされた日時です（削除されていない場合は nil）
+	DeletedAt *time.Time
 }
 
 // NewUser はユーザーエンティティを生成します
-func NewUser(id, name, email string) *User {
+// ロールは一般ユーザー（RoleMember）とし、変更する場合は ChangeRole を使用します
+// 名前またはメールアドレスが不変条件を満たさない場合はエラーを返します
+func NewUser(id, name, email string) (*User, error) {
+	fields := append(validateName(name), validateEmail(email)...)
+	if len(fields) > 0 {
+		return nil, apperror.Validation(ErrInvalidUser.Message, fields...)
+	}
+
 	now := time.Now()
 	return &User{
 		ID:        id,
 		Name:      name,
 		Email:     email,
+		Role:      RoleMember,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
-	}
+	}, nil
+}
+
+// IsDeleted はユーザーがソフトデリートされているか判定します
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
 }
 
 // ChangeName はユーザー名を変更します
-func (u *User) ChangeName(name string) {
+func (u *User) ChangeName(name string) error {
+	if fields := validateName(name); len(fields) > 0 {
+		return apperror.Validation(ErrInvalidUser.Message, fields...)
+	}
 	u.Name = name
 	u.UpdatedAt = time.Now()
+	return nil
 }
 
 // ChangeEmail はメールアドレスを変更します
-func (u *User) ChangeEmail(email string) {
+func (u *User) ChangeEmail(email string) error {
+	if fields := validateEmail(email); len(fields) > 0 {
+		return apperror.Validation(ErrInvalidUser.Message, fields...)
+	}
 	u.Email = email
 	u.UpdatedAt = time.Now()
-} 
+	return nil
+}
+
+// ChangeRole はユーザーのロールを変更します
+func (u *User) ChangeRole(role Role) error {
+	if !role.Valid() {
+		return apperror.Validation(ErrInvalidUser.Message, apperror.FieldError{Field: "role", Rule: "oneof", Message: "must be one of admin member read_only"})
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// validateName はユーザー名の不変条件を検証します
+func validateName(name string) []apperror.FieldError {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return []apperror.FieldError{{Field: "name", Rule: "required", Message: "must not be empty"}}
+	case utf8.RuneCountInString(name) > MaxUserNameLength:
+		return []apperror.FieldError{{Field: "name", Rule: "max", Message: "must be at most 255"}}
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return []apperror.FieldError{{Field: "name", Rule: "printable", Message: "must not contain control characters"}}
+	}
+	return nil
+}
+
+// validateEmail はメールアドレスの不変条件を検証します
+// 詳細な形式の検証は入力データの検証で行い、ここでは最低限の構造のみを確認します
+func validateEmail(email string) []apperror.FieldError {
+	local, domain, found := strings.Cut(email, "@")
+	switch {
+	case email == "":
+		return []apperror.FieldError{{Field: "email", Rule: "required", Message: "must not be empty"}}
+	case utf8.RuneCountInString(email) > MaxUserEmailLength:
+		return []apperror.FieldError{{Field: "email", Rule: "max", Message: "must be at most 255"}}
+	case !found || local == "" || domain == "" || strings.ContainsAny(email, " \t\r\n"):
+		return []apperror.FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+)
+
+// ErrAPIKeyNotFound は API キーが見つからない場合のエラーです
+var ErrAPIKeyNotFound = apperror.NotFound("API key not found")
+
+// APIKeyRepository は API キーの永続化を担当するインターフェースです
+type APIKeyRepository interface {
+	// FindByPrefix は識別用の接頭辞による検索を行います（存在しない場合は nil）
+	FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	// List は失効したものを含むすべてのキーを作成日時の新しい順に返します
+	List(ctx context.Context) ([]*entity.APIKey, error)
+	// Create はキーを保存します
+	Create(ctx context.Context, key *entity.APIKey) error
+	// Touch はキーの最終使用日時を更新します
+	Touch(ctx context.Context, id string, lastUsedAt time.Time) error
+	// Revoke はキーを失効させます
+	// キーが存在しない場合は ErrAPIKeyNotFound を返し、既に失効している場合は何もしません
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"project_template/backend/domain/entity"
+)
+
+// CredentialRepository はユーザーの認証情報の永続化を担当するインターフェースです
+// ユーザーを完全に削除した場合、そのユーザーの認証情報も削除されます
+type CredentialRepository interface {
+	// FindByUserID はユーザーの認証情報を取得します（存在しない場合は nil）
+	FindByUserID(ctx context.Context, userID string) (*entity.Credential, error)
+	// Save は認証情報を保存します（既に存在する場合は置き換えます）
+	Save(ctx context.Context, credential *entity.Credential) error
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+)
+
+// ErrRefreshTokenNotActive は使用済み・失効済みのリフレッシュトークンを使用済みにしようとした場合のエラーです
+var ErrRefreshTokenNotActive = apperror.Unauthorized("refresh token is not active")
+
+// RefreshTokenRepository はリフレッシュトークンの永続化を担当するインターフェースです
+// ユーザーを完全に削除した場合、そのユーザーのリフレッシュトークンも削除されます
+type RefreshTokenRepository interface {
+	// FindByHash はトークンのハッシュ値による検索を行います（存在しない場合は nil）
+	FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	// Create はトークンを保存します（ユーザーが存在しない場合は ErrUserNotFound）
+	Create(ctx context.Context, token *entity.RefreshToken) error
+	// MarkUsed はトークンを使用済みにします
+	// 既に使用済み、または失効している場合は ErrRefreshTokenNotActive を返します
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) error
+	// RevokeFamily は系列の失効していないトークンをすべて失効させます
+	RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	// DeleteExpired は有効期限が before より前のトークンを削除し、削除した件数を返します
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+)
+
+// ErrSessionNotFound はセッションが見つからない場合のエラーです
+var ErrSessionNotFound = apperror.NotFound("session not found")
+
+// SessionRepository はサーバー側セッションの永続化を担当するインターフェースです
+// ユーザーを完全に削除した場合、そのユーザーのセッションも削除されます
+type SessionRepository interface {
+	// FindByTokenHash はセッショントークンのハッシュ値による検索を行います（存在しない場合は nil）
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.Session, error)
+	// ListByUserID はユーザーのセッションを最終アクセスの新しい順に返します
+	ListByUserID(ctx context.Context, userID string) ([]*entity.Session, error)
+	// Create はセッションを保存します（ユーザーが存在しない場合は ErrUserNotFound）
+	Create(ctx context.Context, session *entity.Session) error
+	// Touch はセッションの最終アクセス日時と無操作による有効期限を更新します
+	Touch(ctx context.Context, id string, lastSeenAt, idleExpiresAt time.Time) error
+	// Delete はユーザーのセッションを削除します
+	// ユーザーのセッションが存在しない場合は ErrSessionNotFound を返します
+	Delete(ctx context.Context, userID, id string) error
+	// DeleteExpired は before の時点で失効しているセッションを削除し、削除した件数を返します
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
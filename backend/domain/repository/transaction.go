@@ -0,0 +1,14 @@
+package repository
+
+import "context"
+
+// TxManager は複数のリポジトリ操作を1つのトランザクションとして実行するインターフェースです
+//
+// トランザクションは context.Context を通してリポジトリに引き継がれるため、
+// fn の中では受け取った ctx を使用してリポジトリを呼び出します
+type TxManager interface {
+	// WithinTx は fn をトランザクション内で実行し、fn がエラーを返した場合はロールバックします
+	// すでにトランザクション内の場合はセーブポイントを作成し、fn の変更のみを取り消せるようにします
+	// デッドロックなど再試行で解消できるエラーの場合は、トランザクション全体を最初からやり直します
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"project_template/backend/domain/entity"
+)
+
+// UserSortKey はユーザー一覧の並び替えに使用できる項目です
+type UserSortKey string
+
+const (
+	UserSortByName      UserSortKey = "name"
+	UserSortByEmail     UserSortKey = "email"
+	UserSortByCreatedAt UserSortKey = "created_at"
+	UserSortByUpdatedAt UserSortKey = "updated_at"
+)
+
+// Valid は並び替え項目が許可されたものか判定します
+func (k UserSortKey) Valid() bool {
+	switch k {
+	case UserSortByName, UserSortByEmail, UserSortByCreatedAt, UserSortByUpdatedAt:
+		return true
+	}
+	return false
+}
+
+// SortOrder は並び順です
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// Valid は並び順が許可されたものか判定します
+func (o SortOrder) Valid() bool {
+	return o == SortAsc || o == SortDesc
+}
+
+// FindOption はユーザーの検索方法を変更するオプションです
+type FindOption int
+
+const (
+	// IncludeDeleted はソフトデリートされたユーザーも検索対象に含めます
+	IncludeDeleted FindOption = iota + 1
+)
+
+// HasOption は opts に opt が含まれるか判定します
+func HasOption(opts []FindOption, opt FindOption) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// UserFilter はユーザー一覧の絞り込み条件です
+// ゼロ値の項目は条件として使用しません
+type UserFilter struct {
+	EmailDomain   string
+	NamePrefix    string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// IncludeDeleted が true の場合はソフトデリートされたユーザーも含めます
+	IncludeDeleted bool
+}
+
+// UserCursor はキーセットページネーションの起点となる位置です
+// 並び替え項目に応じて StringKey または TimeKey のどちらかを使用します
+type UserCursor struct {
+	ID        string
+	StringKey string
+	TimeKey   time.Time
+}
+
+// UserPageQuery はユーザー一覧をページ単位で取得するための条件です
+type UserPageQuery struct {
+	Limit  int
+	Sort   UserSortKey
+	Order  SortOrder
+	Filter UserFilter
+	After  *UserCursor
+}
+
+// NewUserCursor はユーザーと並び替え項目から次ページの起点を生成します
+func NewUserCursor(user *entity.User, key UserSortKey) *UserCursor {
+	c := &UserCursor{ID: user.ID}
+	switch key {
+	case UserSortByName:
+		c.StringKey = user.Name
+	case UserSortByEmail:
+		c.StringKey = user.Email
+	case UserSortByCreatedAt:
+		c.TimeKey = user.CreatedAt
+	case UserSortByUpdatedAt:
+		c.TimeKey = user.UpdatedAt
+	}
+	return c
+}
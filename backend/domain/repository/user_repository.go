@@ -2,16 +2,46 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"project_template/backend/domain/apperror"
 	"project_template/backend/domain/entity"
 )
 
+var (
+	// ErrUserNotFound は操作対象のユーザーが存在しない場合に返されます
+	ErrUserNotFound = apperror.NotFound("user not found")
+	// ErrEmailAlreadyExists はメールアドレスが他のユーザーと重複する場合に
+	// Create, Update から返されます
+	ErrEmailAlreadyExists = apperror.Conflict("email already exists")
+	// ErrUserVersionConflict は更新・削除の対象としたバージョンが現在のバージョンと異なる場合に
+	// Update, Delete から返されます（他の操作によってすでに変更されています）
+	ErrUserVersionConflict = apperror.PreconditionFailed("user has been modified")
+	// ErrUserNotDeleted は削除されていないユーザーを Restore しようとした場合に返されます
+	ErrUserNotDeleted = apperror.Conflict("user is not deleted")
+)
+
 // UserRepository はユーザーのリポジトリインターフェースです
+//
+// 削除はソフトデリートで、削除日時を記録するのみです
+// 検索はソフトデリートされたユーザーを除外し、IncludeDeleted を指定した場合のみ含めます
+// メールアドレスは削除されていないユーザーの間で一意であり、削除済みユーザーのメールアドレスは再利用できます
+//
+// Update は user.Version が保存済みのバージョンと一致する場合のみ更新し、成功した場合は
+// バージョンを1つ進めて user.Version に反映します
+// Delete, Restore も同様に version が一致する場合のみ変更し、バージョンを1つ進めます
 type UserRepository interface {
-	FindByID(ctx context.Context, id string) (*entity.User, error)
-	FindByEmail(ctx context.Context, email string) (*entity.User, error)
-	FindAll(ctx context.Context) ([]*entity.User, error)
+	FindByID(ctx context.Context, id string, opts ...FindOption) (*entity.User, error)
+	FindByEmail(ctx context.Context, email string, opts ...FindOption) (*entity.User, error)
+	FindAll(ctx context.Context, opts ...FindOption) ([]*entity.User, error)
+	FindPage(ctx context.Context, query UserPageQuery) ([]*entity.User, error)
 	Create(ctx context.Context, user *entity.User) error
 	Update(ctx context.Context, user *entity.User) error
-	Delete(ctx context.Context, id string) error
+	// Delete は削除されていないユーザーをソフトデリートします
+	Delete(ctx context.Context, id string, version int64) error
+	// Restore はソフトデリートされたユーザーを元に戻します
+	// 削除後に同じメールアドレスのユーザーが作成されている場合は ErrEmailAlreadyExists を返します
+	Restore(ctx context.Context, id string, version int64) error
+	// Purge は deletedBefore より前にソフトデリートされたユーザーを完全に削除し、削除した件数を返します
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 } 
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"project_template/backend/domain/apperror"
+)
+
+// ErrInvalidPassword はパスワードがポリシーを満たさない場合のエラーです
+var ErrInvalidPassword = apperror.Validation("invalid password")
+
+// PasswordPolicy はパスワードとして許可する条件です
+type PasswordPolicy struct {
+	// MinLength と MaxLength はパスワードの文字数の下限と上限です
+	MinLength int
+	MaxLength int
+}
+
+// NewPasswordPolicy はPasswordPolicyを生成します
+func NewPasswordPolicy(minLength, maxLength int) *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength: minLength,
+		MaxLength: maxLength,
+	}
+}
+
+// Validate はパスワードがポリシーを満たすか検証します
+// email はパスワードの持ち主のメールアドレスで、推測しやすいパスワードの判定に使用します
+func (p *PasswordPolicy) Validate(password, email string) error {
+	if field, ok := p.violation(password, email); ok {
+		return apperror.Validation(ErrInvalidPassword.Message, field)
+	}
+	return nil
+}
+
+// violation はパスワードが最初に違反した条件を返します
+func (p *PasswordPolicy) violation(password, email string) (apperror.FieldError, bool) {
+	n := utf8.RuneCountInString(password)
+	first, _ := utf8.DecodeRuneInString(password)
+	local, _, _ := strings.Cut(email, "@")
+	switch {
+	case !utf8.ValidString(password):
+		return apperror.FieldError{Field: "password", Rule: "utf8", Message: "must be valid UTF-8"}, true
+	case n < p.MinLength:
+		return apperror.FieldError{Field: "password", Rule: "min", Message: "must be at least " + strconv.Itoa(p.MinLength)}, true
+	case n > p.MaxLength:
+		return apperror.FieldError{Field: "password", Rule: "max", Message: "must be at most " + strconv.Itoa(p.MaxLength)}, true
+	case strings.IndexFunc(password, unicode.IsControl) >= 0:
+		return apperror.FieldError{Field: "password", Rule: "printable", Message: "must not contain control characters"}, true
+	case strings.Trim(password, string(first)) == "":
+		return apperror.FieldError{Field: "password", Rule: "repeated", Message: "must not be a single repeated character"}, true
+	case email != "" && (strings.EqualFold(password, email) || strings.EqualFold(password, local)):
+		return apperror.FieldError{Field: "password", Rule: "email", Message: "must not be the email address"}, true
+	}
+	return apperror.FieldError{}, false
+}
@@ -2,18 +2,17 @@ package services
 
 import (
 	"context"
-	"errors"
 
 	"project_template/backend/domain/repository"
 )
 
 var (
-	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrEmailAlreadyExists = repository.ErrEmailAlreadyExists
 )
 
 // UserServiceInterface はユーザーサービスのインターフェースを定義します
 type UserServiceInterface interface {
-	ValidateUniqueEmail(ctx context.Context, email string) bool
+	ValidateUniqueEmail(ctx context.Context, email string) (bool, error)
 }
 
 // UserService はユーザーに関するドメインサービスです
@@ -29,15 +28,13 @@ func NewUserService(userRepo repository.UserRepository) UserServiceInterface {
 }
 
 // ValidateUniqueEmail はメールアドレスが一意であるか確認します
-// このメソッドは実際の実装では、emailによるユーザー検索を行いますが、
-// サンプルとしては単純に常にtrueを返します
-func (s *UserService) ValidateUniqueEmail(ctx context.Context, email string) bool {
+// 検索に失敗した場合は一意性を判定せずにエラーを返します（呼び出し元でリトライの対象にできるようにするため）
+func (s *UserService) ValidateUniqueEmail(ctx context.Context, email string) (bool, error) {
 	// リポジトリからメールアドレスでユーザーを検索
 	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
-		// エラーが発生した場合は、安全側に倒して一意でないとみなす
-		return false
+		return false, err
 	}
 	// userがnilの場合、そのメールアドレスのユーザーは存在しないため一意
-	return user == nil
-} 
+	return user == nil, nil
+}
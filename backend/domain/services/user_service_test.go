@@ -0,0 +1,47 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/domain/services"
+)
+
+// stubUserRepository は FindByEmail の結果のみを差し替えた UserRepository です
+type stubUserRepository struct {
+	repository.UserRepository
+	user *entity.User
+	err  error
+}
+
+func (r *stubUserRepository) FindByEmail(ctx context.Context, email string, opts ...repository.FindOption) (*entity.User, error) {
+	return r.user, r.err
+}
+
+func TestValidateUniqueEmail(t *testing.T) {
+	errDB := errors.New("connection reset")
+	existing := &entity.User{ID: "00000000-0000-0000-0000-000000000001", Email: "alice@example.com"}
+
+	tests := []struct {
+		name       string
+		repo       *stubUserRepository
+		wantUnique bool
+		wantErr    error
+	}{
+		{name: "unused", repo: &stubUserRepository{}, wantUnique: true},
+		{name: "used", repo: &stubUserRepository{user: existing}, wantUnique: false},
+		// 検索の失敗は一意でないとみなさず、そのまま返す
+		{name: "repository error", repo: &stubUserRepository{err: errDB}, wantErr: errDB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unique, err := services.NewUserService(tt.repo).ValidateUniqueEmail(context.Background(), "alice@example.com")
+			if unique != tt.wantUnique || !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateUniqueEmail = (%v, %v), want (%v, %v)", unique, err, tt.wantUnique, tt.wantErr)
+			}
+		})
+	}
+}
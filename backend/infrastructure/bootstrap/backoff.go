@@ -0,0 +1,33 @@
+package bootstrap
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff は再試行の待ち時間を指数関数的に増やします
+// 複数のインスタンスが同時に再試行しないよう、待ち時間にはジッターを加えます
+type Backoff struct {
+	// Initial は最初の再試行までの待ち時間です
+	Initial time.Duration
+	// Max は待ち時間の上限です
+	Max time.Duration
+}
+
+// Delay は attempt 回目（0始まり）の失敗の後に待つ時間を返します
+// Initial * 2^attempt（上限 Max）の半分を固定とし、残りの半分をランダムにします
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
@@ -1,57 +1,124 @@
 package bootstrap
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/trace"
 
 	"project_template/backend/infrastructure/config"
-)
-
-const (
-	maxRetries    = 5
-	retryInterval = 3 * time.Second
+	"project_template/backend/infrastructure/tracing"
 )
 
 // InitDB はデータベース接続を初期化します
-func InitDB(cfg *config.Config) *sql.DB {
-	var db *sql.DB
-	var err error
+// 接続できるまで cfg の設定に従って再試行し、すべて失敗した場合や ctx がキャンセルされた場合はエラーを返します
+// SQLの実行は tp のトレーサーでスパンとして記録されます
+func InitDB(ctx context.Context, cfg *config.Config, tp trace.TracerProvider) (*sql.DB, error) {
+	db, err := openDB(cfg, tp.Tracer(tracing.InstrumentationName))
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	return connect(ctx, cfg, db)
+}
+
+// connect は接続プールを設定し、データベースに接続できるまで再試行します
+// 接続できなかった場合は db を閉じてエラーを返します
+func connect(ctx context.Context, cfg *config.Config, db *sql.DB) (*sql.DB, error) {
+	// 接続プールの設定
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
 
 	// 接続の再試行
-	for i := 0; i < maxRetries; i++ {
-		log.Printf("Attempting to connect to database (attempt %d/%d)...", i+1, maxRetries)
-		
-		db, err = sql.Open("mysql", cfg.GetDSN())
-		if err != nil {
-			log.Printf("Failed to open database connection: %v", err)
-			time.Sleep(retryInterval)
-			continue
-		}
+	var err error
+	backoff := Backoff{Initial: cfg.DBRetryInitialInterval, Max: cfg.DBRetryMaxInterval}
+	for attempt := 1; ; attempt++ {
+		slog.Info("Attempting to connect to database", "attempt", attempt, "max_attempts", cfg.DBConnectMaxAttempts)
 
-		// 接続確認
-		if err := db.Ping(); err != nil {
-			log.Printf("Failed to ping database: %v", err)
-			time.Sleep(retryInterval)
-			continue
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.DBConnectTimeout)
+		err = db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			slog.Info("Connected to database successfully")
+			return db, nil
+		}
+		if attempt >= cfg.DBConnectMaxAttempts {
+			break
 		}
 
-		// 接続成功
-		log.Println("Connected to database successfully")
-		break
+		delay := backoff.Delay(attempt - 1)
+		slog.Warn("Failed to connect to database", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
 	}
 
 	// 全ての再試行が失敗した場合
+	db.Close()
+	return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", cfg.DBConnectMaxAttempts, err)
+}
+
+// openDB はSQLの実行を計装したデータベース接続を開きます
+// 実際の接続は最初の利用時に行われます
+func openDB(cfg *config.Config, tracer trace.Tracer) (*sql.DB, error) {
+	mysqlCfg := cfg.MySQLConfig()
+	if cfg.DBTLSMode == "verify" {
+		tlsCfg, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		mysqlCfg.TLS = tlsCfg
+	}
+
+	connector, err := mysql.NewConnector(mysqlCfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database after %d attempts: %v", maxRetries, err)
+		return nil, err
+	}
+	return sql.OpenDB(tracing.WrapConnector(connector, tracer, "mysql")), nil
+}
+
+// newTLSConfig はサーバー証明書を検証するTLS設定を生成します
+// CA証明書が指定されている場合はそれのみを信頼し、クライアント証明書が指定されている場合は送信します
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.DBTLSServerName,
+	}
+	if tlsCfg.ServerName == "" {
+		tlsCfg.ServerName = cfg.DBHost
 	}
 
-	// 接続設定
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	if cfg.DBTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.DBTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no valid certificates found in CA certificate file")
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.DBTLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.DBTLSCertFile, cfg.DBTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
 
-	return db
+	return tlsCfg, nil
 }
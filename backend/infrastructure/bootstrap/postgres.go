@@ -0,0 +1,27 @@
+package bootstrap
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/otel/trace"
+
+	"project_template/backend/infrastructure/config"
+	"project_template/backend/infrastructure/tracing"
+)
+
+// InitPostgres は PostgreSQL への接続を初期化します
+// 接続の再試行と接続プールの設定は InitDB と同じく cfg に従います
+// SQLの実行は tp のトレーサーでスパンとして記録されます
+func InitPostgres(ctx context.Context, cfg *config.Config, tp trace.TracerProvider) (*sql.DB, error) {
+	pgCfg, err := pgx.ParseConfig(cfg.PostgresDSN())
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	connector := stdlib.GetConnector(*pgCfg)
+	db := sql.OpenDB(tracing.WrapConnector(connector, tp.Tracer(tracing.InstrumentationName), "postgresql"))
+	return connect(ctx, cfg, db)
+}
@@ -0,0 +1,71 @@
+package bootstrap
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel/trace"
+	"modernc.org/sqlite"
+
+	"project_template/backend/infrastructure/config"
+	"project_template/backend/infrastructure/tracing"
+)
+
+// InitSQLite はSQLiteのデータベースファイルを開きます
+// ファイルが存在しない場合は作成します
+// SQLの実行は tp のトレーサーでスパンとして記録されます
+func InitSQLite(ctx context.Context, cfg *config.Config, tp trace.TracerProvider) (*sql.DB, error) {
+	if dir := filepath.Dir(cfg.SQLitePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	connector := &dsnConnector{driver: &sqlite.Driver{}, dsn: sqliteDSN(cfg)}
+	db := sql.OpenDB(tracing.WrapConnector(connector, tp.Tracer(tracing.InstrumentationName), "sqlite"))
+
+	// SQLiteの書き込みはデータベース全体で直列化されるため、接続は1つに限定して SQLITE_BUSY を避ける
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	slog.Info("Opened sqlite database", "path", cfg.SQLitePath)
+	return db, nil
+}
+
+// sqliteDSN はデータベースファイルのパスと接続時のプラグマからDSNを組み立てます
+func sqliteDSN(cfg *config.Config) string {
+	q := url.Values{}
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.SQLiteBusyTimeout.Milliseconds()))
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	// 日時は "YYYY-MM-DD HH:MM:SS+00:00" 形式で保存する（マイグレーションの既定値と同じ形式）
+	q.Set("_time_format", "sqlite")
+	return "file:" + cfg.SQLitePath + "?" + q.Encode()
+}
+
+// dsnConnector はDSNを指定して接続する driver.Connector です
+// driver.DriverContext を実装していないドライバーを sql.OpenDB で使用するために使用します
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+// Connect はデータベースに接続します
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver はドライバーを返します
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
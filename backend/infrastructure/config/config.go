@@ -1,95 +1,290 @@
 package config
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/go-sql-driver/mysql"
 )
 
 // Config はアプリケーション設定を管理します
+//
+// 設定項目はこの構造体のフィールドとして定義し、タグで読み込み方法を指定します
+//   - env:      環境変数名（設定ファイルのキーは小文字、フラグ名は小文字かつ "_" を "-" に置き換えたもの）
+//   - default:  未指定の場合の値
+//   - required: "true" の場合は空の値を許可しない
+//   - secret:   "true" の場合は表示時にマスクする
+//   - storage:  指定したストレージを使用する場合のみ required を検証する
+//
+// 新しい設定項目はフィールドを追加するだけで、すべての読み込み元とフラグに反映されます
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	ServerPort string
+	// Storage はユーザーの永続化先です
+	//   - mysql:  MySQL に保存する
+	//   - postgres: PostgreSQL に保存する
+	//   - sqlite: SQLite のデータベースファイルに保存する（単一のプロセスから利用する場合向け）
+	//   - memory: メモリ上に保持する（データベース不要、停止時にデータは失われる）
+	Storage string `env:"STORAGE" default:"mysql"`
+
+	// SQLitePath は Storage が sqlite の場合のデータベースファイルのパスです
+	SQLitePath string `env:"SQLITE_PATH" default:"data/app.db" required:"true" storage:"sqlite"`
+	// SQLiteBusyTimeout は他の接続による書き込みロックの解放を待つ最大時間です
+	SQLiteBusyTimeout time.Duration `env:"SQLITE_BUSY_TIMEOUT" default:"5s"`
+
+	DBHost     string `env:"MYSQL_HOST" required:"true" storage:"mysql"`
+	DBPort     string `env:"MYSQL_PORT" default:"3306"`
+	DBUser     string `env:"MYSQL_USER" required:"true" storage:"mysql"`
+	DBPassword string `env:"MYSQL_PASSWORD" required:"true" secret:"true" storage:"mysql"`
+	DBName     string `env:"MYSQL_DATABASE" required:"true" storage:"mysql"`
+
+	// Storage が postgres の場合の接続先
+	PGHost     string `env:"POSTGRES_HOST" required:"true" storage:"postgres"`
+	PGPort     string `env:"POSTGRES_PORT" default:"5432"`
+	PGUser     string `env:"POSTGRES_USER" required:"true" storage:"postgres"`
+	PGPassword string `env:"POSTGRES_PASSWORD" required:"true" secret:"true" storage:"postgres"`
+	PGName     string `env:"POSTGRES_DB" required:"true" storage:"postgres"`
+
+	// データベース接続プールの設定
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"25"`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"25"`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"5m"`
+	DBConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"0s"`
+	// データベース接続のタイムアウト（接続確立、読み込み、書き込み）
+	// 読み込み・書き込みのタイムアウトは MySQL のみ有効です
+	DBConnectTimeout time.Duration `env:"DB_CONNECT_TIMEOUT" default:"10s"`
+	DBReadTimeout    time.Duration `env:"DB_READ_TIMEOUT" default:"30s"`
+	DBWriteTimeout   time.Duration `env:"DB_WRITE_TIMEOUT" default:"30s"`
+	// DBConnectMaxAttempts は起動時にデータベースへの接続を試行する最大回数です
+	DBConnectMaxAttempts int `env:"DB_CONNECT_MAX_ATTEMPTS" default:"5"`
+	// 接続の再試行の待ち時間（失敗のたびに倍にし、上限を超えないようにジッターを加えます）
+	DBRetryInitialInterval time.Duration `env:"DB_RETRY_INITIAL_INTERVAL" default:"1s"`
+	DBRetryMaxInterval     time.Duration `env:"DB_RETRY_MAX_INTERVAL" default:"30s"`
+	// DBTxMaxAttempts はデッドロックなどで失敗したトランザクションを実行する最大回数です
+	DBTxMaxAttempts int `env:"DB_TX_MAX_ATTEMPTS" default:"3"`
+	// DBTLSMode はデータベース接続のTLSの使用方法です（PostgreSQL では sslmode に対応します）
+	//   - disabled:    TLSを使用しない
+	//   - preferred:   サーバーが対応していればTLSを使用する（証明書は検証しない）
+	//   - skip-verify: TLSを使用するが証明書は検証しない
+	//   - verify:      TLSを使用し、証明書を検証する
+	DBTLSMode string `env:"DB_TLS_MODE" default:"disabled"`
+	// DBTLSCAFile は verify の場合にサーバー証明書の検証に使用するCA証明書です（未指定の場合はシステムの証明書）
+	DBTLSCAFile string `env:"DB_TLS_CA_FILE"`
+	// DBTLSCertFile と DBTLSKeyFile はクライアント証明書による認証に使用する証明書と秘密鍵です
+	DBTLSCertFile string `env:"DB_TLS_CERT_FILE"`
+	DBTLSKeyFile  string `env:"DB_TLS_KEY_FILE"`
+	// DBTLSServerName はサーバー証明書の検証に使用するホスト名です（未指定の場合は MYSQL_HOST、MySQL のみ有効）
+	DBTLSServerName string `env:"DB_TLS_SERVER_NAME"`
+
+	ServerPort string `env:"SERVER_PORT" default:"8080"`
+	// AdminPort はメトリクスなど管理用エンドポイントを提供するポートです
+	AdminPort string `env:"ADMIN_PORT" default:"9090"`
+	// MigrateOnStart が true の場合、API起動時に未適用のマイグレーションを適用します
+	MigrateOnStart bool `env:"MIGRATE_ON_START" default:"false"`
+
+	// HTTPサーバーのタイムアウト
+	ServerReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" default:"15s"`
+	ServerReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	ServerWriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	ServerIdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"60s"`
+	// ShutdownDelay は停止シグナル受信後、readiness を失敗させてから
+	// 新規リクエストの受け付けを止めるまでの待ち時間です
+	ShutdownDelay time.Duration `env:"SERVER_SHUTDOWN_DELAY" default:"0s"`
+	// ShutdownTimeout は処理中のリクエストの完了を待つ最大時間です
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" default:"20s"`
+	// RequestTimeout は1リクエストの処理に許可する最大時間です
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT" default:"10s"`
+	// RouteTimeouts はルート名ごとの処理の期限です（"users.list=5s" の形式のカンマ区切り）
+	// 指定のないルートには RequestTimeout を使用します
+	RouteTimeouts []string `env:"ROUTE_TIMEOUTS"`
+
+	// UserPurgeRetention は削除したユーザーを完全に削除するまで保持する期間です（この期間内は復元できます）
+	UserPurgeRetention time.Duration `env:"USER_PURGE_RETENTION" default:"720h"`
+	// UserPurgeInterval は保持期間を過ぎたユーザーを完全に削除する処理の実行間隔です
+	UserPurgeInterval time.Duration `env:"USER_PURGE_INTERVAL" default:"1h"`
+
+	// パスワードの文字数の下限と上限です
+	PasswordMinLength int `env:"PASSWORD_MIN_LENGTH" default:"12"`
+	PasswordMaxLength int `env:"PASSWORD_MAX_LENGTH" default:"128"`
+	// パスワードのハッシュ化に使用する Argon2id のパラメータ（メモリ量は KiB）
+	// 変更した場合、既存のパスワードは次回のログイン時に新しいパラメータでハッシュ化し直します
+	Argon2Memory      int `env:"ARGON2_MEMORY" default:"65536"`
+	Argon2Iterations  int `env:"ARGON2_ITERATIONS" default:"3"`
+	Argon2Parallelism int `env:"ARGON2_PARALLELISM" default:"2"`
+	// AccessTokenTTL はログインで発行する JWT アクセストークンの有効期間です
+	AccessTokenTTL time.Duration `env:"ACCESS_TOKEN_TTL" default:"15m"`
+	// RefreshTokenTTL はアクセストークンの再発行に使用するリフレッシュトークンの有効期間です
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" default:"720h"`
+	// RefreshTokenCleanupInterval は有効期限切れのリフレッシュトークンを削除する処理の実行間隔です
+	RefreshTokenCleanupInterval time.Duration `env:"REFRESH_TOKEN_CLEANUP_INTERVAL" default:"1h"`
+	// JWTSigningKeyFiles はアクセストークンの署名鍵（PEM 形式の Ed25519 または RSA の秘密鍵）のファイル一覧（カンマ区切り）です
+	// 先頭の鍵で署名し、すべての鍵で検証します。鍵をローテーションする場合は新しい鍵を先頭に追加します
+	// 未指定の場合は起動ごとに一時的な鍵を生成します（開発用）
+	JWTSigningKeyFiles []string `env:"JWT_SIGNING_KEY_FILES"`
+	// JWTIssuer と JWTAudience はアクセストークンの iss と aud クレームです
+	JWTIssuer   string `env:"JWT_ISSUER" default:"backend"`
+	JWTAudience string `env:"JWT_AUDIENCE" default:"backend"`
+
+	// SessionIdleTimeout は Cookie 認証のセッションが無操作で失効するまでの時間です
+	SessionIdleTimeout time.Duration `env:"SESSION_IDLE_TIMEOUT" default:"30m"`
+	// SessionAbsoluteTimeout はセッションがログインから失効するまでの時間です（操作の有無に関わらず失効します）
+	SessionAbsoluteTimeout time.Duration `env:"SESSION_ABSOLUTE_TIMEOUT" default:"12h"`
+	// SessionCleanupInterval は失効したセッションを削除する処理の実行間隔です
+	SessionCleanupInterval time.Duration `env:"SESSION_CLEANUP_INTERVAL" default:"1h"`
+	// セッションの Cookie の名前・ドメイン・Secure 属性・SameSite 属性（lax, strict, none）です
+	// フロントエンドとAPIが別のサイトの場合は SameSite を none にします（Secure が必要です）
+	SessionCookieName     string `env:"SESSION_COOKIE_NAME" default:"session"`
+	SessionCookieDomain   string `env:"SESSION_COOKIE_DOMAIN"`
+	SessionCookieSecure   bool   `env:"SESSION_COOKIE_SECURE" default:"true"`
+	SessionCookieSameSite string `env:"SESSION_COOKIE_SAMESITE" default:"lax"`
+
+	// 起動時に作成する最初の管理者のメールアドレス・名前・パスワードです
+	// ユーザーの作成は管理者のみが行えるため、最初の管理者はこの設定で作成します
+	// 同じメールアドレスのユーザーが存在する場合は何もしません（既存のユーザーのロールは変更しません）
+	BootstrapAdminEmail    string `env:"BOOTSTRAP_ADMIN_EMAIL"`
+	BootstrapAdminName     string `env:"BOOTSTRAP_ADMIN_NAME" default:"admin"`
+	BootstrapAdminPassword string `env:"BOOTSTRAP_ADMIN_PASSWORD" secret:"true"`
+
+	// CORSAllowedOrigins はクロスオリジンのリクエストを許可するオリジンの一覧（カンマ区切り）です
+	CORSAllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS" default:"http://localhost:3000"`
+
+	// LogFormat はログの出力形式（json または text）です
+	LogFormat string `env:"LOG_FORMAT" default:"json"`
+	// LogLevel は出力するログの最低レベル（debug, info, warn, error）です
+	LogLevel string `env:"LOG_LEVEL" default:"info"`
+
+	// TraceExporter はトレースの出力先（none, stdout, file）です
+	TraceExporter string `env:"TRACE_EXPORTER" default:"none"`
+	// TraceFile は TraceExporter が file の場合の出力先ファイルです
+	TraceFile string `env:"TRACE_FILE" default:"traces.jsonl"`
+	// TraceSampleRatio はトレースをサンプリングする割合（0.0〜1.0）です
+	TraceSampleRatio float64 `env:"TRACE_SAMPLE_RATIO" default:"1.0"`
+	// ServiceName はトレースに記録するサービス名です
+	ServiceName string `env:"SERVICE_NAME" default:"backend"`
+
+	// sources は各設定項目の値の読み込み元です（キーは環境変数名）
+	sources map[string]Source
 }
 
-// NewConfig は環境変数から設定を読み込みます
-func NewConfig() (*Config, error) {
-	// 必須環境変数の確認
-	requiredEnvs := []string{"MYSQL_HOST", "MYSQL_USER", "MYSQL_PASSWORD", "MYSQL_DATABASE"}
-	var missingEnvs []string
+// MySQLConfig はデータベース接続用のドライバー設定を返します
+// DB_TLS_MODE が verify の場合の証明書の設定は含みません（接続時に TLS を設定してください）
+func (c *Config) MySQLConfig() *mysql.Config {
+	m := mysql.NewConfig()
+	m.User = c.DBUser
+	m.Passwd = c.DBPassword
+	m.Net = "tcp"
+	m.Addr = net.JoinHostPort(c.DBHost, c.DBPort)
+	m.DBName = c.DBName
+	m.ParseTime = true
+	// Charset はエラーを返さない
+	_ = m.Apply(mysql.Charset("utf8mb4", "utf8mb4_unicode_ci"))
 
-	for _, env := range requiredEnvs {
-		if os.Getenv(env) == "" {
-			missingEnvs = append(missingEnvs, env)
-		}
-	}
+	m.Timeout = c.DBConnectTimeout
+	m.ReadTimeout = c.DBReadTimeout
+	m.WriteTimeout = c.DBWriteTimeout
 
-	if len(missingEnvs) > 0 {
-		return nil, fmt.Errorf("required environment variables are not set: %v", missingEnvs)
+	switch c.DBTLSMode {
+	case "preferred", "skip-verify":
+		m.TLSConfig = c.DBTLSMode
+	case "verify":
+		m.TLSConfig = "true"
 	}
+	return m
+}
 
-	// 環境変数から設定を取得
-	config := &Config{
-		DBHost:     os.Getenv("MYSQL_HOST"),
-		DBPort:     getEnv("MYSQL_PORT", "3306"),
-		DBUser:     os.Getenv("MYSQL_USER"),
-		DBPassword: os.Getenv("MYSQL_PASSWORD"),
-		DBName:     os.Getenv("MYSQL_DATABASE"),
-		ServerPort: getEnv("SERVER_PORT", "8080"),
+// pgSSLModes は DB_TLS_MODE と PostgreSQL の sslmode の対応表です
+var pgSSLModes = map[string]string{
+	"disabled":    "disable",
+	"preferred":   "prefer",
+	"skip-verify": "require",
+	"verify":      "verify-full",
+}
+
+// PostgresDSN は PostgreSQL 接続用のURL形式のDSNを返します
+// ユーザー名とパスワードはエスケープされるため、記号を含んでいても正しく解釈されます
+func (c *Config) PostgresDSN() string {
+	q := url.Values{}
+	q.Set("sslmode", pgSSLModes[c.DBTLSMode])
+	if c.DBTLSCAFile != "" {
+		q.Set("sslrootcert", c.DBTLSCAFile)
+	}
+	if c.DBTLSCertFile != "" {
+		q.Set("sslcert", c.DBTLSCertFile)
+		q.Set("sslkey", c.DBTLSKeyFile)
+	}
+	if c.DBConnectTimeout > 0 {
+		// connect_timeout は秒単位（1秒未満は切り上げ）
+		q.Set("connect_timeout", strconv.Itoa(int((c.DBConnectTimeout+time.Second-1)/time.Second)))
 	}
 
-	return config, nil
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.PGUser, c.PGPassword),
+		Host:     net.JoinHostPort(c.PGHost, c.PGPort),
+		Path:     "/" + c.PGName,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
 }
 
-// GetDSN はデータベース接続用のDSNを返します
-func (c *Config) GetDSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4&collation=utf8mb4_unicode_ci",
-		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+// DatabaseName はメトリクスなどに記録する接続先のデータベース名を返します
+func (c *Config) DatabaseName() string {
+	switch c.Storage {
+	case "postgres":
+		return c.PGName
+	case "sqlite":
+		return "sqlite"
+	default:
+		return c.DBName
+	}
 }
 
-// LoadEnvFile は指定された環境変数ファイルをロードします
-func LoadEnvFile(fileName string) {
-	// まず、現在のディレクトリを試す
-	err := godotenv.Load(fileName)
-	if err == nil {
-		fmt.Printf("Loaded environment from %s\n", fileName)
-		fmt.Printf("MYSQL_HOST=%s, MYSQL_PORT=%s, MYSQL_USER=%s, MYSQL_DATABASE=%s\n", 
-			os.Getenv("MYSQL_HOST"), 
-			os.Getenv("MYSQL_PORT"), 
-			os.Getenv("MYSQL_USER"), 
-			os.Getenv("MYSQL_DATABASE"))
-		return
+// SessionSameSite はセッションの Cookie の SameSite 属性を返します
+func (c *Config) SessionSameSite() http.SameSite {
+	switch strings.ToLower(c.SessionCookieSameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
 	}
-	
-	// 親ディレクトリを試す（最大3階層）
-	for i := 0; i < 3; i++ {
-		envFile := filepath.Join("..", fileName)
-		err = godotenv.Load(envFile)
-		if err == nil {
-			fmt.Printf("Loaded environment from %s\n", envFile)
-			fmt.Printf("MYSQL_HOST=%s, MYSQL_PORT=%s, MYSQL_USER=%s, MYSQL_DATABASE=%s\n", 
-				os.Getenv("MYSQL_HOST"), 
-				os.Getenv("MYSQL_PORT"), 
-				os.Getenv("MYSQL_USER"), 
-				os.Getenv("MYSQL_DATABASE"))
-			return
+}
+
+// RouteTimeoutMap はルート名ごとの処理の期限を返します
+// 形式の誤りは読み込み時の検証でエラーにするため、ここでは解釈できる項目のみを返します
+func (c *Config) RouteTimeoutMap() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(c.RouteTimeouts))
+	for _, item := range c.RouteTimeouts {
+		if name, d, err := parseRouteTimeout(item); err == nil {
+			timeouts[name] = d
 		}
 	}
-	
-	fmt.Printf("Warning: %s file not found. Using OS environment variables.\n", fileName)
+	return timeouts
 }
 
-// getEnv は環境変数を取得し、未設定の場合はデフォルト値を返します
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// parseRouteTimeout は "ルート名=期限" の形式の項目を解釈します
+func parseRouteTimeout(item string) (string, time.Duration, error) {
+	name, value, ok := strings.Cut(item, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", 0, errors.New("must be in the form route=duration")
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return "", 0, err
+	}
+	if d <= 0 {
+		return "", 0, errors.New("must be positive")
 	}
-	return value
+	return name, d, nil
+}
+
+// GetDSN はデータベース接続用のDSNを返します
+// パスワードなどに記号が含まれていても正しく解釈されるよう、ドライバーの形式で組み立てます
+func (c *Config) GetDSN() string {
+	return c.MySQLConfig().FormatDSN()
 }
@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"project_template/backend/infrastructure/config"
+)
+
+func TestRouteTimeouts(t *testing.T) {
+	cfg, _, err := config.Load([]string{"--storage=memory", "--route-timeouts=users.list=5s, auth.login = 15s"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got := cfg.RouteTimeoutMap()
+	want := map[string]time.Duration{"users.list": 5 * time.Second, "auth.login": 15 * time.Second}
+	if len(got) != len(want) {
+		t.Fatalf("RouteTimeoutMap = %v, want %v", got, want)
+	}
+	for name, d := range want {
+		if got[name] != d {
+			t.Errorf("RouteTimeoutMap[%s] = %v, want %v", name, got[name], d)
+		}
+	}
+}
+
+func TestRouteTimeoutsInvalid(t *testing.T) {
+	for _, value := range []string{"users.list", "=5s", "users.list=soon", "users.list=0s", "users.list=-1s"} {
+		_, _, err := config.Load([]string{"--storage=memory", "--route-timeouts=" + value})
+		var verr *config.ValidationError
+		if !errors.As(err, &verr) || !strings.Contains(err.Error(), "ROUTE_TIMEOUTS") {
+			t.Errorf("Load(%q) = %v, want ROUTE_TIMEOUTS validation error", value, err)
+		}
+	}
+}
+
+func TestCORSAllowedOriginsRejectsWildcard(t *testing.T) {
+	_, _, err := config.Load([]string{"--storage=memory", "--cors-allowed-origins=https://app.example.com,*"})
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) || !strings.Contains(err.Error(), "CORS_ALLOWED_ORIGINS") {
+		t.Errorf("Load(*) = %v, want CORS_ALLOWED_ORIGINS validation error", err)
+	}
+}
+
+func TestCleanupIntervals(t *testing.T) {
+	cfg, _, err := config.Load([]string{"--storage=memory"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.RefreshTokenCleanupInterval != time.Hour {
+		t.Errorf("RefreshTokenCleanupInterval = %v, want 1h by default", cfg.RefreshTokenCleanupInterval)
+	}
+	if cfg.SessionCleanupInterval != time.Hour {
+		t.Errorf("SessionCleanupInterval = %v, want 1h by default", cfg.SessionCleanupInterval)
+	}
+
+	tests := []struct {
+		flag string
+		env  string
+	}{
+		{flag: "--refresh-token-cleanup-interval", env: "REFRESH_TOKEN_CLEANUP_INTERVAL"},
+		{flag: "--session-cleanup-interval", env: "SESSION_CLEANUP_INTERVAL"},
+	}
+	for _, tt := range tests {
+		for _, value := range []string{"0s", "-1m"} {
+			_, _, err := config.Load([]string{"--storage=memory", tt.flag + "=" + value})
+			var verr *config.ValidationError
+			if !errors.As(err, &verr) || !strings.Contains(err.Error(), tt.env) {
+				t.Errorf("Load(%s=%s) = %v, want %s validation error", tt.flag, value, err, tt.env)
+			}
+		}
+	}
+}
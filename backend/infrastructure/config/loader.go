@@ -0,0 +1,315 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Source は設定値の読み込み元です
+type Source string
+
+// 読み込み元（後に並ぶものほど優先されます）
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnvFile Source = "env-file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+const (
+	// secretFileSuffix は値をファイルから読み込む環境変数の接尾辞です（Docker secrets 向け）
+	// 例: MYSQL_PASSWORD_FILE=/run/secrets/db_password
+	secretFileSuffix = "_FILE"
+	// defaultEnvFile は .env ファイルの既定のパスです
+	defaultEnvFile = ".env"
+)
+
+// field は Config のフィールドと読み込み方法の対応です
+type field struct {
+	index    int
+	key      string
+	def      string
+	required bool
+	secret   bool
+	storage  string
+}
+
+// flagName はフィールドに対応するコマンドラインフラグ名を返します
+func (f field) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(f.key), "_", "-")
+}
+
+// fileKey はフィールドに対応する設定ファイルのキーを返します
+func (f field) fileKey() string {
+	return strings.ToLower(f.key)
+}
+
+// fields は Config のタグから設定項目の一覧を生成します
+func fields() []field {
+	t := reflect.TypeOf(Config{})
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		result = append(result, field{
+			index:    i,
+			key:      key,
+			def:      sf.Tag.Get("default"),
+			required: sf.Tag.Get("required") == "true",
+			secret:   sf.Tag.Get("secret") == "true",
+			storage:  sf.Tag.Get("storage"),
+		})
+	}
+	return result
+}
+
+// layer は1つの読み込み元から得た値です
+type layer struct {
+	source Source
+	lookup func(key string) (string, bool, error)
+}
+
+// Load は設定を読み込み、検証します
+//
+// 値は次の順に読み込まれ、後のものが優先されます
+//
+//	デフォルト値 < 設定ファイル（YAML） < .env ファイル < 環境変数 < コマンドラインフラグ
+//
+// 設定ファイルは --config フラグまたは CONFIG_FILE で、.env ファイルは --env-file フラグまたは
+// ENV_FILE で指定します（未指定の場合は .env が存在すれば読み込みます）
+// 環境変数と .env ファイルでは KEY_FILE を指定すると、そのファイルの内容を KEY の値として使用します
+//
+// 戻り値の []string はフラグ以外の引数（サブコマンドとその引数）です
+// 検証に失敗した場合は読み込んだ設定とともに *ValidationError を返します
+func Load(args []string) (*Config, []string, error) {
+	items := fields()
+
+	fset := flag.NewFlagSet("api", flag.ContinueOnError)
+	configFile := fset.String("config", os.Getenv("CONFIG_FILE"), "YAML形式の設定ファイル")
+	envFile := fset.String("env-file", os.Getenv("ENV_FILE"), ".env ファイル（デフォルト: "+defaultEnvFile+"）")
+	for _, f := range items {
+		fset.String(f.flagName(), "", f.key+" を上書きする")
+	}
+	if err := fset.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	fileLayer, err := readConfigFile(*configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	envFileLayer, err := readEnvFile(*envFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	flagValues := make(map[string]string)
+	fset.Visit(func(fl *flag.Flag) {
+		flagValues[fl.Name] = fl.Value.String()
+	})
+
+	layers := []layer{
+		fileLayer,
+		envFileLayer,
+		{source: SourceEnv, lookup: withSecretFile(lookupEnv)},
+		{source: SourceFlag, lookup: func(key string) (string, bool, error) {
+			v, ok := flagValues[field{key: key}.flagName()]
+			return v, ok, nil
+		}},
+	}
+
+	cfg := &Config{sources: make(map[string]Source, len(items))}
+	v := reflect.ValueOf(cfg).Elem()
+	var problems []string
+	for _, f := range items {
+		raw, source := f.def, SourceDefault
+		for _, l := range layers {
+			value, ok, err := l.lookup(f.key)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", f.key, err))
+				continue
+			}
+			if ok {
+				raw, source = value, l.source
+			}
+		}
+		cfg.sources[f.key] = source
+		if err := setField(v.Field(f.index), raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid value %q (from %s): %v", f.key, raw, source, err))
+		}
+	}
+
+	problems = append(problems, cfg.validate(items)...)
+	if len(problems) > 0 {
+		return cfg, fset.Args(), &ValidationError{Problems: problems}
+	}
+	return cfg, fset.Args(), nil
+}
+
+// NewConfig は環境変数から設定を読み込みます
+// コマンドラインフラグは参照しません
+func NewConfig() (*Config, error) {
+	cfg, _, err := Load(nil)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// lookupEnv は空でない環境変数の値を返します
+func lookupEnv(key string) (string, bool, error) {
+	v := os.Getenv(key)
+	return v, v != "", nil
+}
+
+// withSecretFile は KEY が未指定で KEY_FILE が指定されている場合に、そのファイルの内容を返すよう lookup を拡張します
+func withSecretFile(lookup func(string) (string, bool, error)) func(string) (string, bool, error) {
+	return func(key string) (string, bool, error) {
+		if v, ok, err := lookup(key); ok || err != nil {
+			return v, ok, err
+		}
+		path, ok, err := lookup(key + secretFileSuffix)
+		if !ok || err != nil {
+			return "", false, err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s%s: %w", key, secretFileSuffix, err)
+		}
+		// ファイル末尾の改行は値に含めない
+		return strings.TrimRight(string(b), "\r\n"), true, nil
+	}
+}
+
+// readConfigFile はYAML形式の設定ファイルを読み込みます
+// キーは環境変数名の小文字（例: mysql_host）で、リストはカンマ区切りの値として扱います
+func readConfigFile(path string) (layer, error) {
+	l := layer{source: SourceFile, lookup: func(string) (string, bool, error) { return "", false, nil }}
+	if path == "" {
+		return l, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return l, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return l, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[strings.ToLower(k)] = strings.Join(items, ",")
+		default:
+			values[strings.ToLower(k)] = fmt.Sprint(v)
+		}
+	}
+	l.lookup = func(key string) (string, bool, error) {
+		v, ok := values[field{key: key}.fileKey()]
+		return v, ok, nil
+	}
+	return l, nil
+}
+
+// readEnvFile は .env ファイルを読み込みます
+// path が空の場合は既定の .env を、存在する場合のみ読み込みます
+func readEnvFile(path string) (layer, error) {
+	l := layer{source: SourceEnvFile, lookup: func(string) (string, bool, error) { return "", false, nil }}
+	optional := path == ""
+	if optional {
+		path = defaultEnvFile
+	}
+
+	values, err := godotenv.Read(path)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return l, nil
+		}
+		return l, fmt.Errorf("failed to read env file: %w", err)
+	}
+	l.lookup = withSecretFile(func(key string) (string, bool, error) {
+		v := values[key]
+		return v, v != "", nil
+	})
+	return l, nil
+}
+
+// setField は文字列の値をフィールドの型に変換して設定します
+func setField(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// formatField はフィールドの値を設定値の文字列形式で返します
+func formatField(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String()
+	case []string:
+		return strings.Join(x, ",")
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+)
+
+// redactedValue はマスクした機密情報の表示です
+const redactedValue = "******"
+
+// Print は有効な設定値とその読み込み元を "KEY=value" 形式で出力します
+// redacted が true の場合、機密情報（secret タグの付いた項目）の値をマスクします
+func (c *Config) Print(w io.Writer, redacted bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range fields() {
+		value := formatField(v.Field(f.index))
+		if redacted && f.secret && value != "" {
+			value = redactedValue
+		}
+		source := c.sources[f.key]
+		if source == "" {
+			source = SourceDefault
+		}
+		fmt.Fprintf(tw, "%s=%s\t# %s\n", f.key, value, source)
+	}
+	return tw.Flush()
+}
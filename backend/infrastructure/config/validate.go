@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationError は設定の検証で見つかったすべての問題を表します
+type ValidationError struct {
+	Problems []string
+}
+
+// Error はエラーメッセージを返します
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validate は設定値を検証し、見つかったすべての問題を返します
+func (c *Config) validate(items []field) []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range items {
+		if f.required && (f.storage == "" || f.storage == c.Storage) && v.Field(f.index).IsZero() {
+			add("%s: is required", f.key)
+		}
+	}
+
+	switch c.Storage {
+	case "mysql", "postgres", "sqlite", "memory":
+	default:
+		add("STORAGE: must be one of mysql, postgres, sqlite, memory, got %q", c.Storage)
+	}
+	if c.SQLiteBusyTimeout < 0 {
+		add("SQLITE_BUSY_TIMEOUT: must not be negative")
+	}
+
+	for _, p := range []struct{ key, value string }{
+		{"MYSQL_PORT", c.DBPort},
+		{"POSTGRES_PORT", c.PGPort},
+		{"SERVER_PORT", c.ServerPort},
+		{"ADMIN_PORT", c.AdminPort},
+	} {
+		if n, err := strconv.Atoi(p.value); err != nil || n < 1 || n > 65535 {
+			add("%s: must be a port number between 1 and 65535, got %q", p.key, p.value)
+		}
+	}
+	if c.ServerPort == c.AdminPort {
+		add("ADMIN_PORT: must differ from SERVER_PORT")
+	}
+
+	if c.DBMaxOpenConns < 0 || c.DBMaxIdleConns < 0 {
+		add("DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS: must not be negative")
+	}
+	if c.DBMaxOpenConns > 0 && c.DBMaxIdleConns > c.DBMaxOpenConns {
+		add("DB_MAX_IDLE_CONNS: must not exceed DB_MAX_OPEN_CONNS (%d)", c.DBMaxOpenConns)
+	}
+	if c.DBConnMaxLifetime < 0 || c.DBConnMaxIdleTime < 0 {
+		add("DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME: must not be negative")
+	}
+	if c.DBConnectTimeout <= 0 {
+		add("DB_CONNECT_TIMEOUT: must be positive")
+	}
+	if c.DBReadTimeout < 0 || c.DBWriteTimeout < 0 {
+		add("DB_READ_TIMEOUT, DB_WRITE_TIMEOUT: must not be negative")
+	}
+	if c.DBConnectMaxAttempts < 1 {
+		add("DB_CONNECT_MAX_ATTEMPTS: must be at least 1")
+	}
+	if c.DBRetryInitialInterval <= 0 || c.DBRetryMaxInterval < c.DBRetryInitialInterval {
+		add("DB_RETRY_INITIAL_INTERVAL, DB_RETRY_MAX_INTERVAL: must be positive and initial must not exceed max")
+	}
+	if c.DBTxMaxAttempts < 1 {
+		add("DB_TX_MAX_ATTEMPTS: must be at least 1")
+	}
+	switch c.DBTLSMode {
+	case "disabled", "preferred", "skip-verify", "verify":
+	default:
+		add("DB_TLS_MODE: must be one of disabled, preferred, skip-verify, verify, got %q", c.DBTLSMode)
+	}
+	if (c.DBTLSCertFile == "") != (c.DBTLSKeyFile == "") {
+		add("DB_TLS_CERT_FILE, DB_TLS_KEY_FILE: must be set together")
+	}
+	if c.DBTLSMode != "verify" && (c.DBTLSCAFile != "" || c.DBTLSCertFile != "" || c.DBTLSServerName != "") {
+		add("DB_TLS_CA_FILE, DB_TLS_CERT_FILE, DB_TLS_SERVER_NAME: require DB_TLS_MODE=verify")
+	}
+
+	if c.ServerReadTimeout < 0 || c.ServerReadHeaderTimeout < 0 || c.ServerWriteTimeout < 0 || c.ServerIdleTimeout < 0 {
+		add("SERVER_*_TIMEOUT: must not be negative")
+	}
+	if c.ShutdownDelay < 0 {
+		add("SERVER_SHUTDOWN_DELAY: must not be negative")
+	}
+	if c.ShutdownTimeout <= 0 {
+		add("SERVER_SHUTDOWN_TIMEOUT: must be positive")
+	}
+	if c.RequestTimeout <= 0 {
+		add("REQUEST_TIMEOUT: must be positive")
+	}
+	for _, item := range c.RouteTimeouts {
+		if _, _, err := parseRouteTimeout(item); err != nil {
+			add("ROUTE_TIMEOUTS: invalid entry %q: %v", item, err)
+		}
+	}
+	if c.UserPurgeRetention <= 0 || c.UserPurgeInterval <= 0 {
+		add("USER_PURGE_RETENTION, USER_PURGE_INTERVAL: must be positive")
+	}
+	if c.PasswordMinLength < 1 || c.PasswordMaxLength < c.PasswordMinLength || c.PasswordMaxLength > 1024 {
+		add("PASSWORD_MIN_LENGTH, PASSWORD_MAX_LENGTH: must be positive, min must not exceed max, and max must be at most 1024")
+	}
+	if c.Argon2Iterations < 1 || c.Argon2Parallelism < 1 || c.Argon2Parallelism > 255 {
+		add("ARGON2_ITERATIONS, ARGON2_PARALLELISM: iterations must be at least 1 and parallelism between 1 and 255")
+	}
+	// Argon2 のメモリ量は並列度の8倍以上が必要（上限は 4GiB）
+	if c.Argon2Memory < 8*c.Argon2Parallelism || c.Argon2Memory > 4<<20 {
+		add("ARGON2_MEMORY: must be between 8*ARGON2_PARALLELISM and 4194304 KiB, got %d", c.Argon2Memory)
+	}
+	if c.AccessTokenTTL <= 0 || c.RefreshTokenTTL <= 0 {
+		add("ACCESS_TOKEN_TTL, REFRESH_TOKEN_TTL: must be positive")
+	} else if c.RefreshTokenTTL <= c.AccessTokenTTL {
+		add("REFRESH_TOKEN_TTL: must be longer than ACCESS_TOKEN_TTL")
+	}
+	if c.RefreshTokenCleanupInterval <= 0 {
+		add("REFRESH_TOKEN_CLEANUP_INTERVAL: must be positive")
+	}
+	if c.JWTIssuer == "" || c.JWTAudience == "" {
+		add("JWT_ISSUER, JWT_AUDIENCE: must not be empty")
+	}
+	if c.SessionIdleTimeout <= 0 || c.SessionAbsoluteTimeout < c.SessionIdleTimeout {
+		add("SESSION_IDLE_TIMEOUT, SESSION_ABSOLUTE_TIMEOUT: must be positive and the idle timeout must not exceed the absolute timeout")
+	}
+	if c.SessionCleanupInterval <= 0 {
+		add("SESSION_CLEANUP_INTERVAL: must be positive")
+	}
+	if c.SessionCookieName == "" || strings.ContainsAny(c.SessionCookieName, " \t;,=\"") {
+		add("SESSION_COOKIE_NAME: must be a non-empty cookie name, got %q", c.SessionCookieName)
+	}
+	if !oneOf(c.SessionCookieSameSite, "lax", "strict", "none") {
+		add("SESSION_COOKIE_SAMESITE: must be one of lax, strict, none, got %q", c.SessionCookieSameSite)
+	} else if strings.EqualFold(c.SessionCookieSameSite, "none") && !c.SessionCookieSecure {
+		add("SESSION_COOKIE_SAMESITE: none requires SESSION_COOKIE_SECURE=true")
+	}
+	if c.BootstrapAdminEmail != "" && (c.BootstrapAdminName == "" || c.BootstrapAdminPassword == "") {
+		add("BOOTSTRAP_ADMIN_NAME, BOOTSTRAP_ADMIN_PASSWORD: required when BOOTSTRAP_ADMIN_EMAIL is set")
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		// Cookie のセッションで認証するため、すべてのオリジンを許可すると任意のサイトから認証付きで呼び出せてしまう
+		if origin == "*" {
+			add("CORS_ALLOWED_ORIGINS: \"*\" is not allowed; list the allowed origins explicitly")
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			add("CORS_ALLOWED_ORIGINS: invalid origin %q", origin)
+		}
+	}
+
+	if !oneOf(c.LogFormat, "json", "text") {
+		add("LOG_FORMAT: must be one of json, text, got %q", c.LogFormat)
+	}
+	if !oneOf(c.LogLevel, "debug", "info", "warn", "error") {
+		add("LOG_LEVEL: must be one of debug, info, warn, error, got %q", c.LogLevel)
+	}
+
+	if !oneOf(c.TraceExporter, "none", "stdout", "file") {
+		add("TRACE_EXPORTER: must be one of none, stdout, file, got %q", c.TraceExporter)
+	}
+	if c.TraceExporter == "file" && c.TraceFile == "" {
+		add("TRACE_FILE: is required when TRACE_EXPORTER is file")
+	}
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		add("TRACE_SAMPLE_RATIO: must be between 0.0 and 1.0, got %v", c.TraceSampleRatio)
+	}
+
+	return problems
+}
+
+// oneOf は値が候補のいずれかと大文字小文字を区別せずに一致するかを返します
+func oneOf(value string, candidates ...string) bool {
+	for _, c := range candidates {
+		if strings.EqualFold(value, c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package migration
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// files はバイナリに埋め込まれたマイグレーションファイル群です
+// データベースごとにディレクトリを分け、ファイル名は "<バージョン>_<名前>.up.sql" / "<バージョン>_<名前>.down.sql" の形式とします
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// 各データベース向けのマイグレーションファイル群
+var (
+	MySQL    = sub("mysql")
+	Postgres = sub("postgres")
+	SQLite   = sub("sqlite")
+)
+
+// sub はデータベースのディレクトリをルートとするファイルシステムを返します
+func sub(dir string) fs.FS {
+	fsys, err := fs.Sub(files, dir)
+	if err != nil {
+		// ディレクトリ名は定数のため、失敗するのは実装の誤りのみ
+		panic(err)
+	}
+	return fsys
+}
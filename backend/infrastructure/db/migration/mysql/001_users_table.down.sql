@@ -0,0 +1,2 @@
+-- ユーザーテーブルを削除
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,4 @@
+-- ユーザー一覧のキーセットページネーション用インデックスを削除
+DROP INDEX idx_users_name_id ON users;
+DROP INDEX idx_users_updated_at_id ON users;
+DROP INDEX idx_users_created_at_id ON users;
@@ -0,0 +1,3 @@
+-- メールアドレスの照合順序をテーブルのデフォルトに戻す
+ALTER TABLE users
+  MODIFY email VARCHAR(255) NOT NULL;
@@ -0,0 +1,5 @@
+-- メールアドレスの一意性を大文字小文字を区別せずにスキーマで保証する
+-- アクセントは区別し、大文字小文字のみを同一視する照合順序を使用する
+-- 既に大文字小文字違いの重複データが存在する場合は事前に解消すること
+ALTER TABLE users
+  MODIFY email VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_as_ci NOT NULL;
@@ -0,0 +1,3 @@
+-- 楽観的排他制御のためのバージョンを削除する
+ALTER TABLE users
+  DROP COLUMN version;
@@ -0,0 +1,4 @@
+-- 楽観的排他制御のためのバージョンを追加する
+-- 既存のユーザーはバージョン1とする
+ALTER TABLE users
+  ADD COLUMN version BIGINT NOT NULL DEFAULT 1 AFTER email;
@@ -0,0 +1,10 @@
+-- ソフトデリートを取りやめ、メールアドレスをすべてのユーザーの間で一意に戻す
+-- 削除済みのユーザーは表現できなくなるため、先に完全に削除する
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+ALTER TABLE users
+  ADD UNIQUE KEY email (email),
+  DROP INDEX idx_users_deleted_at,
+  DROP INDEX idx_users_email,
+  DROP INDEX uq_users_active_email,
+  DROP COLUMN active_email,
+  DROP COLUMN deleted_at;
@@ -0,0 +1,11 @@
+-- ソフトデリートのための削除日時を追加する
+-- メールアドレスは削除されていないユーザーの間でのみ一意とし、削除済みユーザーのメールアドレスを再利用できるようにする
+-- MySQL は条件付きのインデックスに対応していないため、削除済みの場合は NULL になる生成列に一意制約を設定する
+ALTER TABLE users
+  ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL AFTER version,
+  ADD COLUMN active_email VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_as_ci
+    GENERATED ALWAYS AS (IF(deleted_at IS NULL, email, NULL)) STORED,
+  ADD UNIQUE KEY uq_users_active_email (active_email),
+  ADD KEY idx_users_email (email),
+  ADD KEY idx_users_deleted_at (deleted_at),
+  DROP INDEX email;
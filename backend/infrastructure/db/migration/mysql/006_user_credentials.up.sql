@@ -0,0 +1,10 @@
+-- ユーザーのパスワード認証情報を保存するテーブルを作成
+-- パスワードはハッシュ化した値（アルゴリズムとパラメータを含む PHC 文字列形式）のみを保存する
+-- ユーザーを完全に削除した場合は認証情報も削除する
+CREATE TABLE IF NOT EXISTS user_credentials (
+  user_id VARCHAR(36) PRIMARY KEY,
+  password_hash VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_user_credentials_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+-- リフレッシュトークンのテーブルを削除
+DROP TABLE IF EXISTS refresh_tokens;
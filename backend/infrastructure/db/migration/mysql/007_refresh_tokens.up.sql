@@ -0,0 +1,17 @@
+-- リフレッシュトークンを保存するテーブルを作成
+-- トークンはハッシュ値のみを保存し、ローテーションで使用済みになったトークンも再利用の検知のため有効期限まで残す
+-- ユーザーを完全に削除した場合はリフレッシュトークンも削除する
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  id VARCHAR(36) PRIMARY KEY,
+  family_id VARCHAR(36) NOT NULL,
+  user_id VARCHAR(36) NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  used_at TIMESTAMP NULL,
+  revoked_at TIMESTAMP NULL,
+  UNIQUE KEY uq_refresh_tokens_token_hash (token_hash),
+  KEY idx_refresh_tokens_family_id (family_id),
+  KEY idx_refresh_tokens_expires_at (expires_at),
+  CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
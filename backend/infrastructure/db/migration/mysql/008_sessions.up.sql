@@ -0,0 +1,21 @@
+-- Cookie 認証のサーバー側セッションを保存するテーブルを作成
+-- セッショントークンはハッシュ値のみを保存する
+-- idle_expires_at は最終アクセスから無操作で失効する日時、expires_at はログインからの絶対的な有効期限
+-- ユーザーを完全に削除した場合はセッションも削除する
+CREATE TABLE IF NOT EXISTS sessions (
+  id VARCHAR(36) PRIMARY KEY,
+  user_id VARCHAR(36) NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  csrf_token VARCHAR(64) NOT NULL,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  idle_expires_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  UNIQUE KEY uq_sessions_token_hash (token_hash),
+  KEY idx_sessions_user_id_last_seen_at (user_id, last_seen_at),
+  KEY idx_sessions_idle_expires_at (idle_expires_at),
+  KEY idx_sessions_expires_at (expires_at),
+  CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+-- 権限の判定のためのロールを追加する
+-- 既存のユーザーは一般ユーザー（member）とする
+ALTER TABLE users
+  ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'member' AFTER email;
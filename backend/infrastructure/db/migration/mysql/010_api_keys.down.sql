@@ -0,0 +1,2 @@
+-- API キーのテーブルを削除
+DROP TABLE IF EXISTS api_keys;
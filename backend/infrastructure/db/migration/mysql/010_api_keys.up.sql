@@ -0,0 +1,18 @@
+-- サービス間の呼び出しに使用する API キーを保存するテーブルを作成
+-- キーはハッシュ値のみを保存し、prefix はキーの識別と検索に使用する
+-- scopes は許可するスコープの空白区切りの一覧
+-- created_by は作成した管理者のユーザーIDで、記録のみのため外部キーにはしない
+CREATE TABLE IF NOT EXISTS api_keys (
+  id VARCHAR(36) PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  prefix VARCHAR(32) NOT NULL,
+  secret_hash CHAR(64) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  created_by VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP NULL,
+  last_used_at TIMESTAMP NULL,
+  revoked_at TIMESTAMP NULL,
+  UNIQUE KEY uq_api_keys_prefix (prefix),
+  KEY idx_api_keys_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+-- ユーザーテーブルを削除
+DROP TABLE IF EXISTS users;
+DROP FUNCTION IF EXISTS users_set_updated_at();
@@ -0,0 +1,28 @@
+-- ユーザーテーブルを作成
+-- name と email は大文字小文字を区別せずに比較・並び替え・一意性を判定する citext 型とする
+-- id はバイト順で並ぶよう照合順序 "C" を使用する
+-- 日時は MySQL の TIMESTAMP 型と同じく秒単位で保存する
+CREATE EXTENSION IF NOT EXISTS citext;
+
+CREATE TABLE IF NOT EXISTS users (
+  id VARCHAR(36) COLLATE "C" PRIMARY KEY,
+  name CITEXT NOT NULL,
+  email CITEXT NOT NULL,
+  created_at TIMESTAMPTZ(0) NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ(0) NOT NULL DEFAULT now(),
+  CONSTRAINT users_email_key UNIQUE (email)
+);
+
+-- MySQL の ON UPDATE CURRENT_TIMESTAMP に相当する処理
+-- updated_at を指定せずに更新した場合のみ現在時刻を設定する
+CREATE OR REPLACE FUNCTION users_set_updated_at() RETURNS trigger AS $$
+BEGIN
+  IF NEW.updated_at = OLD.updated_at THEN
+    NEW.updated_at = now();
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_updated_at BEFORE UPDATE ON users
+  FOR EACH ROW EXECUTE FUNCTION users_set_updated_at();
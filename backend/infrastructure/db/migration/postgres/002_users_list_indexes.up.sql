@@ -0,0 +1,4 @@
+-- ユーザー一覧のキーセットページネーション用インデックスを作成
+CREATE INDEX idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX idx_users_updated_at_id ON users (updated_at, id);
+CREATE INDEX idx_users_name_id ON users (name, id);
@@ -0,0 +1,7 @@
+-- ソフトデリートを取りやめ、メールアドレスをすべてのユーザーの間で一意に戻す
+-- 削除済みのユーザーは表現できなくなるため、先に完全に削除する
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+DROP INDEX idx_users_deleted_at;
+DROP INDEX users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,6 @@
+-- ソフトデリートのための削除日時を追加する
+-- メールアドレスは削除されていないユーザーの間でのみ一意とし、削除済みユーザーのメールアドレスを再利用できるようにする
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ(0);
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX users_email_key ON users (email) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
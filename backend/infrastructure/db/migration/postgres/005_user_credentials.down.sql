@@ -0,0 +1,2 @@
+-- ユーザーの認証情報テーブルを削除
+DROP TABLE IF EXISTS user_credentials;
@@ -0,0 +1,9 @@
+-- ユーザーのパスワード認証情報を保存するテーブルを作成
+-- パスワードはハッシュ化した値（アルゴリズムとパラメータを含む PHC 文字列形式）のみを保存する
+-- ユーザーを完全に削除した場合は認証情報も削除する
+CREATE TABLE IF NOT EXISTS user_credentials (
+  user_id VARCHAR(36) COLLATE "C" PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+  password_hash VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ(0) NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ(0) NOT NULL DEFAULT now()
+);
@@ -0,0 +1,18 @@
+-- リフレッシュトークンを保存するテーブルを作成
+-- トークンはハッシュ値のみを保存し、ローテーションで使用済みになったトークンも再利用の検知のため有効期限まで残す
+-- ユーザーを完全に削除した場合はリフレッシュトークンも削除する
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  id VARCHAR(36) COLLATE "C" PRIMARY KEY,
+  family_id VARCHAR(36) COLLATE "C" NOT NULL,
+  user_id VARCHAR(36) COLLATE "C" NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  token_hash CHAR(64) NOT NULL,
+  expires_at TIMESTAMPTZ(0) NOT NULL,
+  created_at TIMESTAMPTZ(0) NOT NULL DEFAULT now(),
+  used_at TIMESTAMPTZ(0),
+  revoked_at TIMESTAMPTZ(0),
+  CONSTRAINT refresh_tokens_token_hash_key UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
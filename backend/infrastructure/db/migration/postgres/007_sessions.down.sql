@@ -0,0 +1,2 @@
+-- セッションのテーブルを削除
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,21 @@
+-- Cookie 認証のサーバー側セッションを保存するテーブルを作成
+-- セッショントークンはハッシュ値のみを保存する
+-- idle_expires_at は最終アクセスから無操作で失効する日時、expires_at はログインからの絶対的な有効期限
+-- ユーザーを完全に削除した場合はセッションも削除する
+CREATE TABLE IF NOT EXISTS sessions (
+  id VARCHAR(36) COLLATE "C" PRIMARY KEY,
+  user_id VARCHAR(36) COLLATE "C" NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  token_hash CHAR(64) NOT NULL,
+  csrf_token VARCHAR(64) NOT NULL,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ(0) NOT NULL DEFAULT now(),
+  last_seen_at TIMESTAMPTZ(0) NOT NULL DEFAULT now(),
+  idle_expires_at TIMESTAMPTZ(0) NOT NULL,
+  expires_at TIMESTAMPTZ(0) NOT NULL,
+  CONSTRAINT sessions_token_hash_key UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_sessions_user_id_last_seen_at ON sessions (user_id, last_seen_at);
+CREATE INDEX idx_sessions_idle_expires_at ON sessions (idle_expires_at);
+CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
@@ -0,0 +1,3 @@
+-- 権限の判定のためのロールを削除する
+ALTER TABLE users
+  DROP COLUMN role;
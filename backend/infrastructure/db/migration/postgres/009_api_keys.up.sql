@@ -0,0 +1,19 @@
+-- サービス間の呼び出しに使用する API キーを保存するテーブルを作成
+-- キーはハッシュ値のみを保存し、prefix はキーの識別と検索に使用する
+-- scopes は許可するスコープの空白区切りの一覧
+-- created_by は作成した管理者のユーザーIDで、記録のみのため外部キーにはしない
+CREATE TABLE IF NOT EXISTS api_keys (
+  id VARCHAR(36) COLLATE "C" PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  prefix VARCHAR(32) COLLATE "C" NOT NULL,
+  secret_hash CHAR(64) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  created_by VARCHAR(36) COLLATE "C" NOT NULL,
+  created_at TIMESTAMPTZ(0) NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ(0),
+  last_used_at TIMESTAMPTZ(0),
+  revoked_at TIMESTAMPTZ(0),
+  CONSTRAINT api_keys_prefix_key UNIQUE (prefix)
+);
+
+CREATE INDEX idx_api_keys_created_at ON api_keys (created_at);
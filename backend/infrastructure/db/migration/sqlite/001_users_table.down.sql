@@ -0,0 +1,3 @@
+-- ユーザーテーブルを削除
+DROP TRIGGER IF EXISTS users_updated_at;
+DROP TABLE IF EXISTS users;
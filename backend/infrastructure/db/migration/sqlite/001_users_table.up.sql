@@ -0,0 +1,14 @@
+-- ユーザーテーブルを作成
+-- メールアドレスは大文字小文字を区別しない照合順序で一意とする（NOCASE はASCIIのみを同一視する）
+-- 日時はアプリケーションと同じ "YYYY-MM-DD HH:MM:SS+00:00" 形式（UTC）の文字列で保存する
+CREATE TABLE IF NOT EXISTS users (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL COLLATE NOCASE,
+  email TEXT NOT NULL COLLATE NOCASE UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+
+-- MySQL の ON UPDATE CURRENT_TIMESTAMP に相当する処理
+-- updated_at を指定せずに更新した場合のみ現在時刻を設定する
+CREATE TRIGGER IF NOT EXISTS users_updated_at AFTER UPDATE ON users FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at BEGIN UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now') WHERE id = NEW.id; END;
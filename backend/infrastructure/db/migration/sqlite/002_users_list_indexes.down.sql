@@ -0,0 +1,4 @@
+-- ユーザー一覧のキーセットページネーション用インデックスを削除
+DROP INDEX IF EXISTS idx_users_name_id;
+DROP INDEX IF EXISTS idx_users_updated_at_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
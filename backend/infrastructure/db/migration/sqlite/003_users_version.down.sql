@@ -0,0 +1,2 @@
+-- 楽観的排他制御のためのバージョンを削除する
+ALTER TABLE users DROP COLUMN version;
@@ -0,0 +1,20 @@
+-- ソフトデリートを取りやめ、メールアドレスをすべてのユーザーの間で一意に戻す
+-- 削除済みのユーザーは表現できなくなるため、完全に削除してからテーブルを作り直す
+CREATE TABLE users_old (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL COLLATE NOCASE,
+  email TEXT NOT NULL COLLATE NOCASE UNIQUE,
+  version INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
+INSERT INTO users_old (id, name, email, version, created_at, updated_at)
+  SELECT id, name, email, version, created_at, updated_at FROM users WHERE deleted_at IS NULL;
+DROP TRIGGER IF EXISTS users_updated_at;
+DROP TABLE users;
+ALTER TABLE users_old RENAME TO users;
+
+CREATE INDEX idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX idx_users_updated_at_id ON users (updated_at, id);
+CREATE INDEX idx_users_name_id ON users (name, id);
+CREATE TRIGGER IF NOT EXISTS users_updated_at AFTER UPDATE ON users FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at BEGIN UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now') WHERE id = NEW.id; END;
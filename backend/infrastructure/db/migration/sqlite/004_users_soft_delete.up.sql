@@ -0,0 +1,24 @@
+-- ソフトデリートのための削除日時を追加する
+-- メールアドレスは削除されていないユーザーの間でのみ一意とし、削除済みユーザーのメールアドレスを再利用できるようにする
+-- SQLite は列の UNIQUE 制約を削除できないため、テーブルを作り直す
+CREATE TABLE users_new (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL COLLATE NOCASE,
+  email TEXT NOT NULL COLLATE NOCASE,
+  version INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  deleted_at TIMESTAMP
+);
+INSERT INTO users_new (id, name, email, version, created_at, updated_at)
+  SELECT id, name, email, version, created_at, updated_at FROM users;
+DROP TRIGGER IF EXISTS users_updated_at;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE UNIQUE INDEX users_email_active ON users (email) WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX idx_users_updated_at_id ON users (updated_at, id);
+CREATE INDEX idx_users_name_id ON users (name, id);
+CREATE TRIGGER IF NOT EXISTS users_updated_at AFTER UPDATE ON users FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at BEGIN UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now') WHERE id = NEW.id; END;
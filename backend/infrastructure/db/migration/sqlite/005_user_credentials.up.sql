@@ -0,0 +1,9 @@
+-- ユーザーのパスワード認証情報を保存するテーブルを作成
+-- パスワードはハッシュ化した値（アルゴリズムとパラメータを含む PHC 文字列形式）のみを保存する
+-- ユーザーを完全に削除した場合は認証情報も削除する（接続時に foreign_keys を有効にしている）
+CREATE TABLE IF NOT EXISTS user_credentials (
+  user_id TEXT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+  password_hash TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+);
@@ -0,0 +1,17 @@
+-- リフレッシュトークンを保存するテーブルを作成
+-- トークンはハッシュ値のみを保存し、ローテーションで使用済みになったトークンも再利用の検知のため有効期限まで残す
+-- ユーザーを完全に削除した場合はリフレッシュトークンも削除する（接続時に foreign_keys を有効にしている）
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  id TEXT PRIMARY KEY,
+  family_id TEXT NOT NULL,
+  user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  token_hash TEXT NOT NULL UNIQUE,
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  used_at TIMESTAMP,
+  revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
@@ -0,0 +1,20 @@
+-- Cookie 認証のサーバー側セッションを保存するテーブルを作成
+-- セッショントークンはハッシュ値のみを保存する
+-- idle_expires_at は最終アクセスから無操作で失効する日時、expires_at はログインからの絶対的な有効期限
+-- ユーザーを完全に削除した場合はセッションも削除する（接続時に foreign_keys を有効にしている）
+CREATE TABLE IF NOT EXISTS sessions (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  token_hash TEXT NOT NULL UNIQUE,
+  csrf_token TEXT NOT NULL,
+  user_agent TEXT NOT NULL DEFAULT '',
+  ip_address TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  last_seen_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  idle_expires_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_sessions_user_id_last_seen_at ON sessions (user_id, last_seen_at);
+CREATE INDEX idx_sessions_idle_expires_at ON sessions (idle_expires_at);
+CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
@@ -0,0 +1,2 @@
+-- 権限の判定のためのロールを削除する
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,3 @@
+-- 権限の判定のためのロールを追加する
+-- 既存のユーザーは一般ユーザー（member）とする
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
@@ -0,0 +1,18 @@
+-- サービス間の呼び出しに使用する API キーを保存するテーブルを作成
+-- キーはハッシュ値のみを保存し、prefix はキーの識別と検索に使用する
+-- scopes は許可するスコープの空白区切りの一覧
+-- created_by は作成した管理者のユーザーIDで、記録のみのため外部キーにはしない
+CREATE TABLE IF NOT EXISTS api_keys (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  prefix TEXT NOT NULL UNIQUE,
+  secret_hash TEXT NOT NULL,
+  scopes TEXT NOT NULL,
+  created_by TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')),
+  expires_at TIMESTAMP,
+  last_used_at TIMESTAMP,
+  revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_created_at ON api_keys (created_at);
@@ -0,0 +1,190 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect はデータベースごとに異なるマイグレーション管理の処理です
+type Dialect interface {
+	// Lock は複数のプロセスが同時にマイグレーションしないよう conn でロックを取得します
+	// timeout までに取得できない場合は ErrLockTimeout を返します
+	Lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error
+	// Unlock は Lock で取得したロックを解放します
+	Unlock(ctx context.Context, conn *sql.Conn) error
+	// CreateTableSQL は schema_migrations テーブルを作成するSQLを返します
+	CreateTableSQL() string
+	// TableExistsSQL は schema_migrations テーブルがあれば 1、なければ 0 を返すSQLを返します
+	TableExistsSQL() string
+	// Rebind は "?" プレースホルダーをデータベースの形式に置き換えます
+	Rebind(query string) string
+	// TransactionalDDL は DDL をトランザクション内で実行してロールバックできるか返します
+	TransactionalDDL() bool
+}
+
+// MySQL は MySQL 向けの Dialect です
+// GET_LOCK によるアドバイザリロックを使用します
+type MySQL struct{}
+
+// Lock はアドバイザリロックを取得します
+func (MySQL) Lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	var got sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(timeout.Seconds())).Scan(&got)
+	if err != nil {
+		return err
+	}
+	if !got.Valid || got.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	return nil
+}
+
+// Unlock はアドバイザリロックを解放します
+func (MySQL) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+	return err
+}
+
+// CreateTableSQL は schema_migrations テーブルを作成するSQLを返します
+func (MySQL) CreateTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  checksum CHAR(64) NOT NULL,
+  dirty BOOLEAN NOT NULL DEFAULT FALSE,
+  applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+}
+
+// TableExistsSQL は schema_migrations テーブルの有無を確認するSQLを返します
+func (MySQL) TableExistsSQL() string {
+	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'"
+}
+
+// Rebind はクエリをそのまま返します
+func (MySQL) Rebind(query string) string {
+	return query
+}
+
+// TransactionalDDL は false を返します（MySQL の DDL は暗黙的にコミットされます）
+func (MySQL) TransactionalDDL() bool {
+	return false
+}
+
+// SQLite は SQLite 向けの Dialect です
+// SQLite は1つのプロセスから利用する前提のため、ロックは取得しません
+// （書き込み中はデータベースファイル自体がロックされます）
+type SQLite struct{}
+
+// Lock は何もしません
+func (SQLite) Lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	return nil
+}
+
+// Unlock は何もしません
+func (SQLite) Unlock(ctx context.Context, conn *sql.Conn) error {
+	return nil
+}
+
+// CreateTableSQL は schema_migrations テーブルを作成するSQLを返します
+func (SQLite) CreateTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  checksum TEXT NOT NULL,
+  dirty BOOLEAN NOT NULL DEFAULT FALSE,
+  applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+}
+
+// TableExistsSQL は schema_migrations テーブルの有無を確認するSQLを返します
+func (SQLite) TableExistsSQL() string {
+	return "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+}
+
+// Rebind はクエリをそのまま返します
+func (SQLite) Rebind(query string) string {
+	return query
+}
+
+// TransactionalDDL は true を返します
+func (SQLite) TransactionalDDL() bool {
+	return true
+}
+
+// Postgres は PostgreSQL 向けの Dialect です
+// pg_try_advisory_lock によるアドバイザリロックを使用します
+type Postgres struct{}
+
+// lockPollInterval はロックの取得を再試行する間隔です
+const lockPollInterval = 500 * time.Millisecond
+
+// Lock はアドバイザリロックを取得します
+// pg_advisory_lock は待ち時間を指定できないため、timeout まで取得を繰り返します
+func (Postgres) Lock(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var got bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", lockName).Scan(&got)
+		if err != nil {
+			return err
+		}
+		if got {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// Unlock はアドバイザリロックを解放します
+func (Postgres) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+	return err
+}
+
+// CreateTableSQL は schema_migrations テーブルを作成するSQLを返します
+func (Postgres) CreateTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  checksum CHAR(64) NOT NULL,
+  dirty BOOLEAN NOT NULL DEFAULT FALSE,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+}
+
+// TableExistsSQL は schema_migrations テーブルの有無を確認するSQLを返します
+func (Postgres) TableExistsSQL() string {
+	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'"
+}
+
+// Rebind は "?" プレースホルダーを "$1", "$2", ... に置き換えます
+// マイグレーション管理のクエリは文字列リテラルに "?" を含まないため、単純に置き換えます
+func (Postgres) Rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// TransactionalDDL は true を返します
+func (Postgres) TransactionalDDL() bool {
+	return true
+}
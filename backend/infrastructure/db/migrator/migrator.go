@@ -0,0 +1,381 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+const (
+	// lockName は複数のレプリカが同時にマイグレーションしないためのアドバイザリロック名です
+	lockName = "schema_migrations"
+	// defaultLockTimeout はロック取得を待つ最大時間です
+	defaultLockTimeout = 60 * time.Second
+)
+
+var (
+	ErrDirty            = errors.New("database is dirty; fix the failed migration and run force")
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+	ErrLockTimeout      = errors.New("timed out waiting for migration lock")
+	ErrNoDownMigration  = errors.New("migration has no down file")
+)
+
+// Status はマイグレーションの適用状況です
+type Status struct {
+	*Migration
+	Applied   bool
+	AppliedAt time.Time
+	Dirty     bool
+	Modified  bool
+}
+
+// appliedRecord は schema_migrations テーブルの1行です
+type appliedRecord struct {
+	checksum  string
+	dirty     bool
+	appliedAt time.Time
+}
+
+// Migrator はバージョン管理されたSQLマイグレーションを適用します
+type Migrator struct {
+	db          *sql.DB
+	dialect     Dialect
+	migrations  []*Migration
+	lockTimeout time.Duration
+}
+
+// New はMigratorを生成します
+// fsys には dialect のデータベース向けに書かれたマイグレーションファイルを指定します
+func New(db *sql.DB, fsys fs.FS, dialect Dialect) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:          db,
+		dialect:     dialect,
+		migrations:  migrations,
+		lockTimeout: defaultLockTimeout,
+	}, nil
+}
+
+// Up は未適用のマイグレーションをすべて適用し、適用した件数を返します
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down は適用済みのマイグレーションを新しい順に n 件取り消します
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			n--
+		}
+		return nil
+	})
+}
+
+// Redo は最後に適用したマイグレーションを取り消して再適用します
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			return m.apply(ctx, conn, mig)
+		}
+		return nil
+	})
+}
+
+// Force は指定したバージョンまでを適用済みとして記録し、dirty 状態を解除します
+// 失敗したマイグレーションを手動で修復した後に使用します
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, m.dialect.CreateTableSQL()); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, m.dialect.Rebind("DELETE FROM schema_migrations WHERE version > ?"), version); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, "UPDATE schema_migrations SET dirty = FALSE"); err != nil {
+			return err
+		}
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			query := "INSERT INTO schema_migrations (checksum, version, name, dirty) VALUES (?, ?, ?, FALSE)"
+			args := []interface{}{mig.Checksum, mig.Version, mig.Name}
+			if _, ok := applied[mig.Version]; ok {
+				query = "UPDATE schema_migrations SET checksum = ? WHERE version = ?"
+				args = args[:2]
+			}
+			if _, err := conn.ExecContext(ctx, m.dialect.Rebind(query), args...); err != nil {
+				return err
+			}
+		}
+		slog.Info("Forced schema version", "version", version)
+		return nil
+	})
+}
+
+// Status はすべてのマイグレーションの適用状況を返します
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, m.dialect.CreateTableSQL()); err != nil {
+		return nil, err
+	}
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if rec, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = rec.appliedAt
+			s.Dirty = rec.dirty
+			s.Modified = rec.checksum != mig.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// Pending は未適用のマイグレーション件数を返します
+// readiness の確認から頻繁に呼び出されるため、管理テーブルの作成は行わず読み取りのみで判定します
+// 管理テーブルがない場合は、すべてのマイグレーションを未適用とみなします
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	var exists int
+	if err := m.db.QueryRowContext(ctx, m.dialect.TableExistsSQL()).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists == 0 {
+		return len(m.migrations), nil
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, mig := range m.migrations {
+		if !applied[mig.Version] {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// apply はマイグレーションを適用して記録します
+// DDL をトランザクション内で実行できるデータベースでは、失敗した場合はロールバックして未適用のまま残します
+// それ以外のデータベースでは途中まで適用された可能性があるため dirty のまま残し、以降の実行を止めます
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig *Migration) error {
+	slog.Info("Applying migration", "version", mig.Version, "name", mig.Name)
+	if m.dialect.TransactionalDDL() {
+		return inTx(ctx, conn, func(tx *sql.Tx) error {
+			if err := execScript(ctx, tx, mig.Up); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			_, err := tx.ExecContext(ctx,
+				m.dialect.Rebind("INSERT INTO schema_migrations (version, name, checksum, dirty) VALUES (?, ?, ?, FALSE)"),
+				mig.Version, mig.Name, mig.Checksum)
+			return err
+		})
+	}
+
+	_, err := conn.ExecContext(ctx,
+		m.dialect.Rebind("INSERT INTO schema_migrations (version, name, checksum, dirty) VALUES (?, ?, ?, TRUE)"),
+		mig.Version, mig.Name, mig.Checksum)
+	if err != nil {
+		return err
+	}
+	if err := execScript(ctx, conn, mig.Up); err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+	}
+	_, err = conn.ExecContext(ctx, m.dialect.Rebind("UPDATE schema_migrations SET dirty = FALSE WHERE version = ?"), mig.Version)
+	return err
+}
+
+// revert はマイグレーションを取り消して記録を削除します
+// 失敗した場合の扱いは apply と同じです
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig *Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, mig.Version, mig.Name)
+	}
+	slog.Info("Reverting migration", "version", mig.Version, "name", mig.Name)
+	if m.dialect.TransactionalDDL() {
+		return inTx(ctx, conn, func(tx *sql.Tx) error {
+			if err := execScript(ctx, tx, mig.Down); err != nil {
+				return fmt.Errorf("revert %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			_, err := tx.ExecContext(ctx, m.dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version)
+			return err
+		})
+	}
+
+	if _, err := conn.ExecContext(ctx, m.dialect.Rebind("UPDATE schema_migrations SET dirty = TRUE WHERE version = ?"), mig.Version); err != nil {
+		return err
+	}
+	if err := execScript(ctx, conn, mig.Down); err != nil {
+		return fmt.Errorf("revert %d_%s failed: %w", mig.Version, mig.Name, err)
+	}
+	_, err := conn.ExecContext(ctx, m.dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version)
+	return err
+}
+
+// inTx は conn のトランザクションで fn を実行し、失敗した場合はロールバックします
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// verify は管理テーブルを用意し、dirty 状態と適用済みファイルの改変がないか確認します
+func (m *Migrator) verify(ctx context.Context, conn *sql.Conn) (map[int64]appliedRecord, error) {
+	if _, err := conn.ExecContext(ctx, m.dialect.CreateTableSQL()); err != nil {
+		return nil, err
+	}
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, mig := range m.migrations {
+		rec, ok := applied[mig.Version]
+		if !ok {
+			continue
+		}
+		if rec.dirty {
+			return nil, fmt.Errorf("%w: version %d", ErrDirty, mig.Version)
+		}
+		if rec.checksum != mig.Checksum {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, mig.Version, mig.Name)
+		}
+	}
+	return applied, nil
+}
+
+// withLock はアドバイザリロックを取得した専用の接続で処理を実行します
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.dialect.Lock(ctx, conn, m.lockTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		// 呼び出し元のコンテキストがキャンセルされていても解放する
+		_ = m.dialect.Unlock(context.Background(), conn)
+	}()
+
+	return fn(conn)
+}
+
+// loadApplied は適用済みのマイグレーションをバージョンごとに返します
+func loadApplied(ctx context.Context, conn *sql.Conn) (map[int64]appliedRecord, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, dirty, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedRecord)
+	for rows.Next() {
+		var version int64
+		var rec appliedRecord
+		if err := rows.Scan(&version, &rec.checksum, &rec.dirty, &rec.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = rec
+	}
+	return applied, rows.Err()
+}
+
+// execer は *sql.Conn と *sql.Tx に共通するSQLの実行です
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execScript はSQLファイルの各文を順に実行します
+func execScript(ctx context.Context, db execer, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package migrator_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"project_template/backend/infrastructure/bootstrap"
+	"project_template/backend/infrastructure/config"
+	"project_template/backend/infrastructure/db/migration"
+	"project_template/backend/infrastructure/db/migrator"
+)
+
+// openSQLite はテストごとの一時ファイルに空のデータベースを作成します
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := bootstrap.InitSQLite(context.Background(), &config.Config{SQLitePath: filepath.Join(t.TempDir(), "test.db")}, noop.NewTracerProvider())
+	if err != nil {
+		t.Fatalf("InitSQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newMigrator は fsys のマイグレーションを db に適用する Migrator を生成します
+func newMigrator(t *testing.T, db *sql.DB, fsys fstest.MapFS, dialect migrator.Dialect) *migrator.Migrator {
+	t.Helper()
+
+	m, err := migrator.New(db, fsys, dialect)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m
+}
+
+// file はマイグレーションファイルを返します
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+// threeTables は a, b, c の3つのテーブルを順に作成するマイグレーションです
+func threeTables() fstest.MapFS {
+	return fstest.MapFS{
+		"001_a.up.sql":   file("CREATE TABLE a (id INTEGER PRIMARY KEY);"),
+		"001_a.down.sql": file("DROP TABLE a;"),
+		"002_b.up.sql":   file("CREATE TABLE b (id INTEGER PRIMARY KEY);"),
+		"002_b.down.sql": file("DROP TABLE b;"),
+		"003_c.up.sql":   file("CREATE TABLE c (id INTEGER PRIMARY KEY);"),
+		"003_c.down.sql": file("DROP TABLE c;"),
+	}
+}
+
+// tables は存在するテーブルの有無を名前ごとに返します
+func tables(t *testing.T, db *sql.DB, names ...string) map[string]bool {
+	t.Helper()
+
+	got := make(map[string]bool, len(names))
+	for _, name := range names {
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n); err != nil {
+			t.Fatalf("count tables: %v", err)
+		}
+		got[name] = n > 0
+	}
+	return got
+}
+
+// appliedVersions は適用済みとして記録されたバージョンと dirty 状態を返します
+func appliedVersions(t *testing.T, m *migrator.Migrator) map[int64]bool {
+	t.Helper()
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	applied := make(map[int64]bool)
+	for _, s := range statuses {
+		if s.Applied {
+			applied[s.Version] = s.Dirty
+		}
+	}
+	return applied
+}
+
+// nonTransactional は DDL をトランザクション内で実行できないデータベース（MySQL）の扱いを SQLite で再現します
+type nonTransactional struct {
+	migrator.SQLite
+}
+
+func (nonTransactional) TransactionalDDL() bool { return false }
+
+func TestUpDownRedo(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m := newMigrator(t, db, threeTables(), migrator.SQLite{})
+
+	if n, err := m.Up(ctx); err != nil || n != 3 {
+		t.Fatalf("Up = (%d, %v), want (3, nil)", n, err)
+	}
+	if got := tables(t, db, "a", "b", "c"); !got["a"] || !got["b"] || !got["c"] {
+		t.Fatalf("tables after Up = %v, want all", got)
+	}
+	// 適用済みのマイグレーションは再適用しない
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Errorf("Up(again) = (%d, %v), want (0, nil)", n, err)
+	}
+
+	if err := m.Down(ctx, 1); err != nil {
+		t.Fatalf("Down(1): %v", err)
+	}
+	if got := tables(t, db, "b", "c"); !got["b"] || got["c"] {
+		t.Errorf("tables after Down(1) = %v, want b without c", got)
+	}
+
+	// 最後に適用した b を取り消して再適用する（b のデータは消える）
+	if _, err := db.Exec("INSERT INTO b (id) VALUES (1)"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if err := m.Redo(ctx); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	var rows int
+	if err := db.QueryRow("SELECT COUNT(*) FROM b").Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("rows in b after Redo = (%d, %v), want (0, nil)", rows, err)
+	}
+	if got := appliedVersions(t, m); len(got) != 2 || got[1] || got[2] {
+		t.Errorf("applied after Redo = %v, want 1 and 2 clean", got)
+	}
+
+	// 件数が適用済みより多い場合はすべて取り消す
+	if err := m.Down(ctx, 10); err != nil {
+		t.Fatalf("Down(10): %v", err)
+	}
+	if got := tables(t, db, "a", "b", "c"); got["a"] || got["b"] || got["c"] {
+		t.Errorf("tables after Down(10) = %v, want none", got)
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Errorf("applied after Down(10) = %v, want none", got)
+	}
+}
+
+func TestEmbeddedMigrationsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m, err := migrator.New(db, migration.SQLite, migrator.SQLite{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	// すべての down ファイルが up ファイルを正しく取り消せること
+	if n, err := m.Up(ctx); err != nil || n != len(statuses) {
+		t.Fatalf("Up = (%d, %v), want (%d, nil)", n, err, len(statuses))
+	}
+	if err := m.Down(ctx, len(statuses)); err != nil {
+		t.Fatalf("Down(all): %v", err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != len(statuses) {
+		t.Fatalf("Up(after Down) = (%d, %v), want (%d, nil)", n, err, len(statuses))
+	}
+}
+
+func TestDownWithoutDownFile(t *testing.T) {
+	ctx := context.Background()
+	m := newMigrator(t, openSQLite(t), fstest.MapFS{"001_a.up.sql": file("CREATE TABLE a (id INTEGER);")}, migrator.SQLite{})
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := m.Down(ctx, 1); !errors.Is(err, migrator.ErrNoDownMigration) {
+		t.Errorf("Down = %v, want ErrNoDownMigration", err)
+	}
+	if err := m.Redo(ctx); !errors.Is(err, migrator.ErrNoDownMigration) {
+		t.Errorf("Redo = %v, want ErrNoDownMigration", err)
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	if _, err := newMigrator(t, db, threeTables(), migrator.SQLite{}).Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	// 適用済みのファイルを書き換えた場合は、以降の操作を拒否する
+	modified := threeTables()
+	modified["002_b.up.sql"] = file("CREATE TABLE b (id INTEGER PRIMARY KEY, name TEXT);")
+	modified["004_d.up.sql"] = file("CREATE TABLE d (id INTEGER PRIMARY KEY);")
+	m := newMigrator(t, db, modified, migrator.SQLite{})
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, s := range statuses {
+		if want := s.Version == 2; s.Modified != want {
+			t.Errorf("Status(%d).Modified = %v, want %v", s.Version, s.Modified, want)
+		}
+	}
+	if _, err := m.Up(ctx); !errors.Is(err, migrator.ErrChecksumMismatch) {
+		t.Errorf("Up = %v, want ErrChecksumMismatch", err)
+	}
+	if err := m.Down(ctx, 1); !errors.Is(err, migrator.ErrChecksumMismatch) {
+		t.Errorf("Down = %v, want ErrChecksumMismatch", err)
+	}
+	if got := tables(t, db, "c", "d"); !got["c"] || got["d"] {
+		t.Errorf("tables = %v, want c kept and d not created", got)
+	}
+
+	// Force で現在のファイルのチェックサムを記録し直すと再開できる
+	if err := m.Force(ctx, 3); err != nil {
+		t.Fatalf("Force: %v", err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 1 {
+		t.Errorf("Up(after Force) = (%d, %v), want (1, nil)", n, err)
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	fsys := threeTables()
+	fsys["002_b.up.sql"] = file("CREATE TABLE b (id INTEGER PRIMARY KEY);\nINSERT INTO missing (id) VALUES (1);")
+	m := newMigrator(t, db, fsys, migrator.SQLite{})
+
+	// トランザクション内で失敗したマイグレーションは記録も DDL も残さない
+	if n, err := m.Up(ctx); err == nil || n != 1 {
+		t.Fatalf("Up = (%d, %v), want (1, error)", n, err)
+	}
+	if got := tables(t, db, "a", "b", "c"); !got["a"] || got["b"] || got["c"] {
+		t.Errorf("tables = %v, want only a", got)
+	}
+	if got := appliedVersions(t, m); len(got) != 1 || got[1] {
+		t.Errorf("applied = %v, want only 1 (clean)", got)
+	}
+
+	// ファイルを修正すれば Force なしで再実行できる
+	if n, err := newMigrator(t, db, threeTables(), migrator.SQLite{}).Up(ctx); err != nil || n != 2 {
+		t.Errorf("Up(fixed) = (%d, %v), want (2, nil)", n, err)
+	}
+}
+
+func TestDirtyStateRefusal(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	fsys := threeTables()
+	fsys["002_b.up.sql"] = file("CREATE TABLE b (id INTEGER PRIMARY KEY);\nINSERT INTO missing (id) VALUES (1);")
+	m := newMigrator(t, db, fsys, nonTransactional{})
+
+	// トランザクションを使用できない場合は途中まで適用された可能性があるため dirty として残す
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("Up = nil, want error")
+	}
+	if got := appliedVersions(t, m); len(got) != 2 || got[1] || !got[2] {
+		t.Fatalf("applied = %v, want 1 clean and 2 dirty", got)
+	}
+
+	fixed := threeTables()
+	fixed["002_b.up.sql"] = file("CREATE TABLE IF NOT EXISTS b (id INTEGER PRIMARY KEY);")
+	m = newMigrator(t, db, fixed, nonTransactional{})
+	if _, err := m.Up(ctx); !errors.Is(err, migrator.ErrDirty) {
+		t.Errorf("Up(dirty) = %v, want ErrDirty", err)
+	}
+	if err := m.Down(ctx, 1); !errors.Is(err, migrator.ErrDirty) {
+		t.Errorf("Down(dirty) = %v, want ErrDirty", err)
+	}
+	if err := m.Redo(ctx); !errors.Is(err, migrator.ErrDirty) {
+		t.Errorf("Redo(dirty) = %v, want ErrDirty", err)
+	}
+
+	// 手動で修復した後、失敗する前のバージョンを Force で記録すると再開できる
+	if err := m.Force(ctx, 1); err != nil {
+		t.Fatalf("Force: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 1 || got[1] {
+		t.Errorf("applied after Force(1) = %v, want only 1 (clean)", got)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 2 {
+		t.Errorf("Up(after Force) = (%d, %v), want (2, nil)", n, err)
+	}
+}
+
+func TestForceRecordsWithoutRunning(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m := newMigrator(t, db, threeTables(), migrator.SQLite{})
+
+	// 指定したバージョンまでは実行せずに適用済みとして記録する
+	if err := m.Force(ctx, 2); err != nil {
+		t.Fatalf("Force: %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 2 || got[1] || got[2] {
+		t.Errorf("applied after Force(2) = %v, want 1 and 2 clean", got)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 1 {
+		t.Fatalf("Up = (%d, %v), want (1, nil)", n, err)
+	}
+	if got := tables(t, db, "a", "b", "c"); got["a"] || got["b"] || !got["c"] {
+		t.Errorf("tables = %v, want only c", got)
+	}
+
+	// 指定したバージョンより新しい記録は削除する
+	if err := m.Force(ctx, 0); err != nil {
+		t.Fatalf("Force(0): %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Errorf("applied after Force(0) = %v, want none", got)
+	}
+}
+
+func TestPendingIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m, err := migrator.New(db, migration.SQLite, migrator.SQLite{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	total := len(statuses)
+	if _, err := db.ExecContext(ctx, "DROP TABLE schema_migrations"); err != nil {
+		t.Fatalf("DROP TABLE: %v", err)
+	}
+
+	// 管理テーブルがない場合はすべて未適用とみなし、テーブルは作成しない
+	pending, err := m.Pending(ctx)
+	if err != nil || pending != total {
+		t.Fatalf("Pending(no table) = (%d, %v), want (%d, nil)", pending, err, total)
+	}
+	var tables int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tables); err != nil {
+		t.Fatalf("count tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("Pending created schema_migrations")
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if pending, err := m.Pending(ctx); err != nil || pending != 0 {
+		t.Errorf("Pending(after Up) = (%d, %v), want (0, nil)", pending, err)
+	}
+	if err := m.Down(ctx, 1); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if pending, err := m.Pending(ctx); err != nil || pending != 1 {
+		t.Errorf("Pending(after Down) = (%d, %v), want (1, nil)", pending, err)
+	}
+}
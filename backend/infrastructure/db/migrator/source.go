@@ -0,0 +1,107 @@
+package migrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fileNamePattern はマイグレーションファイル名の形式です
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration は1つのバージョンのマイグレーションです
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Load はファイルシステムからマイグレーションを読み込み、バージョン順に並べて返します
+// up ファイルのないバージョンや重複したバージョンはエラーとします
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := fileNamePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse version of %s: %w", e.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", e.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		sum := sha256.Sum256([]byte(mig.Up))
+		mig.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// splitStatements はSQLファイルを文単位に分割します
+// 行末の ";" を文の区切りとみなし、"--" で始まるコメント行は除外します
+// PostgreSQL の関数定義など "$$" で囲まれた範囲の中では分割しません
+func splitStatements(script string) []string {
+	var stmts []string
+	var buf strings.Builder
+	inDollarQuote := false
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !inDollarQuote && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if strings.Count(line, "$$")%2 == 1 {
+			inDollarQuote = !inDollarQuote
+		}
+		if !inDollarQuote && strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(buf.String()))
+			buf.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(buf.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"project_template/backend/infrastructure/db/migrator"
+)
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// DBChecker はデータベースへの疎通を確認します
+type DBChecker struct {
+	db *sql.DB
+}
+
+// NewDBChecker はDBCheckerを生成します
+func NewDBChecker(db *sql.DB) *DBChecker {
+	return &DBChecker{db: db}
+}
+
+// Name はチェック対象の名前を返します
+func (c *DBChecker) Name() string {
+	return "database"
+}
+
+// Check はデータベースにpingします
+func (c *DBChecker) Check(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// MigrationChecker は未適用のマイグレーションがないことを確認します
+type MigrationChecker struct {
+	migrator *migrator.Migrator
+}
+
+// NewMigrationChecker はMigrationCheckerを生成します
+func NewMigrationChecker(m *migrator.Migrator) *MigrationChecker {
+	return &MigrationChecker{migrator: m}
+}
+
+// Name はチェック対象の名前を返します
+func (c *MigrationChecker) Name() string {
+	return "migrations"
+}
+
+// Check は未適用のマイグレーションがある場合にエラーを返します
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	pending, err := c.migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d pending migration(s)", pending)
+	}
+	return nil
+}
+
+// ShutdownChecker はサーバーが停止処理中でないことを確認します
+type ShutdownChecker struct {
+	ready func() bool
+}
+
+// NewShutdownChecker はShutdownCheckerを生成します
+// ready には server.Server.Ready を渡します
+func NewShutdownChecker(ready func() bool) *ShutdownChecker {
+	return &ShutdownChecker{ready: ready}
+}
+
+// Name はチェック対象の名前を返します
+func (c *ShutdownChecker) Name() string {
+	return "shutdown"
+}
+
+// Check はサーバーが受け付け可能でない場合にエラーを返します
+func (c *ShutdownChecker) Check(ctx context.Context) error {
+	if !c.ready() {
+		return ErrShuttingDown
+	}
+	return nil
+}
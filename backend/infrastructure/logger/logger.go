@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// ctxKey はコンテキストにロガーを格納するためのキーです
+type ctxKey struct{}
+
+// New は形式とレベルを指定してロガーを生成します
+// format には "json" または "text" を、level には "debug", "info", "warn", "error" を指定します
+// 出力される属性は自動的にマスキングされます
+// slog.InfoContext などに渡したコンテキストにリクエストスコープのロガーが格納されている場合は、
+// そのロガーの属性（リクエストIDや認証したユーザーIDなど）を付与して出力します
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lv slog.Level
+	if err := lv.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       lv,
+		ReplaceAttr: redactAttr,
+	}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+	return slog.New(&contextHandler{Handler: h}), nil
+}
+
+// contextHandler はコンテキストに格納されたリクエストスコープのロガーに出力を委譲するハンドラーです
+// インフラストラクチャ層に依存しないユースケースでも slog.*Context でリクエストの属性を含めたログを出力できるようにします
+type contextHandler struct {
+	slog.Handler
+	// derived は属性やグループを追加したハンドラーかを表します
+	// 追加した属性を失わないよう、派生したハンドラーは委譲せずにそのまま出力します
+	derived bool
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.derived {
+		if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok && l.Handler() != h {
+			if !l.Handler().Enabled(ctx, r.Level) {
+				return nil
+			}
+			return l.Handler().Handle(ctx, r)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), derived: true}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), derived: true}
+}
+
+// WithContext はロガーを格納したコンテキストを返します
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext はコンテキストに格納されたリクエストスコープのロガーを返します
+// 格納されていない場合はデフォルトのロガーを返します
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// With はコンテキストのロガーに属性を追加し、新しいコンテキストを返します
+// 認証後のユーザーIDなど、処理の途中で判明した情報を以降のログに含める場合に使用します
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
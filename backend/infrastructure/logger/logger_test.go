@@ -0,0 +1,47 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"project_template/backend/infrastructure/logger"
+)
+
+func TestContextAttributesWithoutRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base, err := logger.New(&buf, "json", "info")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := logger.WithContext(context.Background(), base.With("request_id", "req-1"))
+	ctx = logger.With(ctx, "user_id", "user-1")
+
+	// コンテキストのロガーを取り出さずに slog.*Context で出力してもリクエストの属性が付与される
+	slog.New(base.Handler()).WarnContext(ctx, "from usecase", "key", "value")
+	// 属性を追加したロガーは委譲せず、追加した属性で出力する
+	base.With("component", "worker").InfoContext(ctx, "from worker")
+	// レベルを満たさないログは出力しない
+	base.DebugContext(ctx, "debug")
+
+	dec := json.NewDecoder(&buf)
+	var records []map[string]any
+	for dec.More() {
+		var rec map[string]any
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %s", len(records), buf.String())
+	}
+	if r := records[0]; r["request_id"] != "req-1" || r["user_id"] != "user-1" || r["key"] != "value" {
+		t.Errorf("usecase record = %v, want request_id, user_id and key", r)
+	}
+	if r := records[1]; r["component"] != "worker" || r["request_id"] != nil {
+		t.Errorf("worker record = %v, want component only", r)
+	}
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretKeys は値をすべて伏せる属性名です（大文字小文字を区別しない部分一致）
+var secretKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"authorization",
+	"cookie",
+	"api_key",
+	"apikey",
+	"dsn",
+}
+
+// emailPattern はログに含まれるメールアドレスを検出します
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// redactAttr は秘匿情報と個人情報をログ出力前にマスキングします
+// 秘匿情報の属性は値全体を伏せ、それ以外の文字列やエラーに含まれるメールアドレスはドメインのみ残します
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+
+	key := strings.ToLower(a.Key)
+	for _, k := range secretKeys {
+		if strings.Contains(key, k) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, MaskEmails(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, MaskEmails(err.Error()))
+		}
+	}
+	return a
+}
+
+// MaskEmails は文字列中のメールアドレスのローカル部を伏せます
+func MaskEmails(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	return emailPattern.ReplaceAllString(s, "***@$1")
+}
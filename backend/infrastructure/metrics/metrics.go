@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace はアプリケーション固有のメトリクスに付与する接頭辞です
+const namespace = "app"
+
+// Metrics はアプリケーションのメトリクスを保持します
+// HTTP・DB接続プール・ドメインのメトリクスを1つのレジストリで管理します
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	usersCreated   prometheus.Counter
+	emailConflicts prometheus.Counter
+}
+
+// New はMetricsを生成し、プロセスとGoランタイムのメトリクスを登録します
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by route template and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		usersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_created_total",
+			Help:      "Total number of users created.",
+		}),
+		emailConflicts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "user_email_conflicts_total",
+			Help:      "Total number of user writes rejected because the email already exists.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+		m.httpRequests,
+		m.httpDuration,
+		m.usersCreated,
+		m.emailConflicts,
+	)
+	return m
+}
+
+// RegisterDB はDB接続プールの統計（sql.DBStats）を公開します
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler はPrometheusのテキスト形式でメトリクスを返すハンドラーです
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest はHTTPリクエストの件数と処理時間を記録します
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// UserCreated はユーザーの作成件数を記録します
+func (m *Metrics) UserCreated() {
+	m.usersCreated.Inc()
+}
+
+// EmailConflict はメールアドレスの重複による失敗件数を記録します
+func (m *Metrics) EmailConflict() {
+	m.emailConflicts.Inc()
+}
@@ -0,0 +1,75 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"project_template/backend/adapter/middleware"
+	"project_template/backend/infrastructure/metrics"
+)
+
+func TestScrapeHTTPMetrics(t *testing.T) {
+	m := metrics.New()
+
+	// API のルーターと同様に、ルートテンプレートごとに記録する
+	router := mux.NewRouter()
+	router.Use(middleware.Metrics(m))
+	router.HandleFunc("/api/v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["id"] == "missing" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "ok")
+	}).Methods(http.MethodGet)
+	api := httptest.NewServer(router)
+	defer api.Close()
+
+	// 管理用ポートと同様に /metrics で公開する
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", m.Handler())
+	admin := httptest.NewServer(adminMux)
+	defer admin.Close()
+
+	for _, id := range []string{"1", "2", "missing"} {
+		resp, err := http.Get(api.URL + "/api/v1/users/" + id)
+		if err != nil {
+			t.Fatalf("GET /api/v1/users/%s: %v", id, err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(admin.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d, want 200", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read /metrics: %v", err)
+	}
+	text := string(body)
+
+	// パスではなくルートテンプレートをラベルにし、ステータスごとに集計する
+	for _, want := range []string{
+		`app_http_requests_total{method="GET",route="/api/v1/users/{id}",status="200"} 2`,
+		`app_http_requests_total{method="GET",route="/api/v1/users/{id}",status="404"} 1`,
+		`app_http_request_duration_seconds_count{method="GET",route="/api/v1/users/{id}",status="200"} 2`,
+		`app_http_request_duration_seconds_bucket{method="GET",route="/api/v1/users/{id}",status="200",le="+Inf"} 2`,
+		`go_goroutines `,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("/metrics does not contain %q", want)
+		}
+	}
+	if strings.Contains(text, `route="/api/v1/users/1"`) {
+		t.Error("/metrics uses the raw path as a label")
+	}
+}
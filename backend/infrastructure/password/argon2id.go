@@ -0,0 +1,115 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrInvalidHash はハッシュが Argon2id の PHC 文字列形式として解釈できない場合のエラーです
+var ErrInvalidHash = errors.New("invalid argon2id hash")
+
+// Params は Argon2id のパラメータです
+type Params struct {
+	// Memory は使用するメモリ量（KiB）です
+	Memory uint32
+	// Iterations は反復回数です
+	Iterations uint32
+	// Parallelism は並列度です
+	Parallelism uint8
+	// SaltLength と KeyLength はソルトとハッシュの長さ（バイト）です
+	SaltLength uint32
+	KeyLength  uint32
+}
+
+// Argon2id は Argon2id によるパスワードのハッシュ化と検証を行います
+//
+// ハッシュは "$argon2id$v=19$m=65536,t=3,p=2$<ソルト>$<ハッシュ>" の PHC 文字列形式で、
+// 検証にはハッシュに含まれるパラメータを使用するため、パラメータを変更しても既存のハッシュを検証できます
+type Argon2id struct {
+	params Params
+}
+
+// NewArgon2id はArgon2idを生成します
+func NewArgon2id(params Params) *Argon2id {
+	return &Argon2id{
+		params: params,
+	}
+}
+
+// Hash はパスワードをランダムなソルトとともにハッシュ化します
+func (h *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return encode(h.params, salt, key), nil
+}
+
+// Verify はパスワードがハッシュと一致するか判定します
+func (h *Argon2id) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash はハッシュのパラメータが現在の設定と異なり、ハッシュ化し直す必要があるか判定します
+func (h *Argon2id) NeedsRehash(encoded string) bool {
+	params, _, _, err := decode(encoded)
+	return err != nil || params != h.params
+}
+
+// encode はパラメータ・ソルト・ハッシュを PHC 文字列形式に変換します
+func encode(params Params, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+// decode は PHC 文字列形式のハッシュからパラメータ・ソルト・ハッシュを取り出します
+func decode(encoded string) (Params, []byte, []byte, error) {
+	var params Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHash, version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
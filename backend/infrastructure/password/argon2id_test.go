@@ -0,0 +1,112 @@
+package password_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"project_template/backend/infrastructure/password"
+)
+
+// testParams はテストを速くするため最小限のコストにしたパラメータです
+var testParams = password.Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashAndVerify(t *testing.T) {
+	h := password.NewArgon2id(testParams)
+	hash, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Hash = %q, want PHC string with the configured params", hash)
+	}
+
+	// 同じパスワードでもソルトが異なるため別のハッシュになる
+	if again, _ := h.Hash("correct horse battery staple"); again == hash {
+		t.Error("Hash returned the same string twice, want a random salt")
+	}
+
+	if ok, err := h.Verify("correct horse battery staple", hash); err != nil || !ok {
+		t.Errorf("Verify(correct) = (%v, %v), want (true, nil)", ok, err)
+	}
+	if ok, err := h.Verify("wrong password", hash); err != nil || ok {
+		t.Errorf("Verify(wrong) = (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestVerifyMalformedHash(t *testing.T) {
+	h := password.NewArgon2id(testParams)
+	valid, err := h.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	parts := strings.Split(valid, "$")
+	with := func(i int, v string) string {
+		p := append([]string(nil), parts...)
+		p[i] = v
+		return strings.Join(p, "$")
+	}
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "empty", hash: ""},
+		{name: "argon2i", hash: with(1, "argon2i")},
+		{name: "bcrypt", hash: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"},
+		{name: "unsupported version", hash: with(2, "v=16")},
+		{name: "missing version", hash: with(2, "")},
+		{name: "missing params", hash: with(3, "m=64,t=1")},
+		{name: "non-numeric params", hash: with(3, "m=x,t=1,p=1")},
+		{name: "bad salt base64", hash: with(4, "not*base64")},
+		{name: "bad key base64", hash: with(5, "not*base64")},
+		{name: "empty key", hash: with(5, "")},
+		{name: "missing segment", hash: strings.Join(parts[:5], "$")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := h.Verify("password", tt.hash)
+			if ok || !errors.Is(err, password.ErrInvalidHash) {
+				t.Errorf("Verify = (%v, %v), want (false, ErrInvalidHash)", ok, err)
+			}
+			// 解釈できないハッシュはハッシュ化し直す
+			if !h.NeedsRehash(tt.hash) {
+				t.Error("NeedsRehash = false, want true")
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	hash, err := password.NewArgon2id(testParams).Hash("password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*password.Params)
+		want   bool
+	}{
+		{name: "same params", change: func(p *password.Params) {}, want: false},
+		{name: "memory", change: func(p *password.Params) { p.Memory = 128 }, want: true},
+		{name: "iterations", change: func(p *password.Params) { p.Iterations = 2 }, want: true},
+		{name: "parallelism", change: func(p *password.Params) { p.Parallelism = 2 }, want: true},
+		{name: "salt length", change: func(p *password.Params) { p.SaltLength = 32 }, want: true},
+		{name: "key length", change: func(p *password.Params) { p.KeyLength = 64 }, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams
+			tt.change(&params)
+			h := password.NewArgon2id(params)
+			if got := h.NeedsRehash(hash); got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+			// パラメータを変更しても既存のハッシュは検証できる
+			if ok, err := h.Verify("password", hash); err != nil || !ok {
+				t.Errorf("Verify = (%v, %v), want (true, nil)", ok, err)
+			}
+		})
+	}
+}
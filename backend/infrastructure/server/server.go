@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"project_template/backend/infrastructure/config"
+)
+
+// Worker はサーバーと同じライフサイクルで動作するバックグラウンド処理です
+// ctx がキャンセルされたら速やかに終了する必要があります
+type Worker func(ctx context.Context) error
+
+// Server はHTTPサーバーとバックグラウンド処理の起動・停止を管理します
+type Server struct {
+	httpServer      *http.Server
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+
+	ready        atomic.Bool
+	shuttingDown atomic.Bool
+
+	workers       map[string]Worker
+	shutdownHooks []func(ctx context.Context) error
+}
+
+// New は設定からServerを生成します
+func New(cfg *config.Config) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              fmt.Sprintf(":%s", cfg.ServerPort),
+			ReadTimeout:       cfg.ServerReadTimeout,
+			ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+			WriteTimeout:      cfg.ServerWriteTimeout,
+			IdleTimeout:       cfg.ServerIdleTimeout,
+		},
+		shutdownDelay:   cfg.ShutdownDelay,
+		shutdownTimeout: cfg.ShutdownTimeout,
+		workers:         make(map[string]Worker),
+	}
+}
+
+// Ready はリクエストを受け付け可能な状態かを返します
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// ShuttingDown は停止処理中かを返します
+func (s *Server) ShuttingDown() bool {
+	return s.shuttingDown.Load()
+}
+
+// AddWorker はバックグラウンド処理を登録します
+func (s *Server) AddWorker(name string, w Worker) {
+	s.workers[name] = w
+}
+
+// OnShutdown はHTTPサーバーとバックグラウンド処理の停止後に呼び出す処理を登録します
+// DB接続のクローズなどを登録し、登録と逆の順序で実行されます
+func (s *Server) OnShutdown(fn func(ctx context.Context) error) {
+	s.shutdownHooks = append(s.shutdownHooks, fn)
+}
+
+// Run はサーバーを起動し、ctx がキャンセルされるまでリクエストを処理します
+// 停止時は readiness を失敗させ、処理中のリクエストとバックグラウンド処理の完了を待ってから
+// 登録された停止処理を実行します
+// ポートを確保できず起動しなかった場合も、登録された停止処理を実行してから返します
+func (s *Server) Run(ctx context.Context, handler http.Handler) error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		hookCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		return errors.Join(append([]error{err}, s.runHooks(hookCtx)...)...)
+	}
+	return s.Serve(ctx, ln, handler)
+}
+
+// Serve は指定したリスナーでサーバーを起動します
+func (s *Server) Serve(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	s.httpServer.Handler = handler
+
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	var wg sync.WaitGroup
+	for name, w := range s.workers {
+		wg.Add(1)
+		go func(name string, w Worker) {
+			defer wg.Done()
+			if err := w(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+				slog.Error("Worker stopped with error", "worker", name, "error", err)
+			}
+		}(name, w)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.Serve(ln)
+	}()
+	s.ready.Store(true)
+	slog.Info("Server starting", "addr", ln.Addr().String())
+
+	var runErr error
+	select {
+	case err := <-serveErr:
+		// 起動に失敗した場合も後続の停止処理を実行する
+		runErr = err
+	case <-ctx.Done():
+		slog.Info("Shutdown signal received")
+	}
+
+	runErr = errors.Join(runErr, s.shutdown(cancelWorkers, &wg))
+	return runErr
+}
+
+// AdminWorker は管理用エンドポイントを別のリスナーで提供する Worker を返します
+// メトリクスなど公開しないエンドポイントを、APIとは異なるポートで提供するために使用します
+func AdminWorker(addr string, handler http.Handler) Worker {
+	return func(ctx context.Context) error {
+		srv := &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		errCh := make(chan error, 1)
+		go func() {
+			slog.Info("Admin server starting", "addr", addr)
+			errCh <- srv.ListenAndServe()
+		}()
+
+		select {
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		}
+	}
+}
+
+// IntervalWorker は interval ごとに fn を実行する Worker を返します
+// fn がエラーを返しても終了せず、ログに記録して次の実行を待ちます
+func IntervalWorker(interval time.Duration, fn func(ctx context.Context) error) Worker {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := fn(ctx); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Interval worker run failed", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// shutdown は停止処理を順に実行します
+func (s *Server) shutdown(cancelWorkers context.CancelFunc, wg *sync.WaitGroup) error {
+	s.ready.Store(false)
+	s.shuttingDown.Store(true)
+
+	// ロードバランサーが readiness の失敗を検知するまで待つ
+	if s.shutdownDelay > 0 {
+		time.Sleep(s.shutdownDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+
+	cancelWorkers()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, errors.New("timed out waiting for workers to stop"))
+	}
+
+	errs = append(errs, s.runHooks(ctx)...)
+
+	slog.Info("Server stopped")
+	return errors.Join(errs...)
+}
+
+// runHooks は登録された停止処理を登録と逆の順序で実行し、失敗したもののエラーを返します
+func (s *Server) runHooks(ctx context.Context) []error {
+	var errs []error
+	for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+		if err := s.shutdownHooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,216 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"project_template/backend/infrastructure/config"
+	"project_template/backend/infrastructure/server"
+)
+
+// eventLog は停止処理の各段階が実行された順序を記録します
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.events, ",")
+}
+
+// startServer は空きポートでサーバーを起動し、ベースURLと Serve の結果を受け取るチャネルを返します
+func startServer(ctx context.Context, t *testing.T, srv *server.Server, handler http.Handler) (string, <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx, ln, handler)
+	}()
+	return "http://" + ln.Addr().String(), done
+}
+
+func TestShutdownDrainsRequestsBeforeHooks(t *testing.T) {
+	log := &eventLog{}
+	srv := server.New(&config.Config{ShutdownTimeout: 5 * time.Second})
+	srv.AddWorker("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		log.add("worker stopped")
+		return nil
+	})
+	// 停止処理は登録と逆の順序で実行する
+	srv.OnShutdown(func(ctx context.Context) error {
+		log.add("hook 1")
+		return nil
+	})
+	srv.OnShutdown(func(ctx context.Context) error {
+		log.add("hook 2")
+		return nil
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		log.add("request done")
+		io.WriteString(w, "ok")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	url, done := startServer(ctx, t, srv, handler)
+
+	respErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			defer resp.Body.Close()
+			if body, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(body) != "ok" {
+				err = errors.New("unexpected response: " + resp.Status + " " + string(body))
+			}
+		}
+		respErr <- err
+	}()
+	<-started
+	if !srv.Ready() {
+		t.Error("Ready() = false while serving, want true")
+	}
+
+	// 停止の開始後は readiness を失敗させ、処理中のリクエストの完了を待つ
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for !srv.ShuttingDown() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if srv.Ready() || !srv.ShuttingDown() {
+		t.Fatalf("Ready() = %v, ShuttingDown() = %v after cancel, want false and true", srv.Ready(), srv.ShuttingDown())
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Serve returned %v before the in-flight request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-respErr; err != nil {
+		t.Errorf("in-flight request: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Serve = %v, want nil", err)
+	}
+	if got, want := log.String(), "request done,worker stopped,hook 2,hook 1"; got != want {
+		t.Errorf("shutdown sequence = %q, want %q", got, want)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	log := &eventLog{}
+	srv := server.New(&config.Config{ShutdownTimeout: 100 * time.Millisecond})
+	srv.OnShutdown(func(ctx context.Context) error {
+		// 期限を過ぎた後も停止処理は実行する
+		if ctx.Err() == nil {
+			t.Error("hook ctx is not done after the shutdown timeout")
+		}
+		log.add("hook")
+		return nil
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	url, done := startServer(ctx, t, srv, handler)
+	go func() {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	begin := time.Now()
+	cancel()
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after the shutdown timeout")
+	}
+	// 終わらないリクエストは待たずに期限で打ち切る
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Errorf("shutdown took %v, want about 100ms", elapsed)
+	}
+	if got := log.String(); got != "hook" {
+		t.Errorf("hooks run = %q, want %q", got, "hook")
+	}
+}
+
+func TestShutdownJoinsHookErrors(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	srv := server.New(&config.Config{ShutdownTimeout: time.Second})
+	srv.OnShutdown(func(ctx context.Context) error { return errFirst })
+	srv.OnShutdown(func(ctx context.Context) error { return errSecond })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, done := startServer(ctx, t, srv, http.NotFoundHandler())
+	cancel()
+
+	// 失敗した停止処理があっても残りの停止処理を実行し、すべてのエラーを返す
+	err := <-done
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Errorf("Serve = %v, want both hook errors", err)
+	}
+}
+
+func TestRunListenFailureRunsHooks(t *testing.T) {
+	// 使用中のポートを指定して起動に失敗させる
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	log := &eventLog{}
+	srv := server.New(&config.Config{ServerPort: port, ShutdownTimeout: time.Second})
+	srv.OnShutdown(func(ctx context.Context) error {
+		log.add("hook")
+		return nil
+	})
+
+	// 起動しなかった場合も登録された停止処理（DB接続のクローズなど）を実行する
+	if err := srv.Run(context.Background(), http.NotFoundHandler()); err == nil {
+		t.Fatal("Run = nil, want listen error")
+	}
+	if got := log.String(); got != "hook" {
+		t.Errorf("hooks run = %q, want %q", got, "hook")
+	}
+}
@@ -0,0 +1,114 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+)
+
+// leeway は有効期限などの検証で許容する時刻のずれです
+const leeway = 30 * time.Second
+
+// claims はアクセストークンのクレームです
+// Role は発行時点のユーザーのロールで、ロールの変更はトークンの再発行まで反映されません
+type claims struct {
+	jwt.RegisteredClaims
+	Role entity.Role `json:"role"`
+}
+
+// Issuer は JWT 形式のアクセストークンを発行・検証します
+type Issuer struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	ttl      time.Duration
+	parser   *jwt.Parser
+}
+
+// NewIssuer はIssuerを生成します
+// issuer と audience はトークンの iss と aud クレームで、検証時にも一致を確認します
+func NewIssuer(keys *KeySet, issuer, audience string, ttl time.Duration) *Issuer {
+	return &Issuer{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		ttl:      ttl,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}),
+			jwt.WithIssuer(issuer),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+			jwt.WithLeeway(leeway),
+		),
+	}
+}
+
+// Issue はユーザーのアクセストークンを発行し、トークンと有効期限を返します
+func (i *Issuer) Issue(ctx context.Context, userID string, role entity.Role) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+	c := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    i.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{i.audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Role: role,
+	}
+
+	key := i.keys.signer()
+	t := jwt.NewWithClaims(key.method, c)
+	t.Header["kid"] = key.kid
+	signed, err := t.SignedString(key.key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify はアクセストークンの署名とクレームを検証し、認証された主体を返します
+func (i *Issuer) Verify(ctx context.Context, token string) (*auth.Principal, error) {
+	var c claims
+	_, err := i.parser.ParseWithClaims(token, &c, i.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if c.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	if !c.Role.Valid() {
+		return nil, fmt.Errorf("token has invalid role %q", c.Role)
+	}
+	return &auth.Principal{UserID: c.Subject, Role: c.Role}, nil
+}
+
+// keyFunc はトークンのヘッダーの鍵IDから検証に使用する公開鍵を返します
+// 鍵と異なる署名方式のトークンは受け付けません
+func (i *Issuer) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := i.keys.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}
+	return key.key.Public(), nil
+}
+
+// JWKS は検証に使用する公開鍵の一覧を JWK Set の JSON で返します
+func (i *Issuer) JWKS() []byte {
+	return i.keys.JWKS()
+}
@@ -0,0 +1,196 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits は署名に使用できる RSA 鍵の最小の長さです
+const minRSAKeyBits = 2048
+
+// signingKey は1つの署名鍵と、その公開鍵の JWK です
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+	jwk    jwk
+}
+
+// jwk は JWKS で公開する公開鍵の表現です（RFC 7517）
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// KeySet はアクセストークンの署名と検証に使用する鍵の集合です
+//
+// 先頭の鍵で署名し、すべての鍵で検証します
+// 鍵をローテーションする場合は、新しい鍵を先頭に追加し、古い鍵で署名したトークンの有効期限が切れた後に古い鍵を取り除きます
+type KeySet struct {
+	keys []*signingKey
+	byID map[string]*signingKey
+	jwks []byte
+}
+
+// LoadKeySet は PEM 形式の秘密鍵ファイルから KeySet を生成します
+// 対応する鍵は Ed25519（EdDSA）と 2048 ビット以上の RSA（RS256）です
+func LoadKeySet(paths []string) (*KeySet, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no signing key files")
+	}
+	keys := make([]*signingKey, 0, len(paths))
+	for _, path := range paths {
+		signer, err := readPrivateKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key %s: %w", path, err)
+		}
+		key, err := newSigningKey(signer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key %s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return newKeySet(keys)
+}
+
+// GenerateKeySet は一時的な Ed25519 の鍵で KeySet を生成します
+// 鍵はプロセスの終了とともに失われるため、再起動すると発行済みのトークンは検証できなくなります
+func GenerateKeySet() (*KeySet, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	key, err := newSigningKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	return newKeySet([]*signingKey{key})
+}
+
+// newKeySet は署名鍵から KeySet を生成します
+func newKeySet(keys []*signingKey) (*KeySet, error) {
+	ks := &KeySet{byID: make(map[string]*signingKey, len(keys))}
+	jwks := struct {
+		Keys []jwk `json:"keys"`
+	}{Keys: make([]jwk, 0, len(keys))}
+
+	for _, key := range keys {
+		if _, ok := ks.byID[key.kid]; ok {
+			return nil, fmt.Errorf("duplicate signing key %s", key.kid)
+		}
+		ks.keys = append(ks.keys, key)
+		ks.byID[key.kid] = key
+		jwks.Keys = append(jwks.Keys, key.jwk)
+	}
+
+	b, err := json.Marshal(jwks)
+	if err != nil {
+		return nil, err
+	}
+	ks.jwks = b
+	return ks, nil
+}
+
+// newSigningKey は秘密鍵の種類から署名方式を決定し、JWK と鍵ID（RFC 7638 のサムプリント）を生成します
+func newSigningKey(signer crypto.Signer) (*signingKey, error) {
+	var (
+		method jwt.SigningMethod
+		pub    jwk
+		// thumbprint は RFC 7638 で定められた必須のメンバーのみを辞書順に並べた JSON です
+		thumbprint string
+	)
+	switch k := signer.(type) {
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+		x := base64.RawURLEncoding.EncodeToString(k.Public().(ed25519.PublicKey))
+		pub = jwk{Kty: "OKP", Crv: "Ed25519", X: x}
+		thumbprint = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, x)
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("rsa key must be at least %d bits", minRSAKeyBits)
+		}
+		method = jwt.SigningMethodRS256
+		n := base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		pub = jwk{Kty: "RSA", N: n, E: e}
+		thumbprint = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, e, n)
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", signer)
+	}
+
+	sum := sha256.Sum256([]byte(thumbprint))
+	kid := base64.RawURLEncoding.EncodeToString(sum[:])
+	pub.Kid = kid
+	pub.Alg = method.Alg()
+	pub.Use = "sig"
+	return &signingKey{
+		kid:    kid,
+		method: method,
+		key:    signer,
+		jwk:    pub,
+	}, nil
+}
+
+// readPrivateKey は PEM 形式の秘密鍵（PKCS #8、または RSA の PKCS #1）を読み込みます
+func readPrivateKey(path string) (crypto.Signer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported signing key type %T", key)
+		}
+		return signer, nil
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// signer は署名に使用する鍵を返します
+func (ks *KeySet) signer() *signingKey {
+	return ks.keys[0]
+}
+
+// lookup は鍵IDに対応する鍵を返します
+func (ks *KeySet) lookup(kid string) (*signingKey, bool) {
+	key, ok := ks.byID[kid]
+	return key, ok
+}
+
+// JWKS は検証に使用する公開鍵の一覧を JWK Set（RFC 7517）の JSON で返します
+func (ks *KeySet) JWKS() []byte {
+	return ks.jwks
+}
@@ -0,0 +1,304 @@
+package token_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/infrastructure/token"
+)
+
+const (
+	testIssuer   = "https://api.example.com"
+	testAudience = "example-api"
+)
+
+// testKey はテストで生成した署名鍵と、その鍵を書き出した PEM ファイルです
+type testKey struct {
+	signer crypto.Signer
+	path   string
+}
+
+// newEd25519Key は Ed25519 の鍵を生成して PKCS #8 の PEM ファイルに書き出します
+func newEd25519Key(t *testing.T) testKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return writeKey(t, priv)
+}
+
+// newRSAKey は RSA の鍵を生成して PKCS #8 の PEM ファイルに書き出します
+func newRSAKey(t *testing.T, bits int) testKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return writeKey(t, priv)
+}
+
+func writeKey(t *testing.T, signer crypto.Signer) testKey {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return testKey{signer: signer, path: path}
+}
+
+// newIssuer は鍵ファイルを読み込んで Issuer を生成します（先頭の鍵で署名します）
+func newIssuer(t *testing.T, keys ...testKey) *token.Issuer {
+	t.Helper()
+	paths := make([]string, len(keys))
+	for i, k := range keys {
+		paths[i] = k.path
+	}
+	ks, err := token.LoadKeySet(paths)
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	return token.NewIssuer(ks, testIssuer, testAudience, time.Minute)
+}
+
+// kidOf はトークンのヘッダーの鍵IDを返します
+func kidOf(t *testing.T, s string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(s, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+// validClaims は検証を通過するクレームを返します
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":  testIssuer,
+		"aud":  testAudience,
+		"sub":  "user-1",
+		"role": string(entity.RoleMember),
+		"iat":  now.Unix(),
+		"nbf":  now.Unix(),
+		"exp":  now.Add(time.Minute).Unix(),
+	}
+}
+
+func TestIssueVerifyRoundTrip(t *testing.T) {
+	for name, key := range map[string]testKey{"ed25519": newEd25519Key(t), "rsa": newRSAKey(t, 2048)} {
+		t.Run(name, func(t *testing.T) {
+			issuer := newIssuer(t, key)
+			signed, expiresAt, err := issuer.Issue(context.Background(), "user-1", entity.RoleAdmin)
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+			if d := time.Until(expiresAt); d <= 0 || d > time.Minute {
+				t.Errorf("expiresAt in %v, want within the TTL", d)
+			}
+
+			principal, err := issuer.Verify(context.Background(), signed)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if principal.UserID != "user-1" || principal.Role != entity.RoleAdmin {
+				t.Errorf("Verify = %+v, want user-1 as admin", principal)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	key := newEd25519Key(t)
+	issuer := newIssuer(t, key)
+	signed, _, err := issuer.Issue(context.Background(), "user-1", entity.RoleMember)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	kid := kidOf(t, signed)
+	other := newEd25519Key(t)
+
+	// sign は指定した方式と鍵IDでクレームに署名します
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, change func(jwt.MapClaims)) string {
+		c := validClaims()
+		change(c)
+		tok := jwt.NewWithClaims(method, c)
+		tok.Header["kid"] = kid
+		s, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return s
+	}
+	unchanged := func(jwt.MapClaims) {}
+	publicKey := []byte(key.signer.Public().(ed25519.PublicKey))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: sign(jwt.SigningMethodEdDSA, kid, key.signer, func(c jwt.MapClaims) {
+			c["iat"] = time.Now().Add(-time.Hour).Unix()
+			c["exp"] = time.Now().Add(-time.Hour + time.Minute).Unix()
+		})},
+		{name: "no expiry", token: sign(jwt.SigningMethodEdDSA, kid, key.signer, func(c jwt.MapClaims) { delete(c, "exp") })},
+		{name: "wrong issuer", token: sign(jwt.SigningMethodEdDSA, kid, key.signer, func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" })},
+		{name: "wrong audience", token: sign(jwt.SigningMethodEdDSA, kid, key.signer, func(c jwt.MapClaims) { c["aud"] = "other-api" })},
+		{name: "no subject", token: sign(jwt.SigningMethodEdDSA, kid, key.signer, func(c jwt.MapClaims) { delete(c, "sub") })},
+		{name: "invalid role", token: sign(jwt.SigningMethodEdDSA, kid, key.signer, func(c jwt.MapClaims) { c["role"] = "root" })},
+		{name: "alg none", token: sign(jwt.SigningMethodNone, kid, jwt.UnsafeAllowNoneSignatureType, unchanged)},
+		// 公開鍵を HMAC の秘密鍵として使用する署名方式の取り違え
+		{name: "alg HS256 with public key", token: sign(jwt.SigningMethodHS256, kid, publicKey, unchanged)},
+		{name: "unknown kid", token: sign(jwt.SigningMethodEdDSA, "unknown", key.signer, unchanged)},
+		{name: "signed by other key", token: sign(jwt.SigningMethodEdDSA, kid, other.signer, unchanged)},
+		{name: "tampered", token: signed[:len(signed)-2] + "AA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if principal, err := issuer.Verify(context.Background(), tt.token); err == nil {
+				t.Errorf("Verify = %+v, want error", principal)
+			}
+		})
+	}
+
+	// 別の Issuer の鍵で署名したトークンは、鍵IDが不明なため受け付けない
+	foreign, _, err := newIssuer(t, other).Issue(context.Background(), "user-1", entity.RoleMember)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if _, err := issuer.Verify(context.Background(), foreign); err == nil {
+		t.Error("Verify(foreign key) = nil, want error")
+	}
+}
+
+func TestVerifyWithRotatedOutKey(t *testing.T) {
+	oldKey, newKey := newEd25519Key(t), newEd25519Key(t)
+	before := newIssuer(t, oldKey)
+	signed, _, err := before.Issue(context.Background(), "user-1", entity.RoleMember)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// 新しい鍵を先頭に追加した後は新しい鍵で署名し、古い鍵で署名したトークンも検証できる
+	after := newIssuer(t, newKey, oldKey)
+	if _, err := after.Verify(context.Background(), signed); err != nil {
+		t.Errorf("Verify(old key) = %v, want nil", err)
+	}
+	rotated, _, err := after.Issue(context.Background(), "user-1", entity.RoleMember)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if kidOf(t, rotated) == kidOf(t, signed) {
+		t.Error("Issue after rotation signed with the old key")
+	}
+
+	// 古い鍵を取り除いた後は検証できない
+	if _, err := newIssuer(t, newKey).Verify(context.Background(), signed); err == nil {
+		t.Error("Verify(removed key) = nil, want error")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	edKey, rsaKey := newEd25519Key(t), newRSAKey(t, 2048)
+	issuer := newIssuer(t, edKey, rsaKey)
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(issuer.JWKS(), &set); err != nil {
+		t.Fatalf("JWKS is not valid JSON: %v", err)
+	}
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(set.Keys))
+	}
+
+	// thumbprint は RFC 7638 のサムプリント（必須のメンバーを辞書順に並べた JSON の SHA-256）を計算します
+	thumbprint := func(members map[string]string) string {
+		b, err := json.Marshal(members) // map のキーは辞書順に出力される
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		sum := sha256.Sum256(b)
+		return base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("decode %q: %v", s, err)
+		}
+		return b
+	}
+
+	ed := set.Keys[0]
+	if ed["kty"] != "OKP" || ed["crv"] != "Ed25519" || ed["alg"] != "EdDSA" || ed["use"] != "sig" {
+		t.Errorf("Ed25519 JWK = %v", ed)
+	}
+	if !ed25519.PublicKey(decode(ed["x"])).Equal(edKey.signer.Public()) {
+		t.Error("Ed25519 JWK x does not match the public key")
+	}
+	if want := thumbprint(map[string]string{"crv": ed["crv"], "kty": ed["kty"], "x": ed["x"]}); ed["kid"] != want {
+		t.Errorf("Ed25519 kid = %q, want thumbprint %q", ed["kid"], want)
+	}
+
+	rs := set.Keys[1]
+	if rs["kty"] != "RSA" || rs["alg"] != "RS256" || rs["use"] != "sig" {
+		t.Errorf("RSA JWK = %v", rs)
+	}
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(decode(rs["n"])), E: int(new(big.Int).SetBytes(decode(rs["e"])).Int64())}
+	if !pub.Equal(rsaKey.signer.Public()) {
+		t.Error("RSA JWK n/e do not match the public key")
+	}
+	if want := thumbprint(map[string]string{"e": rs["e"], "kty": rs["kty"], "n": rs["n"]}); rs["kid"] != want {
+		t.Errorf("RSA kid = %q, want thumbprint %q", rs["kid"], want)
+	}
+
+	// 発行したトークンの鍵IDは署名に使用した鍵（先頭の鍵）の JWK と一致する
+	signed, _, err := issuer.Issue(context.Background(), "user-1", entity.RoleMember)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if kid := kidOf(t, signed); kid != ed["kid"] {
+		t.Errorf("token kid = %q, want %q", kid, ed["kid"])
+	}
+}
+
+func TestLoadKeySetRejectsInvalidKeys(t *testing.T) {
+	key := newEd25519Key(t)
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{name: "no files", paths: nil},
+		{name: "missing file", paths: []string{filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "short rsa key", paths: []string{newRSAKey(t, 1024).path}},
+		{name: "duplicate key", paths: []string{key.path, key.path}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := token.LoadKeySet(tt.paths); err == nil {
+				t.Error("LoadKeySet = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/usecase/dto"
+)
+
+// CredentialRepository はスパンを記録する repository.CredentialRepository のデコレーターです
+type CredentialRepository struct {
+	next   repository.CredentialRepository
+	tracer trace.Tracer
+}
+
+// NewCredentialRepository はCredentialRepositoryを生成します
+func NewCredentialRepository(next repository.CredentialRepository, tracer trace.Tracer) repository.CredentialRepository {
+	return &CredentialRepository{next: next, tracer: tracer}
+}
+
+func (r *CredentialRepository) FindByUserID(ctx context.Context, userID string) (credential *entity.Credential, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "CredentialRepository.FindByUserID", userIDKey.String(userID))
+	defer func() { endSpan(span, err) }()
+	return r.next.FindByUserID(ctx, userID)
+}
+
+func (r *CredentialRepository) Save(ctx context.Context, credential *entity.Credential) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "CredentialRepository.Save", userIDKey.String(credential.UserID))
+	defer func() { endSpan(span, err) }()
+	return r.next.Save(ctx, credential)
+}
+
+// refreshTokenFamilyKey はリフレッシュトークンの系列IDの属性キーです
+const refreshTokenFamilyKey = attribute.Key("refresh_token.family_id")
+
+// RefreshTokenRepository はスパンを記録する repository.RefreshTokenRepository のデコレーターです
+// トークンのハッシュ値は属性に記録しません
+type RefreshTokenRepository struct {
+	next   repository.RefreshTokenRepository
+	tracer trace.Tracer
+}
+
+// NewRefreshTokenRepository はRefreshTokenRepositoryを生成します
+func NewRefreshTokenRepository(next repository.RefreshTokenRepository, tracer trace.Tracer) repository.RefreshTokenRepository {
+	return &RefreshTokenRepository{next: next, tracer: tracer}
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (token *entity.RefreshToken, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "RefreshTokenRepository.FindByHash")
+	defer func() { endSpan(span, err) }()
+	return r.next.FindByHash(ctx, tokenHash)
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "RefreshTokenRepository.Create",
+		userIDKey.String(token.UserID), refreshTokenFamilyKey.String(token.FamilyID))
+	defer func() { endSpan(span, err) }()
+	return r.next.Create(ctx, token)
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "RefreshTokenRepository.MarkUsed", attribute.String("refresh_token.id", id))
+	defer func() { endSpan(span, err) }()
+	return r.next.MarkUsed(ctx, id, usedAt)
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "RefreshTokenRepository.RevokeFamily", refreshTokenFamilyKey.String(familyID))
+	defer func() { endSpan(span, err) }()
+	return r.next.RevokeFamily(ctx, familyID, revokedAt)
+}
+
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (n int64, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "RefreshTokenRepository.DeleteExpired", attribute.String("purge.expired_before", before.UTC().Format(time.RFC3339)))
+	defer func() {
+		span.SetAttributes(attribute.Int64("purge.count", n))
+		endSpan(span, err)
+	}()
+	return r.next.DeleteExpired(ctx, before)
+}
+
+// authInteractor はデコレート対象のユースケースのメソッドです
+// handler.AuthInteractorInterface のメソッドと、定期処理から呼び出すメソッドを持ちます
+type authInteractor interface {
+	Login(ctx context.Context, input *dto.LoginInput) (*dto.TokenOutput, error)
+	Refresh(ctx context.Context, input *dto.RefreshInput) (*dto.TokenOutput, error)
+	Logout(ctx context.Context, input *dto.LogoutInput) error
+	PurgeExpiredRefreshTokens(ctx context.Context) (int64, error)
+}
+
+// AuthInteractor はスパンを記録する認証のユースケースのデコレーターです
+// メールアドレスやトークンは個人情報・秘匿情報のため属性に記録しません
+type AuthInteractor struct {
+	next   authInteractor
+	tracer trace.Tracer
+}
+
+// NewAuthInteractor はAuthInteractorを生成します
+func NewAuthInteractor(next authInteractor, tracer trace.Tracer) *AuthInteractor {
+	return &AuthInteractor{next: next, tracer: tracer}
+}
+
+func (i *AuthInteractor) Login(ctx context.Context, input *dto.LoginInput) (out *dto.TokenOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "AuthInteractor.Login")
+	defer func() { endSpan(span, err) }()
+	return i.next.Login(ctx, input)
+}
+
+func (i *AuthInteractor) Logout(ctx context.Context, input *dto.LogoutInput) (err error) {
+	ctx, span := startSpan(ctx, i.tracer, "AuthInteractor.Logout")
+	defer func() { endSpan(span, err) }()
+	return i.next.Logout(ctx, input)
+}
+
+func (i *AuthInteractor) Refresh(ctx context.Context, input *dto.RefreshInput) (out *dto.TokenOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "AuthInteractor.Refresh")
+	defer func() { endSpan(span, err) }()
+	return i.next.Refresh(ctx, input)
+}
+
+func (i *AuthInteractor) PurgeExpiredRefreshTokens(ctx context.Context) (n int64, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "AuthInteractor.PurgeExpiredRefreshTokens")
+	defer func() {
+		span.SetAttributes(attribute.Int64("purge.count", n))
+		endSpan(span, err)
+	}()
+	return i.next.PurgeExpiredRefreshTokens(ctx)
+}
+
+// sessionIDKey はセッションIDの属性キーです
+const sessionIDKey = attribute.Key("session.id")
+
+// SessionRepository はスパンを記録する repository.SessionRepository のデコレーターです
+// トークンのハッシュ値は属性に記録しません
+type SessionRepository struct {
+	next   repository.SessionRepository
+	tracer trace.Tracer
+}
+
+// NewSessionRepository はSessionRepositoryを生成します
+func NewSessionRepository(next repository.SessionRepository, tracer trace.Tracer) repository.SessionRepository {
+	return &SessionRepository{next: next, tracer: tracer}
+}
+
+func (r *SessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (session *entity.Session, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "SessionRepository.FindByTokenHash")
+	defer func() { endSpan(span, err) }()
+	return r.next.FindByTokenHash(ctx, tokenHash)
+}
+
+func (r *SessionRepository) ListByUserID(ctx context.Context, userID string) (sessions []*entity.Session, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "SessionRepository.ListByUserID", userIDKey.String(userID))
+	defer func() { endSpan(span, err) }()
+	return r.next.ListByUserID(ctx, userID)
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *entity.Session) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "SessionRepository.Create", userIDKey.String(session.UserID), sessionIDKey.String(session.ID))
+	defer func() { endSpan(span, err) }()
+	return r.next.Create(ctx, session)
+}
+
+func (r *SessionRepository) Touch(ctx context.Context, id string, lastSeenAt, idleExpiresAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "SessionRepository.Touch", sessionIDKey.String(id))
+	defer func() { endSpan(span, err) }()
+	return r.next.Touch(ctx, id, lastSeenAt, idleExpiresAt)
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, userID, id string) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "SessionRepository.Delete", userIDKey.String(userID), sessionIDKey.String(id))
+	defer func() { endSpan(span, err) }()
+	return r.next.Delete(ctx, userID, id)
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (n int64, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "SessionRepository.DeleteExpired", attribute.String("purge.expired_before", before.UTC().Format(time.RFC3339)))
+	defer func() {
+		span.SetAttributes(attribute.Int64("purge.count", n))
+		endSpan(span, err)
+	}()
+	return r.next.DeleteExpired(ctx, before)
+}
+
+// sessionInteractor はデコレート対象のユースケースのメソッドです
+// handler.SessionInteractorInterface と middleware.SessionVerifier のメソッドと、定期処理から呼び出すメソッドを持ちます
+type sessionInteractor interface {
+	CreateSession(ctx context.Context, input *dto.CreateSessionInput) (*dto.CreatedSessionOutput, error)
+	VerifySession(ctx context.Context, token string) (*auth.Principal, string, error)
+	ListSessions(ctx context.Context) (*dto.SessionsOutput, error)
+	GetCurrentSession(ctx context.Context) (*dto.CurrentSessionOutput, error)
+	RevokeSession(ctx context.Context, input *dto.RevokeSessionInput) error
+	PurgeExpiredSessions(ctx context.Context) (int64, error)
+}
+
+// SessionInteractor はスパンを記録するセッションのユースケースのデコレーターです
+type SessionInteractor struct {
+	next   sessionInteractor
+	tracer trace.Tracer
+}
+
+// NewSessionInteractor はSessionInteractorを生成します
+func NewSessionInteractor(next sessionInteractor, tracer trace.Tracer) *SessionInteractor {
+	return &SessionInteractor{next: next, tracer: tracer}
+}
+
+func (i *SessionInteractor) CreateSession(ctx context.Context, input *dto.CreateSessionInput) (out *dto.CreatedSessionOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "SessionInteractor.CreateSession")
+	defer func() {
+		if out != nil {
+			span.SetAttributes(sessionIDKey.String(out.Session.ID))
+		}
+		endSpan(span, err)
+	}()
+	return i.next.CreateSession(ctx, input)
+}
+
+func (i *SessionInteractor) VerifySession(ctx context.Context, token string) (principal *auth.Principal, csrfToken string, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "SessionInteractor.VerifySession")
+	defer func() {
+		if principal != nil {
+			span.SetAttributes(userIDKey.String(principal.UserID), sessionIDKey.String(principal.SessionID))
+		}
+		endSpan(span, err)
+	}()
+	return i.next.VerifySession(ctx, token)
+}
+
+func (i *SessionInteractor) ListSessions(ctx context.Context) (out *dto.SessionsOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "SessionInteractor.ListSessions")
+	defer func() { endSpan(span, err) }()
+	return i.next.ListSessions(ctx)
+}
+
+func (i *SessionInteractor) GetCurrentSession(ctx context.Context) (out *dto.CurrentSessionOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "SessionInteractor.GetCurrentSession")
+	defer func() {
+		if out != nil {
+			span.SetAttributes(sessionIDKey.String(out.Session.ID))
+		}
+		endSpan(span, err)
+	}()
+	return i.next.GetCurrentSession(ctx)
+}
+
+func (i *SessionInteractor) RevokeSession(ctx context.Context, input *dto.RevokeSessionInput) (err error) {
+	ctx, span := startSpan(ctx, i.tracer, "SessionInteractor.RevokeSession", sessionIDKey.String(input.ID))
+	defer func() { endSpan(span, err) }()
+	return i.next.RevokeSession(ctx, input)
+}
+
+func (i *SessionInteractor) PurgeExpiredSessions(ctx context.Context) (n int64, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "SessionInteractor.PurgeExpiredSessions")
+	defer func() {
+		span.SetAttributes(attribute.Int64("purge.count", n))
+		endSpan(span, err)
+	}()
+	return i.next.PurgeExpiredSessions(ctx)
+}
+
+// apiKeyIDKey は API キーのIDの属性キーです
+const apiKeyIDKey = attribute.Key("api_key.id")
+
+// APIKeyRepository はスパンを記録する repository.APIKeyRepository のデコレーターです
+// キーのハッシュ値は属性に記録しません
+type APIKeyRepository struct {
+	next   repository.APIKeyRepository
+	tracer trace.Tracer
+}
+
+// NewAPIKeyRepository はAPIKeyRepositoryを生成します
+func NewAPIKeyRepository(next repository.APIKeyRepository, tracer trace.Tracer) repository.APIKeyRepository {
+	return &APIKeyRepository{next: next, tracer: tracer}
+}
+
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (key *entity.APIKey, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "APIKeyRepository.FindByPrefix")
+	defer func() { endSpan(span, err) }()
+	return r.next.FindByPrefix(ctx, prefix)
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) (keys []*entity.APIKey, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "APIKeyRepository.List")
+	defer func() { endSpan(span, err) }()
+	return r.next.List(ctx)
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *entity.APIKey) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "APIKeyRepository.Create", apiKeyIDKey.String(key.ID))
+	defer func() { endSpan(span, err) }()
+	return r.next.Create(ctx, key)
+}
+
+func (r *APIKeyRepository) Touch(ctx context.Context, id string, lastUsedAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "APIKeyRepository.Touch", apiKeyIDKey.String(id))
+	defer func() { endSpan(span, err) }()
+	return r.next.Touch(ctx, id, lastUsedAt)
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "APIKeyRepository.Revoke", apiKeyIDKey.String(id))
+	defer func() { endSpan(span, err) }()
+	return r.next.Revoke(ctx, id, revokedAt)
+}
+
+// apiKeyInteractor はデコレート対象のユースケースのメソッドです
+// handler.APIKeyInteractorInterface と middleware.APIKeyVerifier のメソッドを持ちます
+type apiKeyInteractor interface {
+	CreateAPIKey(ctx context.Context, input *dto.CreateAPIKeyInput) (*dto.CreatedAPIKeyOutput, error)
+	ListAPIKeys(ctx context.Context) (*dto.APIKeysOutput, error)
+	RevokeAPIKey(ctx context.Context, input *dto.RevokeAPIKeyInput) error
+	VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// APIKeyInteractor はスパンを記録する API キーのユースケースのデコレーターです
+// キーそのものは秘匿情報のため属性に記録しません
+type APIKeyInteractor struct {
+	next   apiKeyInteractor
+	tracer trace.Tracer
+}
+
+// NewAPIKeyInteractor はAPIKeyInteractorを生成します
+func NewAPIKeyInteractor(next apiKeyInteractor, tracer trace.Tracer) *APIKeyInteractor {
+	return &APIKeyInteractor{next: next, tracer: tracer}
+}
+
+func (i *APIKeyInteractor) CreateAPIKey(ctx context.Context, input *dto.CreateAPIKeyInput) (out *dto.CreatedAPIKeyOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "APIKeyInteractor.CreateAPIKey")
+	defer func() {
+		if out != nil {
+			span.SetAttributes(apiKeyIDKey.String(out.APIKey.ID))
+		}
+		endSpan(span, err)
+	}()
+	return i.next.CreateAPIKey(ctx, input)
+}
+
+func (i *APIKeyInteractor) ListAPIKeys(ctx context.Context) (out *dto.APIKeysOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "APIKeyInteractor.ListAPIKeys")
+	defer func() { endSpan(span, err) }()
+	return i.next.ListAPIKeys(ctx)
+}
+
+func (i *APIKeyInteractor) RevokeAPIKey(ctx context.Context, input *dto.RevokeAPIKeyInput) (err error) {
+	ctx, span := startSpan(ctx, i.tracer, "APIKeyInteractor.RevokeAPIKey", apiKeyIDKey.String(input.ID))
+	defer func() { endSpan(span, err) }()
+	return i.next.RevokeAPIKey(ctx, input)
+}
+
+func (i *APIKeyInteractor) VerifyAPIKey(ctx context.Context, key string) (principal *auth.Principal, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "APIKeyInteractor.VerifyAPIKey")
+	defer func() {
+		if principal != nil {
+			span.SetAttributes(apiKeyIDKey.String(principal.APIKeyID))
+		}
+		endSpan(span, err)
+	}()
+	return i.next.VerifyAPIKey(ctx, key)
+}
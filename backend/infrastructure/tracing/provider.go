@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// InstrumentationName はこのアプリケーションが生成するスパンの計装名です
+const InstrumentationName = "project_template/backend"
+
+// Config はトレーシングの設定です
+type Config struct {
+	// Exporter はスパンの出力先です（none, stdout, file）
+	Exporter string
+	// FilePath は Exporter が file の場合の出力先ファイルです
+	FilePath string
+	// SampleRatio はルートスパンをサンプリングする割合（0.0〜1.0）です
+	// 親スパンがある場合は親のサンプリング判定に従います
+	SampleRatio float64
+	// ServiceName はリソース属性 service.name に設定する名前です
+	ServiceName string
+}
+
+// Setup はトレーサープロバイダーとW3C Trace Contextのプロパゲーターをグローバルに設定します
+// 返り値の関数は停止時に呼び出し、未送信のスパンを出力します
+func Setup(cfg Config) (trace.TracerProvider, func(context.Context) error, error) {
+	// traceparent / tracestate ヘッダーによる伝播
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var w io.Writer
+	var closeFile func() error
+	switch cfg.Exporter {
+	case "", "none":
+		tp := noop.NewTracerProvider()
+		otel.SetTracerProvider(tp)
+		return tp, func(context.Context) error { return nil }, nil
+	case "stdout":
+		w = os.Stdout
+	case "file":
+		f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open trace file: %w", err)
+		}
+		w, closeFile = f, f.Close
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	shutdown := func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closeFile != nil {
+			if cerr := closeFile(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+	return tp, shutdown, nil
+}
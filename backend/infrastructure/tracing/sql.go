@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// stringLiteralPattern と numberLiteralPattern はSQL文中のリテラルを検出します
+	stringLiteralPattern = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	numberLiteralPattern = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	whitespacePattern    = regexp.MustCompile(`\s+`)
+)
+
+// SanitizeStatement はSQL文のリテラルを "?" に置き換え、空白を正規化します
+// スパンの属性に値（メールアドレスなど）が含まれないようにするために使用します
+func SanitizeStatement(query string) string {
+	s := stringLiteralPattern.ReplaceAllString(query, "?")
+	s = numberLiteralPattern.ReplaceAllString(s, "?")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(s, " "))
+}
+
+// WrapConnector はSQLの実行ごとにスパンを記録する driver.Connector を返します
+// sql.OpenDB に渡すことで、リポジトリのコードを変更せずにSQL呼び出しを計装できます
+func WrapConnector(c driver.Connector, tracer trace.Tracer, system string) driver.Connector {
+	return &tracedConnector{Connector: c, tracer: tracer, system: system}
+}
+
+type tracedConnector struct {
+	driver.Connector
+	tracer trace.Tracer
+	system string
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, c: c}, nil
+}
+
+// record は処理後にスパンを記録します
+// driver.ErrSkip の場合は database/sql が別の経路で再実行するため記録しません
+func (c *tracedConnector) record(ctx context.Context, op, query string, start time.Time, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	stmt := SanitizeStatement(query)
+	_, span := c.tracer.Start(ctx, "db."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			attribute.String("db.system", c.system),
+			attribute.String("db.operation", operationName(stmt)),
+			attribute.String("db.statement", stmt),
+		),
+	)
+	if err != nil {
+		recordError(span, err)
+	}
+	span.End()
+}
+
+// operationName はSQL文の先頭のキーワードを返します
+func operationName(stmt string) string {
+	op, _, _ := strings.Cut(stmt, " ")
+	return strings.ToUpper(op)
+}
+
+type tracedConn struct {
+	driver.Conn
+	c *tracedConnector
+}
+
+func (tc *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := tc.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	tc.c.record(ctx, "exec", query, start, err)
+	return res, err
+}
+
+func (tc *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := tc.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	tc.c.record(ctx, "query", query, start, err)
+	return rows, err
+}
+
+func (tc *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := tc.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = tc.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: stmt, query: query, c: tc.c}, nil
+}
+
+func (tc *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := tc.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return tc.Conn.Begin()
+}
+
+func (tc *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := tc.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (tc *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := tc.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (tc *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := tc.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (tc *tracedConn) IsValid() bool {
+	if validator, ok := tc.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+type tracedStmt struct {
+	driver.Stmt
+	query string
+	c     *tracedConnector
+}
+
+func (ts *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var res driver.Result
+	var err error
+	if execer, ok := ts.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		res, err = ts.Stmt.Exec(namedValuesToValues(args))
+	}
+	ts.c.record(ctx, "exec", ts.query, start, err)
+	return res, err
+}
+
+func (ts *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := ts.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = ts.Stmt.Query(namedValuesToValues(args))
+	}
+	ts.c.record(ctx, "query", ts.query, start, err)
+	return rows, err
+}
+
+func (ts *tracedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := ts.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (ts *tracedStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if converter, ok := ts.Stmt.(driver.ColumnConverter); ok {
+		return converter.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// namedValuesToValues は旧来の driver.Value のスライスに変換します
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return values
+}
@@ -0,0 +1,263 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/domain/services"
+	"project_template/backend/usecase/dto"
+)
+
+// スパンに付与するユーザーの属性名
+const (
+	userIDKey      = attribute.Key("user.id")
+	userVersionKey = attribute.Key("user.version")
+)
+
+// startSpan は層とメソッド名からスパンを開始します
+func startSpan(ctx context.Context, tracer trace.Tracer, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan はエラーを記録してスパンを終了します
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		recordError(span, err)
+	}
+	span.End()
+}
+
+// recordError はスパンにエラーの種類を記録します
+// エラーメッセージにはメールアドレスなどの個人情報や SQL の値が含まれるため、エクスポーターには送りません
+// アプリケーションのエラーは apperror の種類を、それ以外はラップされた元のエラーの型名を記録します
+func recordError(span trace.Span, err error) {
+	cause := err
+	for next := errors.Unwrap(cause); next != nil; next = errors.Unwrap(cause) {
+		cause = next
+	}
+	errType := fmt.Sprintf("%T", cause)
+	if appErr, ok := apperror.As(err); ok {
+		errType = appErr.Kind.String()
+	}
+	span.SetAttributes(attribute.String("error.type", errType))
+	span.SetStatus(codes.Error, errType)
+}
+
+// UserRepository はスパンを記録する repository.UserRepository のデコレーターです
+type UserRepository struct {
+	next   repository.UserRepository
+	tracer trace.Tracer
+}
+
+// NewUserRepository はUserRepositoryを生成します
+func NewUserRepository(next repository.UserRepository, tracer trace.Tracer) repository.UserRepository {
+	return &UserRepository{next: next, tracer: tracer}
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id string, opts ...repository.FindOption) (user *entity.User, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.FindByID", userIDKey.String(id), includeDeletedAttr(opts))
+	defer func() { endSpan(span, err) }()
+	return r.next.FindByID(ctx, id, opts...)
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email string, opts ...repository.FindOption) (user *entity.User, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.FindByEmail", includeDeletedAttr(opts))
+	defer func() { endSpan(span, err) }()
+	return r.next.FindByEmail(ctx, email, opts...)
+}
+
+func (r *UserRepository) FindAll(ctx context.Context, opts ...repository.FindOption) (users []*entity.User, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.FindAll", includeDeletedAttr(opts))
+	defer func() { endSpan(span, err) }()
+	return r.next.FindAll(ctx, opts...)
+}
+
+func (r *UserRepository) FindPage(ctx context.Context, query repository.UserPageQuery) (users []*entity.User, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.FindPage",
+		attribute.Int("query.limit", query.Limit),
+		attribute.String("query.sort", string(query.Sort)),
+		attribute.String("query.order", string(query.Order)),
+		attribute.Bool("query.include_deleted", query.Filter.IncludeDeleted),
+	)
+	defer func() { endSpan(span, err) }()
+	return r.next.FindPage(ctx, query)
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.Create", userIDKey.String(user.ID))
+	defer func() { endSpan(span, err) }()
+	return r.next.Create(ctx, user)
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.Update", userIDKey.String(user.ID), userVersionKey.Int64(user.Version))
+	defer func() { endSpan(span, err) }()
+	return r.next.Update(ctx, user)
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string, version int64) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.Delete", userIDKey.String(id), userVersionKey.Int64(version))
+	defer func() { endSpan(span, err) }()
+	return r.next.Delete(ctx, id, version)
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id string, version int64) (err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.Restore", userIDKey.String(id), userVersionKey.Int64(version))
+	defer func() { endSpan(span, err) }()
+	return r.next.Restore(ctx, id, version)
+}
+
+func (r *UserRepository) Purge(ctx context.Context, deletedBefore time.Time) (n int64, err error) {
+	ctx, span := startSpan(ctx, r.tracer, "UserRepository.Purge", attribute.String("purge.deleted_before", deletedBefore.UTC().Format(time.RFC3339)))
+	defer func() {
+		span.SetAttributes(attribute.Int64("purge.count", n))
+		endSpan(span, err)
+	}()
+	return r.next.Purge(ctx, deletedBefore)
+}
+
+// includeDeletedAttr は検索オプションに削除済みのユーザーを含めるかを属性にします
+func includeDeletedAttr(opts []repository.FindOption) attribute.KeyValue {
+	return attribute.Bool("query.include_deleted", repository.HasOption(opts, repository.IncludeDeleted))
+}
+
+// TxManager はスパンを記録する repository.TxManager のデコレーターです
+// トランザクション内のリポジトリ操作はこのスパンの子として記録されます
+type TxManager struct {
+	next   repository.TxManager
+	tracer trace.Tracer
+}
+
+// NewTxManager はTxManagerを生成します
+func NewTxManager(next repository.TxManager, tracer trace.Tracer) repository.TxManager {
+	return &TxManager{next: next, tracer: tracer}
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	ctx, span := startSpan(ctx, m.tracer, "TxManager.WithinTx")
+	defer func() { endSpan(span, err) }()
+	attempts := 0
+	err = m.next.WithinTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return fn(ctx)
+	})
+	span.SetAttributes(attribute.Int("tx.attempts", attempts))
+	return err
+}
+
+// UserService はスパンを記録する services.UserServiceInterface のデコレーターです
+type UserService struct {
+	next   services.UserServiceInterface
+	tracer trace.Tracer
+}
+
+// NewUserService はUserServiceを生成します
+func NewUserService(next services.UserServiceInterface, tracer trace.Tracer) services.UserServiceInterface {
+	return &UserService{next: next, tracer: tracer}
+}
+
+func (s *UserService) ValidateUniqueEmail(ctx context.Context, email string) (unique bool, err error) {
+	ctx, span := startSpan(ctx, s.tracer, "UserService.ValidateUniqueEmail")
+	defer func() {
+		if err == nil {
+			span.SetAttributes(attribute.Bool("email.unique", unique))
+		}
+		endSpan(span, err)
+	}()
+	return s.next.ValidateUniqueEmail(ctx, email)
+}
+
+// userInteractor はデコレート対象のユースケースのメソッドです
+// handler.UserInteractorInterface と同じメソッドを持ちます
+type userInteractor interface {
+	GetUser(ctx context.Context, input *dto.GetUserInput) (*dto.UserOutput, error)
+	GetUsers(ctx context.Context, input *dto.GetUsersInput) (*dto.UsersOutput, error)
+	CreateUser(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error)
+	UpdateUser(ctx context.Context, input *dto.UpdateUserInput) (*dto.UserOutput, error)
+	DeleteUser(ctx context.Context, input *dto.DeleteUserInput) error
+	RestoreUser(ctx context.Context, input *dto.RestoreUserInput) (*dto.UserOutput, error)
+	EnsureAdmin(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error)
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// UserInteractor はスパンを記録するユースケースのデコレーターです
+type UserInteractor struct {
+	next   userInteractor
+	tracer trace.Tracer
+}
+
+// NewUserInteractor はUserInteractorを生成します
+func NewUserInteractor(next userInteractor, tracer trace.Tracer) *UserInteractor {
+	return &UserInteractor{next: next, tracer: tracer}
+}
+
+func (i *UserInteractor) GetUser(ctx context.Context, input *dto.GetUserInput) (out *dto.UserOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.GetUser", userIDKey.String(input.ID))
+	defer func() { endSpan(span, err) }()
+	return i.next.GetUser(ctx, input)
+}
+
+func (i *UserInteractor) GetUsers(ctx context.Context, input *dto.GetUsersInput) (out *dto.UsersOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.GetUsers")
+	defer func() { endSpan(span, err) }()
+	return i.next.GetUsers(ctx, input)
+}
+
+func (i *UserInteractor) CreateUser(ctx context.Context, input *dto.CreateUserInput) (out *dto.UserOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.CreateUser")
+	defer func() {
+		if out != nil {
+			span.SetAttributes(userIDKey.String(out.ID))
+		}
+		endSpan(span, err)
+	}()
+	return i.next.CreateUser(ctx, input)
+}
+
+func (i *UserInteractor) UpdateUser(ctx context.Context, input *dto.UpdateUserInput) (out *dto.UserOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.UpdateUser", userIDKey.String(input.ID))
+	defer func() { endSpan(span, err) }()
+	return i.next.UpdateUser(ctx, input)
+}
+
+func (i *UserInteractor) DeleteUser(ctx context.Context, input *dto.DeleteUserInput) (err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.DeleteUser", userIDKey.String(input.ID))
+	defer func() { endSpan(span, err) }()
+	return i.next.DeleteUser(ctx, input)
+}
+
+func (i *UserInteractor) RestoreUser(ctx context.Context, input *dto.RestoreUserInput) (out *dto.UserOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.RestoreUser", userIDKey.String(input.ID))
+	defer func() { endSpan(span, err) }()
+	return i.next.RestoreUser(ctx, input)
+}
+
+func (i *UserInteractor) EnsureAdmin(ctx context.Context, input *dto.CreateUserInput) (out *dto.UserOutput, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.EnsureAdmin")
+	defer func() {
+		span.SetAttributes(attribute.Bool("user.created", out != nil))
+		if out != nil {
+			span.SetAttributes(userIDKey.String(out.ID))
+		}
+		endSpan(span, err)
+	}()
+	return i.next.EnsureAdmin(ctx, input)
+}
+
+func (i *UserInteractor) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (n int64, err error) {
+	ctx, span := startSpan(ctx, i.tracer, "UserInteractor.PurgeDeletedUsers", attribute.String("purge.retention", retention.String()))
+	defer func() {
+		span.SetAttributes(attribute.Int64("purge.count", n))
+		endSpan(span, err)
+	}()
+	return i.next.PurgeDeletedUsers(ctx, retention)
+}
@@ -0,0 +1,77 @@
+package tracing_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/infrastructure/tracing"
+)
+
+// failingUserRepository は常に決まったエラーを返す UserRepository です
+type failingUserRepository struct {
+	repository.UserRepository
+	err error
+}
+
+func (r failingUserRepository) Create(ctx context.Context, user *entity.User) error {
+	return r.err
+}
+
+func TestSpanErrorOmitsMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantType string
+	}{
+		{name: "application error", err: apperror.Conflict("email alice@example.com already exists"), wantType: "conflict"},
+		{name: "driver error", err: fmt.Errorf("insert: %w", &driverError{"Duplicate entry 'alice@example.com'"}), wantType: "*tracing_test.driverError"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			repo := tracing.NewUserRepository(failingUserRepository{err: tt.err}, tp.Tracer("test"))
+
+			user := &entity.User{ID: "user-1", Email: "alice@example.com"}
+			if err := repo.Create(context.Background(), user); err != tt.err {
+				t.Fatalf("Create = %v, want %v", err, tt.err)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("recorded %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Status().Code != codes.Error || span.Status().Description != tt.wantType {
+				t.Errorf("status = %+v, want Error %q", span.Status(), tt.wantType)
+			}
+			// エラーメッセージに含まれる個人情報はエクスポートしない
+			for _, kv := range span.Attributes() {
+				if strings.Contains(kv.Value.Emit(), "alice@example.com") {
+					t.Errorf("attribute %s contains the email", kv.Key)
+				}
+			}
+			for _, ev := range span.Events() {
+				for _, kv := range ev.Attributes {
+					if strings.Contains(kv.Value.Emit(), "alice@example.com") {
+						t.Errorf("event %s attribute %s contains the email", ev.Name, kv.Key)
+					}
+				}
+			}
+		})
+	}
+}
+
+// driverError はデータベースドライバーのエラーを模したエラーです
+type driverError struct{ msg string }
+
+func (e *driverError) Error() string { return e.msg }
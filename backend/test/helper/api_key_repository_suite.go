@@ -0,0 +1,165 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+)
+
+// APIKeyRepositoryFactory は空のストレージに対する APIKeyRepository を生成します
+type APIKeyRepositoryFactory func(t *testing.T) repository.APIKeyRepository
+
+// RunAPIKeyRepositorySuite は APIKeyRepository の実装が満たすべき振る舞いを検証します
+func RunAPIKeyRepositorySuite(t *testing.T, newStore APIKeyRepositoryFactory) {
+	t.Helper()
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		testAPIKeyCreateAndFind(t, newStore(t))
+	})
+	t.Run("List", func(t *testing.T) {
+		testAPIKeyList(t, newStore(t))
+	})
+	t.Run("Touch", func(t *testing.T) {
+		testAPIKeyTouch(t, newStore(t))
+	})
+	t.Run("Revoke", func(t *testing.T) {
+		testAPIKeyRevoke(t, newStore(t))
+	})
+}
+
+// seedAPIKey は createdAt に作成された API キーを保存します
+// 識別用の接頭辞は "prefix-<id>"、ハッシュ値は "hash-<id>" です
+func seedAPIKey(t *testing.T, repo repository.APIKeyRepository, id string, createdAt time.Time, expiresAt *time.Time) *entity.APIKey {
+	t.Helper()
+
+	key := &entity.APIKey{
+		ID:         id,
+		Name:       "key " + id,
+		Prefix:     "prefix-" + id,
+		SecretHash: "hash-" + id,
+		Scopes:     []entity.APIKeyScope{entity.ScopeUsersRead, entity.ScopeUsersWrite},
+		CreatedBy:  "00000000-0000-0000-0000-000000000001",
+		CreatedAt:  createdAt,
+		ExpiresAt:  expiresAt,
+	}
+	if err := repo.Create(context.Background(), key); err != nil {
+		t.Fatalf("Create(%q): %v", id, err)
+	}
+	return key
+}
+
+func testAPIKeyCreateAndFind(t *testing.T, keys repository.APIKeyRepository) {
+	ctx := context.Background()
+	expiresAt := baseTime.Add(24 * time.Hour)
+	seedAPIKey(t, keys, "key-1", baseTime, &expiresAt)
+	seedAPIKey(t, keys, "key-2", baseTime, nil)
+
+	got, err := keys.FindByPrefix(ctx, "prefix-key-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByPrefix = (%v, %v)", got, err)
+	}
+	if got.ID != "key-1" || got.Name != "key key-1" || got.SecretHash != "hash-key-1" ||
+		got.CreatedBy != "00000000-0000-0000-0000-000000000001" || !got.CreatedAt.Equal(baseTime) ||
+		got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) || got.LastUsedAt != nil || got.RevokedAt != nil {
+		t.Errorf("FindByPrefix = %+v, want key-1", got)
+	}
+	if len(got.Scopes) != 2 || got.Scopes[0] != entity.ScopeUsersRead || got.Scopes[1] != entity.ScopeUsersWrite {
+		t.Errorf("FindByPrefix Scopes = %v, want [users:read users:write]", got.Scopes)
+	}
+
+	// 有効期限のないキー
+	got, err = keys.FindByPrefix(ctx, "prefix-key-2")
+	if err != nil || got == nil || got.ExpiresAt != nil {
+		t.Errorf("FindByPrefix(no expiry) = (%+v, %v), want ExpiresAt nil", got, err)
+	}
+
+	if got, err := keys.FindByPrefix(ctx, "prefix-unknown"); got != nil || err != nil {
+		t.Errorf("FindByPrefix(unknown) = (%v, %v), want (nil, nil)", got, err)
+	}
+}
+
+func testAPIKeyList(t *testing.T, keys repository.APIKeyRepository) {
+	ctx := context.Background()
+
+	empty, err := keys.List(ctx)
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("List(no keys) = (%v, %v), want empty slice", empty, err)
+	}
+
+	seedAPIKey(t, keys, "key-1", baseTime, nil)
+	seedAPIKey(t, keys, "key-2", baseTime.Add(time.Minute), nil)
+	seedAPIKey(t, keys, "key-3", baseTime.Add(-time.Minute), nil)
+	if err := keys.Revoke(ctx, "key-3", baseTime); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	got, err := keys.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	// 作成日時の新しい順で、失効したキーも含める
+	if ids := apiKeyIDs(got); strings.Join(ids, ",") != "key-2,key-1,key-3" {
+		t.Errorf("List = %v, want [key-2 key-1 key-3]", ids)
+	}
+}
+
+func testAPIKeyTouch(t *testing.T, keys repository.APIKeyRepository) {
+	ctx := context.Background()
+	seedAPIKey(t, keys, "key-1", baseTime, nil)
+
+	lastUsedAt := baseTime.Add(10 * time.Minute)
+	if err := keys.Touch(ctx, "key-1", lastUsedAt); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	got, err := keys.FindByPrefix(ctx, "prefix-key-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByPrefix after Touch = (%v, %v)", got, err)
+	}
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(lastUsedAt) {
+		t.Errorf("after Touch LastUsedAt = %v, want %v", got.LastUsedAt, lastUsedAt)
+	}
+}
+
+func testAPIKeyRevoke(t *testing.T, keys repository.APIKeyRepository) {
+	ctx := context.Background()
+	seedAPIKey(t, keys, "key-1", baseTime, nil)
+
+	revokedAt := baseTime.Add(time.Hour)
+	if err := keys.Revoke(ctx, "key-1", revokedAt); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	got, err := keys.FindByPrefix(ctx, "prefix-key-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByPrefix after Revoke = (%v, %v)", got, err)
+	}
+	if got.RevokedAt == nil || !got.RevokedAt.Equal(revokedAt) {
+		t.Errorf("after Revoke RevokedAt = %v, want %v", got.RevokedAt, revokedAt)
+	}
+
+	// 既に失効しているキーは何もせず、失効日時も変更しない
+	if err := keys.Revoke(ctx, "key-1", revokedAt.Add(time.Hour)); err != nil {
+		t.Errorf("Revoke(revoked) = %v, want nil", err)
+	}
+	got, err = keys.FindByPrefix(ctx, "prefix-key-1")
+	if err != nil || got == nil || got.RevokedAt == nil || !got.RevokedAt.Equal(revokedAt) {
+		t.Errorf("after second Revoke = (%+v, %v), want RevokedAt %v", got, err, revokedAt)
+	}
+
+	if err := keys.Revoke(ctx, "key-unknown", revokedAt); !errors.Is(err, repository.ErrAPIKeyNotFound) {
+		t.Errorf("Revoke(unknown) = %v, want ErrAPIKeyNotFound", err)
+	}
+}
+
+// apiKeyIDs は API キーのIDの一覧を返します
+func apiKeyIDs(keys []*entity.APIKey) []string {
+	ids := make([]string, len(keys))
+	for i, k := range keys {
+		ids[i] = k.ID
+	}
+	return ids
+}
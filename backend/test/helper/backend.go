@@ -0,0 +1,257 @@
+package helper
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"project_template/backend/adapter/repository"
+	"project_template/backend/adapter/repository/memory"
+	"project_template/backend/adapter/repository/postgres"
+	"project_template/backend/adapter/repository/sqlite"
+	"project_template/backend/adapter/repository/transaction"
+	domainRepo "project_template/backend/domain/repository"
+	"project_template/backend/infrastructure/bootstrap"
+	"project_template/backend/infrastructure/config"
+	"project_template/backend/infrastructure/db/migration"
+	"project_template/backend/infrastructure/db/migrator"
+)
+
+// txMaxAttempts はテストで使用するトランザクションの最大試行回数です
+const txMaxAttempts = 3
+
+// Stores は1つの空のストレージに対する各リポジトリです
+type Stores struct {
+	Users         domainRepo.UserRepository
+	Credentials   domainRepo.CredentialRepository
+	RefreshTokens domainRepo.RefreshTokenRepository
+	Sessions      domainRepo.SessionRepository
+	APIKeys       domainRepo.APIKeyRepository
+	TxManager     domainRepo.TxManager
+}
+
+// Backend はリポジトリの実装の1つです
+// Open はテストごとに空のストレージを用意し、その上のリポジトリを返します
+type Backend struct {
+	Name string
+	Open func(t *testing.T) *Stores
+}
+
+// Backends はリポジトリのスイートを実行するすべての実装です
+// データベースの実装は TEST_POSTGRES_DSN, TEST_MYSQL_DSN が設定されていない場合はスキップします
+var Backends = []Backend{
+	{Name: "memory", Open: openMemory},
+	{Name: "sqlite", Open: openSQLite},
+	{Name: "postgres", Open: openPostgres},
+	{Name: "mysql", Open: openMySQL},
+}
+
+// RunRepositorySuites は実装に対してすべてのリポジトリのスイートを実行します
+// リポジトリを追加する場合は Stores と各実装の Open に追加し、ここでスイートを実行します
+func RunRepositorySuites(t *testing.T, b Backend) {
+	t.Helper()
+
+	t.Run("UserRepository", func(t *testing.T) {
+		RunUserRepositorySuite(t, func(t *testing.T) domainRepo.UserRepository {
+			return b.Open(t).Users
+		})
+	})
+	t.Run("TxManager", func(t *testing.T) {
+		RunTxManagerSuite(t, func(t *testing.T) (domainRepo.TxManager, domainRepo.UserRepository) {
+			s := b.Open(t)
+			return s.TxManager, s.Users
+		})
+	})
+	t.Run("CredentialRepository", func(t *testing.T) {
+		RunCredentialRepositorySuite(t, func(t *testing.T) (domainRepo.CredentialRepository, domainRepo.UserRepository) {
+			s := b.Open(t)
+			return s.Credentials, s.Users
+		})
+	})
+	t.Run("RefreshTokenRepository", func(t *testing.T) {
+		RunRefreshTokenRepositorySuite(t, func(t *testing.T) (domainRepo.RefreshTokenRepository, domainRepo.UserRepository) {
+			s := b.Open(t)
+			return s.RefreshTokens, s.Users
+		})
+	})
+	t.Run("SessionRepository", func(t *testing.T) {
+		RunSessionRepositorySuite(t, func(t *testing.T) (domainRepo.SessionRepository, domainRepo.UserRepository) {
+			s := b.Open(t)
+			return s.Sessions, s.Users
+		})
+	})
+	t.Run("APIKeyRepository", func(t *testing.T) {
+		RunAPIKeyRepositorySuite(t, func(t *testing.T) domainRepo.APIKeyRepository {
+			return b.Open(t).APIKeys
+		})
+	})
+}
+
+// openMemory はメモリ上のリポジトリを返します
+func openMemory(t *testing.T) *Stores {
+	users := memory.NewUserRepository()
+	return &Stores{
+		Users:         users,
+		Credentials:   memory.NewCredentialRepository(users),
+		RefreshTokens: memory.NewRefreshTokenRepository(users),
+		Sessions:      memory.NewSessionRepository(users),
+		APIKeys:       memory.NewAPIKeyRepository(),
+		TxManager:     memory.NewTxManager(),
+	}
+}
+
+// openSQLite はテストごとの一時ファイルにマイグレーション済みのデータベースを作成します
+func openSQLite(t *testing.T) *Stores {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := bootstrap.InitSQLite(ctx, &config.Config{SQLitePath: filepath.Join(t.TempDir(), "test.db")}, noop.NewTracerProvider())
+	if err != nil {
+		t.Fatalf("InitSQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrate(t, db, migration.SQLite, migrator.SQLite{})
+
+	return &Stores{
+		Users:         sqlite.NewUserRepository(db),
+		Credentials:   sqlite.NewCredentialRepository(db),
+		RefreshTokens: sqlite.NewRefreshTokenRepository(db),
+		Sessions:      sqlite.NewSessionRepository(db),
+		APIKeys:       sqlite.NewAPIKeyRepository(db),
+		TxManager:     transaction.NewManager(db, sqlite.IsRetryable, txMaxAttempts),
+	}
+}
+
+// openPostgres は TEST_POSTGRES_DSN のデータベースにマイグレーションを適用し、すべてのテーブルを空にして返します
+// テーブルのデータは削除されるため、テスト専用のデータベースを指定してください
+// TEST_POSTGRES_DSN が設定されていない場合はテストをスキップします
+func openPostgres(t *testing.T) *Stores {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrate(t, db, migration.Postgres, migrator.Postgres{})
+
+	tables := listTables(t, db,
+		"SELECT quote_ident(tablename) FROM pg_tables WHERE schemaname = current_schema() AND tablename <> 'schema_migrations'")
+	for _, name := range tables {
+		if _, err := db.ExecContext(ctx, "TRUNCATE TABLE "+name+" CASCADE"); err != nil {
+			t.Fatalf("truncate %s: %v", name, err)
+		}
+	}
+
+	return &Stores{
+		Users:         postgres.NewUserRepository(db),
+		Credentials:   postgres.NewCredentialRepository(db),
+		RefreshTokens: postgres.NewRefreshTokenRepository(db),
+		Sessions:      postgres.NewSessionRepository(db),
+		APIKeys:       postgres.NewAPIKeyRepository(db),
+		TxManager:     transaction.NewManager(db, postgres.IsRetryable, txMaxAttempts),
+	}
+}
+
+// openMySQL は TEST_MYSQL_DSN のデータベースにマイグレーションを適用し、すべてのテーブルを空にして返します
+// テーブルのデータは削除されるため、テスト専用のデータベースを指定してください
+// TEST_MYSQL_DSN が設定されていない場合はテストをスキップします
+func openMySQL(t *testing.T) *Stores {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN is not set")
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid TEST_MYSQL_DSN: %v", err)
+	}
+	// リポジトリは日時の列を time.Time として読み込む
+	cfg.ParseTime = true
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		t.Fatalf("mysql.NewConnector: %v", err)
+	}
+	ctx := context.Background()
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { db.Close() })
+	migrate(t, db, migration.MySQL, migrator.MySQL{})
+
+	tables := listTables(t, db,
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name <> 'schema_migrations'")
+	// 外部キー制約の無効化は接続単位のため、同じ接続で TRUNCATE する
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("db.Conn: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		t.Fatalf("disable foreign key checks: %v", err)
+	}
+	for _, name := range tables {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE `"+name+"`"); err != nil {
+			t.Fatalf("truncate %s: %v", name, err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+		t.Fatalf("enable foreign key checks: %v", err)
+	}
+
+	return &Stores{
+		Users:         repository.NewUserRepository(db),
+		Credentials:   repository.NewCredentialRepository(db),
+		RefreshTokens: repository.NewRefreshTokenRepository(db),
+		Sessions:      repository.NewSessionRepository(db),
+		APIKeys:       repository.NewAPIKeyRepository(db),
+		TxManager:     transaction.NewManager(db, repository.IsRetryable, txMaxAttempts),
+	}
+}
+
+// migrate はデータベースにすべてのマイグレーションを適用します
+func migrate(t *testing.T, db *sql.DB, files fs.FS, dialect migrator.Dialect) {
+	t.Helper()
+
+	m, err := migrator.New(db, files, dialect)
+	if err != nil {
+		t.Fatalf("migrator.New: %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+}
+
+// listTables はマイグレーションの管理テーブルを除くテーブル名を返します
+func listTables(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+
+	rows, err := db.QueryContext(context.Background(), query)
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("list tables: %v", err)
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	return tables
+}
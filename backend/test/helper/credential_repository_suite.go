@@ -0,0 +1,109 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+)
+
+// CredentialRepositoryFactory は空のストレージに対する CredentialRepository と、同じストレージの UserRepository を生成します
+type CredentialRepositoryFactory func(t *testing.T) (repository.CredentialRepository, repository.UserRepository)
+
+// RunCredentialRepositorySuite は CredentialRepository の実装が満たすべき振る舞いを検証します
+func RunCredentialRepositorySuite(t *testing.T, newStore CredentialRepositoryFactory) {
+	t.Helper()
+
+	t.Run("SaveAndFind", func(t *testing.T) {
+		creds, users := newStore(t)
+		testCredentialSaveAndFind(t, creds, users)
+	})
+	t.Run("MissingUser", func(t *testing.T) {
+		creds, _ := newStore(t)
+		testCredentialMissingUser(t, creds)
+	})
+	t.Run("PurgedUser", func(t *testing.T) {
+		creds, users := newStore(t)
+		testCredentialPurgedUser(t, creds, users)
+	})
+}
+
+// seedCredential はユーザーの認証情報を保存します
+func seedCredential(t *testing.T, repo repository.CredentialRepository, userID, hash string) *entity.Credential {
+	t.Helper()
+
+	credential := entity.NewCredential(userID, hash)
+	credential.CreatedAt = baseTime
+	credential.UpdatedAt = baseTime
+	if err := repo.Save(context.Background(), credential); err != nil {
+		t.Fatalf("Save(%q): %v", userID, err)
+	}
+	return credential
+}
+
+func testCredentialSaveAndFind(t *testing.T, creds repository.CredentialRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedCredential(t, creds, user.ID, "hash-1")
+
+	got, err := creds.FindByUserID(ctx, user.ID)
+	if err != nil || got == nil {
+		t.Fatalf("FindByUserID = (%v, %v)", got, err)
+	}
+	if got.PasswordHash != "hash-1" || !got.CreatedAt.Equal(baseTime) || !got.UpdatedAt.Equal(baseTime) {
+		t.Errorf("FindByUserID = %+v, want hash-1 created and updated at %v", got, baseTime)
+	}
+
+	// 保存済みの場合は置き換え、作成日時は変更しない
+	got.PasswordHash = "hash-2"
+	got.CreatedAt = baseTime.Add(time.Hour)
+	got.UpdatedAt = baseTime.Add(time.Hour)
+	if err := creds.Save(ctx, got); err != nil {
+		t.Fatalf("Save(replace): %v", err)
+	}
+	replaced, err := creds.FindByUserID(ctx, user.ID)
+	if err != nil || replaced == nil {
+		t.Fatalf("FindByUserID after replace = (%v, %v)", replaced, err)
+	}
+	if replaced.PasswordHash != "hash-2" || !replaced.CreatedAt.Equal(baseTime) || !replaced.UpdatedAt.Equal(baseTime.Add(time.Hour)) {
+		t.Errorf("FindByUserID after replace = %+v, want hash-2 created at %v", replaced, baseTime)
+	}
+}
+
+func testCredentialMissingUser(t *testing.T, creds repository.CredentialRepository) {
+	const id = "00000000-0000-0000-0000-000000000404"
+
+	if got, err := creds.FindByUserID(context.Background(), id); got != nil || err != nil {
+		t.Errorf("FindByUserID(missing) = (%v, %v), want (nil, nil)", got, err)
+	}
+	// 存在しないユーザーの認証情報は保存できない
+	err := creds.Save(context.Background(), entity.NewCredential(id, "hash"))
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Save(missing user) = %v, want ErrUserNotFound", err)
+	}
+}
+
+func testCredentialPurgedUser(t *testing.T, creds repository.CredentialRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedCredential(t, creds, user.ID, "hash")
+
+	// ソフトデリートされたユーザーの認証情報は復元に備えて残す
+	if err := users.Delete(ctx, user.ID, user.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := creds.FindByUserID(ctx, user.ID); got == nil || err != nil {
+		t.Errorf("FindByUserID after Delete = (%v, %v), want credential", got, err)
+	}
+
+	// 完全に削除されたユーザーの認証情報は残さない
+	if _, err := users.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if got, err := creds.FindByUserID(ctx, user.ID); got != nil || err != nil {
+		t.Errorf("FindByUserID after Purge = (%v, %v), want (nil, nil)", got, err)
+	}
+}
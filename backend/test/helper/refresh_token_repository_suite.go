@@ -0,0 +1,152 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+)
+
+// RefreshTokenRepositoryFactory は空のストレージに対する RefreshTokenRepository と、同じストレージの UserRepository を生成します
+type RefreshTokenRepositoryFactory func(t *testing.T) (repository.RefreshTokenRepository, repository.UserRepository)
+
+// RunRefreshTokenRepositorySuite は RefreshTokenRepository の実装が満たすべき振る舞いを検証します
+func RunRefreshTokenRepositorySuite(t *testing.T, newStore RefreshTokenRepositoryFactory) {
+	t.Helper()
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		tokens, users := newStore(t)
+		testRefreshTokenCreateAndFind(t, tokens, users)
+	})
+	t.Run("MarkUsedOnce", func(t *testing.T) {
+		tokens, users := newStore(t)
+		testRefreshTokenMarkUsedOnce(t, tokens, users)
+	})
+	t.Run("RevokeFamily", func(t *testing.T) {
+		tokens, users := newStore(t)
+		testRefreshTokenRevokeFamily(t, tokens, users)
+	})
+	t.Run("DeleteExpired", func(t *testing.T) {
+		tokens, users := newStore(t)
+		testRefreshTokenDeleteExpired(t, tokens, users)
+	})
+	t.Run("MissingUser", func(t *testing.T) {
+		tokens, _ := newStore(t)
+		testRefreshTokenMissingUser(t, tokens)
+	})
+}
+
+// seedRefreshToken は baseTime に作成され、baseTime から ttl 後に期限切れになるトークンを保存します
+func seedRefreshToken(t *testing.T, repo repository.RefreshTokenRepository, id, familyID, userID string, ttl time.Duration) *entity.RefreshToken {
+	t.Helper()
+
+	token := entity.NewRefreshToken(id, familyID, userID, "hash-"+id, ttl)
+	token.CreatedAt = baseTime
+	token.ExpiresAt = baseTime.Add(ttl)
+	if err := repo.Create(context.Background(), token); err != nil {
+		t.Fatalf("Create(%q): %v", id, err)
+	}
+	return token
+}
+
+func testRefreshTokenCreateAndFind(t *testing.T, tokens repository.RefreshTokenRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedRefreshToken(t, tokens, "token-1", "family-1", user.ID, time.Hour)
+
+	got, err := tokens.FindByHash(ctx, "hash-token-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByHash = (%v, %v)", got, err)
+	}
+	if got.ID != "token-1" || got.FamilyID != "family-1" || got.UserID != user.ID ||
+		!got.CreatedAt.Equal(baseTime) || !got.ExpiresAt.Equal(baseTime.Add(time.Hour)) || got.IsUsed() || got.IsRevoked() {
+		t.Errorf("FindByHash = %+v, want unused token-1 of family-1", got)
+	}
+
+	if got, err := tokens.FindByHash(ctx, "hash-unknown"); got != nil || err != nil {
+		t.Errorf("FindByHash(unknown) = (%v, %v), want (nil, nil)", got, err)
+	}
+}
+
+func testRefreshTokenMarkUsedOnce(t *testing.T, tokens repository.RefreshTokenRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedRefreshToken(t, tokens, "token-1", "family-1", user.ID, time.Hour)
+
+	usedAt := baseTime.Add(time.Minute)
+	if err := tokens.MarkUsed(ctx, "token-1", usedAt); err != nil {
+		t.Fatalf("MarkUsed: %v", err)
+	}
+	got, err := tokens.FindByHash(ctx, "hash-token-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByHash after MarkUsed = (%v, %v)", got, err)
+	}
+	if !got.IsUsed() || !got.UsedAt.Equal(usedAt) {
+		t.Errorf("UsedAt = %v, want %v", got.UsedAt, usedAt)
+	}
+
+	// 使用済みのトークンは再び使用済みにできない
+	if err := tokens.MarkUsed(ctx, "token-1", usedAt.Add(time.Minute)); !errors.Is(err, repository.ErrRefreshTokenNotActive) {
+		t.Errorf("MarkUsed(used) = %v, want ErrRefreshTokenNotActive", err)
+	}
+}
+
+func testRefreshTokenRevokeFamily(t *testing.T, tokens repository.RefreshTokenRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedRefreshToken(t, tokens, "token-1", "family-1", user.ID, time.Hour)
+	seedRefreshToken(t, tokens, "token-2", "family-1", user.ID, time.Hour)
+	seedRefreshToken(t, tokens, "token-3", "family-2", user.ID, time.Hour)
+
+	revokedAt := baseTime.Add(time.Minute)
+	if err := tokens.RevokeFamily(ctx, "family-1", revokedAt); err != nil {
+		t.Fatalf("RevokeFamily: %v", err)
+	}
+	for _, id := range []string{"token-1", "token-2"} {
+		got, err := tokens.FindByHash(ctx, "hash-"+id)
+		if err != nil || got == nil {
+			t.Fatalf("FindByHash(%s) = (%v, %v)", id, got, err)
+		}
+		if !got.IsRevoked() || !got.RevokedAt.Equal(revokedAt) {
+			t.Errorf("%s RevokedAt = %v, want %v", id, got.RevokedAt, revokedAt)
+		}
+	}
+	// 他の系列のトークンは失効させない
+	if got, err := tokens.FindByHash(ctx, "hash-token-3"); err != nil || got == nil || got.IsRevoked() {
+		t.Errorf("FindByHash(token-3) = (%+v, %v), want active token", got, err)
+	}
+
+	// 失効したトークンは使用済みにできない
+	if err := tokens.MarkUsed(ctx, "token-1", revokedAt); !errors.Is(err, repository.ErrRefreshTokenNotActive) {
+		t.Errorf("MarkUsed(revoked) = %v, want ErrRefreshTokenNotActive", err)
+	}
+}
+
+func testRefreshTokenDeleteExpired(t *testing.T, tokens repository.RefreshTokenRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedRefreshToken(t, tokens, "token-1", "family-1", user.ID, time.Hour)
+	seedRefreshToken(t, tokens, "token-2", "family-1", user.ID, 3*time.Hour)
+
+	n, err := tokens.DeleteExpired(ctx, baseTime.Add(2*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteExpired = (%d, %v), want (1, nil)", n, err)
+	}
+	if got, err := tokens.FindByHash(ctx, "hash-token-1"); got != nil || err != nil {
+		t.Errorf("FindByHash(expired) = (%v, %v), want (nil, nil)", got, err)
+	}
+	if got, err := tokens.FindByHash(ctx, "hash-token-2"); got == nil || err != nil {
+		t.Errorf("FindByHash(active) = (%v, %v), want token", got, err)
+	}
+}
+
+func testRefreshTokenMissingUser(t *testing.T, tokens repository.RefreshTokenRepository) {
+	// 存在しないユーザーのトークンは保存できない
+	token := entity.NewRefreshToken("token-1", "family-1", "00000000-0000-0000-0000-000000000404", "hash-token-1", time.Hour)
+	if err := tokens.Create(context.Background(), token); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Create(missing user) = %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,15 @@
+package helper_test
+
+import (
+	"testing"
+
+	"project_template/backend/test/helper"
+)
+
+func TestRepositories(t *testing.T) {
+	for _, b := range helper.Backends {
+		t.Run(b.Name, func(t *testing.T) {
+			helper.RunRepositorySuites(t, b)
+		})
+	}
+}
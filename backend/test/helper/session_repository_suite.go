@@ -0,0 +1,190 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+)
+
+// SessionRepositoryFactory は空のストレージに対する SessionRepository と、同じストレージの UserRepository を生成します
+type SessionRepositoryFactory func(t *testing.T) (repository.SessionRepository, repository.UserRepository)
+
+// RunSessionRepositorySuite は SessionRepository の実装が満たすべき振る舞いを検証します
+func RunSessionRepositorySuite(t *testing.T, newStore SessionRepositoryFactory) {
+	t.Helper()
+
+	t.Run("CreateAndFind", func(t *testing.T) {
+		sessions, users := newStore(t)
+		testSessionCreateAndFind(t, sessions, users)
+	})
+	t.Run("ListByUserID", func(t *testing.T) {
+		sessions, users := newStore(t)
+		testSessionListByUserID(t, sessions, users)
+	})
+	t.Run("Touch", func(t *testing.T) {
+		sessions, users := newStore(t)
+		testSessionTouch(t, sessions, users)
+	})
+	t.Run("DeleteOwnOnly", func(t *testing.T) {
+		sessions, users := newStore(t)
+		testSessionDeleteOwnOnly(t, sessions, users)
+	})
+	t.Run("DeleteExpired", func(t *testing.T) {
+		sessions, users := newStore(t)
+		testSessionDeleteExpired(t, sessions, users)
+	})
+	t.Run("MissingUser", func(t *testing.T) {
+		sessions, _ := newStore(t)
+		testSessionMissingUser(t, sessions)
+	})
+}
+
+// seedSession は baseTime に作成され、最終アクセスが lastSeenAt のセッションを保存します
+// 無操作による有効期限は lastSeenAt の30分後、ログインからの有効期限は baseTime の12時間後です
+func seedSession(t *testing.T, repo repository.SessionRepository, id, userID string, lastSeenAt time.Time) *entity.Session {
+	t.Helper()
+
+	session := entity.NewSession(id, userID, "hash-"+id, "csrf-"+id, "test-agent", "192.0.2.1", 30*time.Minute, 12*time.Hour)
+	session.CreatedAt = baseTime
+	session.ExpiresAt = baseTime.Add(12 * time.Hour)
+	session.Touch(lastSeenAt, 30*time.Minute)
+	if err := repo.Create(context.Background(), session); err != nil {
+		t.Fatalf("Create(%q): %v", id, err)
+	}
+	return session
+}
+
+func testSessionCreateAndFind(t *testing.T, sessions repository.SessionRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedSession(t, sessions, "session-1", user.ID, baseTime)
+
+	got, err := sessions.FindByTokenHash(ctx, "hash-session-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByTokenHash = (%v, %v)", got, err)
+	}
+	if got.ID != "session-1" || got.UserID != user.ID || got.CSRFToken != "csrf-session-1" ||
+		got.UserAgent != "test-agent" || got.IPAddress != "192.0.2.1" ||
+		!got.CreatedAt.Equal(baseTime) || !got.LastSeenAt.Equal(baseTime) ||
+		!got.IdleExpiresAt.Equal(baseTime.Add(30*time.Minute)) || !got.ExpiresAt.Equal(baseTime.Add(12*time.Hour)) {
+		t.Errorf("FindByTokenHash = %+v, want session-1 of %s", got, user.ID)
+	}
+
+	if got, err := sessions.FindByTokenHash(ctx, "hash-unknown"); got != nil || err != nil {
+		t.Errorf("FindByTokenHash(unknown) = (%v, %v), want (nil, nil)", got, err)
+	}
+}
+
+func testSessionListByUserID(t *testing.T, sessions repository.SessionRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	alice := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	bob := seedUser(t, users, "00000000-0000-0000-0000-000000000002", "Bob", "bob@example.com", baseTime)
+	seedSession(t, sessions, "session-1", alice.ID, baseTime)
+	seedSession(t, sessions, "session-2", alice.ID, baseTime.Add(time.Minute))
+	seedSession(t, sessions, "session-3", bob.ID, baseTime)
+
+	got, err := sessions.ListByUserID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("ListByUserID: %v", err)
+	}
+	// 最終アクセスの新しい順で、他のユーザーのセッションは含めない
+	if ids := sessionIDs(got); strings.Join(ids, ",") != "session-2,session-1" {
+		t.Errorf("ListByUserID = %v, want [session-2 session-1]", ids)
+	}
+
+	empty, err := sessions.ListByUserID(ctx, "00000000-0000-0000-0000-000000000404")
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("ListByUserID(no sessions) = (%v, %v), want empty slice", empty, err)
+	}
+}
+
+func testSessionTouch(t *testing.T, sessions repository.SessionRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedSession(t, sessions, "session-1", user.ID, baseTime)
+
+	lastSeenAt := baseTime.Add(10 * time.Minute)
+	if err := sessions.Touch(ctx, "session-1", lastSeenAt, lastSeenAt.Add(30*time.Minute)); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	got, err := sessions.FindByTokenHash(ctx, "hash-session-1")
+	if err != nil || got == nil {
+		t.Fatalf("FindByTokenHash after Touch = (%v, %v)", got, err)
+	}
+	if !got.LastSeenAt.Equal(lastSeenAt) || !got.IdleExpiresAt.Equal(lastSeenAt.Add(30*time.Minute)) {
+		t.Errorf("after Touch LastSeenAt = %v, IdleExpiresAt = %v, want %v and %v",
+			got.LastSeenAt, got.IdleExpiresAt, lastSeenAt, lastSeenAt.Add(30*time.Minute))
+	}
+	// ログインからの有効期限は変更しない
+	if !got.ExpiresAt.Equal(baseTime.Add(12 * time.Hour)) {
+		t.Errorf("after Touch ExpiresAt = %v, want %v", got.ExpiresAt, baseTime.Add(12*time.Hour))
+	}
+}
+
+func testSessionDeleteOwnOnly(t *testing.T, sessions repository.SessionRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	alice := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	bob := seedUser(t, users, "00000000-0000-0000-0000-000000000002", "Bob", "bob@example.com", baseTime)
+	seedSession(t, sessions, "session-1", alice.ID, baseTime)
+
+	// 他のユーザーのセッションは削除できない
+	if err := sessions.Delete(ctx, bob.ID, "session-1"); !errors.Is(err, repository.ErrSessionNotFound) {
+		t.Errorf("Delete(other user) = %v, want ErrSessionNotFound", err)
+	}
+	if err := sessions.Delete(ctx, alice.ID, "session-1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := sessions.FindByTokenHash(ctx, "hash-session-1"); got != nil || err != nil {
+		t.Errorf("FindByTokenHash after Delete = (%v, %v), want (nil, nil)", got, err)
+	}
+	if err := sessions.Delete(ctx, alice.ID, "session-1"); !errors.Is(err, repository.ErrSessionNotFound) {
+		t.Errorf("Delete(deleted) = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func testSessionDeleteExpired(t *testing.T, sessions repository.SessionRepository, users repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, users, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedSession(t, sessions, "idle", user.ID, baseTime)
+	seedSession(t, sessions, "active", user.ID, baseTime.Add(time.Hour))
+
+	// 無操作による有効期限を過ぎたセッションのみ削除する
+	n, err := sessions.DeleteExpired(ctx, baseTime.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteExpired(idle) = (%d, %v), want (1, nil)", n, err)
+	}
+	if got, err := sessions.FindByTokenHash(ctx, "hash-idle"); got != nil || err != nil {
+		t.Errorf("FindByTokenHash(idle) = (%v, %v), want (nil, nil)", got, err)
+	}
+
+	// ログインからの有効期限を過ぎたセッションは最終アクセスに関わらず削除する
+	if err := sessions.Touch(ctx, "active", baseTime.Add(12*time.Hour), baseTime.Add(12*time.Hour)); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	n, err = sessions.DeleteExpired(ctx, baseTime.Add(12*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteExpired(absolute) = (%d, %v), want (1, nil)", n, err)
+	}
+}
+
+func testSessionMissingUser(t *testing.T, sessions repository.SessionRepository) {
+	// 存在しないユーザーのセッションは保存できない
+	session := entity.NewSession("session-1", "00000000-0000-0000-0000-000000000404", "hash", "csrf", "", "", time.Minute, time.Hour)
+	if err := sessions.Create(context.Background(), session); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Create(missing user) = %v, want ErrUserNotFound", err)
+	}
+}
+
+// sessionIDs はセッションのIDの一覧を返します
+func sessionIDs(sessions []*entity.Session) []string {
+	ids := make([]string, len(sessions))
+	for i, s := range sessions {
+		ids[i] = s.ID
+	}
+	return ids
+}
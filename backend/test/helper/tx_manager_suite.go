@@ -0,0 +1,135 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+)
+
+// TxManagerFactory は空のストレージに対する TxManager と、同じストレージの UserRepository を生成します
+type TxManagerFactory func(t *testing.T) (repository.TxManager, repository.UserRepository)
+
+// errRollback はロールバックさせるために fn から返すエラーです
+var errRollback = errors.New("rollback")
+
+// RunTxManagerSuite は TxManager の実装が満たすべき振る舞いを検証します
+func RunTxManagerSuite(t *testing.T, newStore TxManagerFactory) {
+	t.Helper()
+
+	t.Run("Commit", func(t *testing.T) {
+		tx, repo := newStore(t)
+		testTxCommit(t, tx, repo)
+	})
+	t.Run("Rollback", func(t *testing.T) {
+		tx, repo := newStore(t)
+		testTxRollback(t, tx, repo)
+	})
+	t.Run("NestedRollback", func(t *testing.T) {
+		tx, repo := newStore(t)
+		testTxNestedRollback(t, tx, repo)
+	})
+}
+
+// userExists はユーザーが保存されているか確認します
+func userExists(t *testing.T, repo repository.UserRepository, id string) bool {
+	t.Helper()
+
+	user, err := repo.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("FindByID(%q): %v", id, err)
+	}
+	return user != nil
+}
+
+func testTxCommit(t *testing.T, tx repository.TxManager, repo repository.UserRepository) {
+	const id = "00000000-0000-0000-0000-000000000001"
+
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		seedUserContext(t, ctx, repo, id, "Alice", "alice@example.com")
+		// トランザクション内では書き込んだ内容を読み込めること
+		if !userExists(t, txRepo{repo, ctx}, id) {
+			t.Error("user not visible inside transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+	if !userExists(t, repo, id) {
+		t.Error("user not found after commit")
+	}
+}
+
+func testTxRollback(t *testing.T, tx repository.TxManager, repo repository.UserRepository) {
+	ctx := context.Background()
+	const keep, created = "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"
+	seedUser(t, repo, keep, "Alice", "alice@example.com", baseTime)
+
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		seedUserContext(t, ctx, repo, created, "Bob", "bob@example.com")
+		if err := repo.Delete(ctx, keep, 1); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTx = %v, want %v", err, errRollback)
+	}
+	if userExists(t, repo, created) {
+		t.Error("created user remains after rollback")
+	}
+	if !userExists(t, repo, keep) {
+		t.Error("deleted user not restored after rollback")
+	}
+}
+
+func testTxNestedRollback(t *testing.T, tx repository.TxManager, repo repository.UserRepository) {
+	const outer, inner = "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"
+
+	err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+		seedUserContext(t, ctx, repo, outer, "Alice", "alice@example.com")
+
+		// 内側のトランザクションの失敗は、内側の変更のみを取り消すこと
+		err := tx.WithinTx(ctx, func(ctx context.Context) error {
+			seedUserContext(t, ctx, repo, inner, "Bob", "bob@example.com")
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Errorf("nested WithinTx = %v, want %v", err, errRollback)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+	if !userExists(t, repo, outer) {
+		t.Error("outer user not found after commit")
+	}
+	if userExists(t, repo, inner) {
+		t.Error("inner user remains after nested rollback")
+	}
+}
+
+// seedUserContext は ctx のトランザクション内でユーザーを保存します
+func seedUserContext(t *testing.T, ctx context.Context, repo repository.UserRepository, id, name, email string) {
+	t.Helper()
+	seedUser(t, txRepo{repo, ctx}, id, name, email, baseTime)
+}
+
+// txRepo は context.Background() の代わりに ctx を使用して呼び出す UserRepository です
+// context を受け取らない補助関数からトランザクション内の操作を行うために使用します
+type txRepo struct {
+	repository.UserRepository
+	ctx context.Context
+}
+
+func (r txRepo) FindByID(_ context.Context, id string, opts ...repository.FindOption) (*entity.User, error) {
+	return r.UserRepository.FindByID(r.ctx, id, opts...)
+}
+
+func (r txRepo) Create(_ context.Context, user *entity.User) error {
+	return r.UserRepository.Create(r.ctx, user)
+}
@@ -0,0 +1,477 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+)
+
+// UserRepositoryFactory は空の UserRepository を生成します
+// データベースを使用する実装の場合は、呼び出しごとにテーブルを空にしてください
+type UserRepositoryFactory func(t *testing.T) repository.UserRepository
+
+// RunUserRepositorySuite は UserRepository の実装が満たすべき振る舞いを検証します
+// メモリ実装とデータベース実装で同じ結果になることを確認するため、RunRepositorySuites から Backends のすべての実装に対して呼び出します
+func RunUserRepositorySuite(t *testing.T, newRepo UserRepositoryFactory) {
+	t.Helper()
+
+	t.Run("CreateAndFind", func(t *testing.T) { testCreateAndFind(t, newRepo(t)) })
+	t.Run("FindMissing", func(t *testing.T) { testFindMissing(t, newRepo(t)) })
+	t.Run("EmailIsCaseInsensitive", func(t *testing.T) { testEmailIsCaseInsensitive(t, newRepo(t)) })
+	t.Run("ConcurrentCreateSameEmail", func(t *testing.T) { testConcurrentCreateSameEmail(t, newRepo(t)) })
+	t.Run("UpdateAndDelete", func(t *testing.T) { testUpdateAndDelete(t, newRepo(t)) })
+	t.Run("UpdateAndDeleteMissing", func(t *testing.T) { testUpdateAndDeleteMissing(t, newRepo(t)) })
+	t.Run("VersionConflict", func(t *testing.T) { testVersionConflict(t, newRepo(t)) })
+	t.Run("SoftDeleteAndRestore", func(t *testing.T) { testSoftDeleteAndRestore(t, newRepo(t)) })
+	t.Run("EmailReuseAfterDelete", func(t *testing.T) { testEmailReuseAfterDelete(t, newRepo(t)) })
+	t.Run("Purge", func(t *testing.T) { testPurge(t, newRepo(t)) })
+	t.Run("FindAllOrder", func(t *testing.T) { testFindAllOrder(t, newRepo(t)) })
+	t.Run("FindPage", func(t *testing.T) { testFindPage(t, newRepo(t)) })
+}
+
+// seedUser は作成日時を指定してユーザーを保存します
+// 日時はデータベースの精度に合わせて秒単位で指定します
+func seedUser(t *testing.T, repo repository.UserRepository, id, name, email string, createdAt time.Time) *entity.User {
+	t.Helper()
+
+	user, err := entity.NewUser(id, name, email)
+	if err != nil {
+		t.Fatalf("NewUser(%q): %v", id, err)
+	}
+	user.CreatedAt = createdAt.UTC().Truncate(time.Second)
+	user.UpdatedAt = user.CreatedAt
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create(%q): %v", id, err)
+	}
+	return user
+}
+
+// baseTime はテストデータの作成日時の基準です
+var baseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testCreateAndFind(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	want := seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+
+	got, err := repo.FindByID(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got == nil {
+		t.Fatal("FindByID: user not found")
+	}
+	if got.ID != want.ID || got.Name != want.Name || got.Email != want.Email || got.Role != entity.RoleMember {
+		t.Errorf("FindByID = %+v, want %+v", got, want)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("FindByID times = (%v, %v), want (%v, %v)", got.CreatedAt, got.UpdatedAt, want.CreatedAt, want.UpdatedAt)
+	}
+
+	// 取得した値の変更が保存済みのデータに影響しないこと
+	got.Name = "Changed"
+	again, err := repo.FindByID(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if again.Name != want.Name {
+		t.Errorf("stored name changed without Update: %q", again.Name)
+	}
+}
+
+func testConcurrentCreateSameEmail(t *testing.T, repo repository.UserRepository) {
+	const n = 10
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		// 大文字小文字のみ異なるメールアドレスも重複とする
+		email := "same@example.com"
+		if i%2 == 1 {
+			email = "SAME@example.com"
+		}
+		user, err := entity.NewUser(fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1), fmt.Sprintf("User %d", i), email)
+		if err != nil {
+			t.Fatalf("NewUser: %v", err)
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			// 事前の重複確認を行わず、一意制約のみで重複を検出できることを確認する
+			errs[i] = repo.Create(context.Background(), user)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case apperror.KindOf(err) == apperror.KindConflict && errors.Is(err, repository.ErrEmailAlreadyExists):
+		default:
+			t.Errorf("Create #%d = %v, want nil or ErrEmailAlreadyExists", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("succeeded = %d, want 1", succeeded)
+	}
+}
+
+func testFindMissing(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+
+	user, err := repo.FindByID(ctx, "00000000-0000-0000-0000-000000000404")
+	if user != nil || err != nil {
+		t.Errorf("FindByID(missing) = (%v, %v), want (nil, nil)", user, err)
+	}
+	user, err = repo.FindByEmail(ctx, "missing@example.com")
+	if user != nil || err != nil {
+		t.Errorf("FindByEmail(missing) = (%v, %v), want (nil, nil)", user, err)
+	}
+}
+
+func testEmailIsCaseInsensitive(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	alice := seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Alice", "Alice@Example.com", baseTime)
+
+	got, err := repo.FindByEmail(ctx, "alice@example.COM")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if got == nil || got.ID != alice.ID {
+		t.Fatalf("FindByEmail = %v, want user %q", got, alice.ID)
+	}
+
+	dup, err := entity.NewUser("00000000-0000-0000-0000-000000000002", "Another", "ALICE@example.com")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	if err := repo.Create(ctx, dup); !errors.Is(err, repository.ErrEmailAlreadyExists) {
+		t.Errorf("Create(duplicate email) = %v, want ErrEmailAlreadyExists", err)
+	}
+
+	// 他のユーザーのメールアドレスへの変更は一意制約違反となる
+	bob := seedUser(t, repo, "00000000-0000-0000-0000-000000000003", "Bob", "bob@example.com", baseTime)
+	if err := bob.ChangeEmail("ALICE@EXAMPLE.COM"); err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+	if err := repo.Update(ctx, bob); !errors.Is(err, repository.ErrEmailAlreadyExists) {
+		t.Errorf("Update(duplicate email) = %v, want ErrEmailAlreadyExists", err)
+	}
+
+	// 自身のメールアドレスの大文字小文字のみの変更は許可される
+	if err := alice.ChangeEmail("alice@example.com"); err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+	if err := repo.Update(ctx, alice); err != nil {
+		t.Errorf("Update(own email case change) = %v, want nil", err)
+	}
+}
+
+func testUpdateAndDelete(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+
+	user.Name = "Alice Updated"
+	user.Email = "alice.updated@example.com"
+	user.Role = entity.RoleAdmin
+	user.UpdatedAt = baseTime.Add(time.Hour)
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, user.ID)
+	if err != nil || got == nil {
+		t.Fatalf("FindByID = (%v, %v)", got, err)
+	}
+	if got.Name != user.Name || got.Email != user.Email || got.Role != user.Role || !got.UpdatedAt.Equal(user.UpdatedAt) {
+		t.Errorf("FindByID after Update = %+v, want %+v", got, user)
+	}
+	// 更新のたびにバージョンが進み、引数のユーザーにも反映される
+	if user.Version != 2 || got.Version != 2 {
+		t.Errorf("Version after Update = (%d, stored %d), want 2", user.Version, got.Version)
+	}
+
+	// 変更前のメールアドレスは再利用できる
+	if other, err := repo.FindByEmail(ctx, "alice@example.com"); other != nil || err != nil {
+		t.Errorf("FindByEmail(old email) = (%v, %v), want (nil, nil)", other, err)
+	}
+	seedUser(t, repo, "00000000-0000-0000-0000-000000000002", "New Alice", "alice@example.com", baseTime)
+
+	if err := repo.Delete(ctx, user.ID, user.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := repo.FindByID(ctx, user.ID); got != nil || err != nil {
+		t.Errorf("FindByID after Delete = (%v, %v), want (nil, nil)", got, err)
+	}
+}
+
+func testUpdateAndDeleteMissing(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+
+	user, err := entity.NewUser("00000000-0000-0000-0000-000000000404", "Nobody", "nobody@example.com")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	if err := repo.Update(ctx, user); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Update(missing) = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Delete(ctx, user.ID, user.Version); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Delete(missing) = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Restore(ctx, user.ID, user.Version); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Restore(missing) = %v, want ErrUserNotFound", err)
+	}
+}
+
+func testVersionConflict(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	stale := *user
+
+	user.Name = "Alice Updated"
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// 古いバージョンに基づく更新・削除は他の更新を上書きしない
+	stale.Name = "Stale"
+	if err := repo.Update(ctx, &stale); !errors.Is(err, repository.ErrUserVersionConflict) {
+		t.Errorf("Update(stale) = %v, want ErrUserVersionConflict", err)
+	}
+	if stale.Version != 1 {
+		t.Errorf("Version after failed Update = %d, want 1", stale.Version)
+	}
+	if err := repo.Delete(ctx, stale.ID, stale.Version); !errors.Is(err, repository.ErrUserVersionConflict) {
+		t.Errorf("Delete(stale) = %v, want ErrUserVersionConflict", err)
+	}
+
+	got, err := repo.FindByID(ctx, user.ID)
+	if err != nil || got == nil {
+		t.Fatalf("FindByID = (%v, %v)", got, err)
+	}
+	if got.Name != "Alice Updated" || got.Version != 2 {
+		t.Errorf("FindByID after conflicts = %+v, want name %q version 2", got, "Alice Updated")
+	}
+}
+
+func testSoftDeleteAndRestore(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedUser(t, repo, "00000000-0000-0000-0000-000000000002", "Bob", "bob@example.com", baseTime.Add(time.Hour))
+
+	if err := repo.Restore(ctx, user.ID, user.Version); !errors.Is(err, repository.ErrUserNotDeleted) {
+		t.Errorf("Restore(active) = %v, want ErrUserNotDeleted", err)
+	}
+	if err := repo.Delete(ctx, user.ID, user.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// 削除済みのユーザーは既定では検索結果に含まれない
+	if got, err := repo.FindByID(ctx, user.ID); got != nil || err != nil {
+		t.Errorf("FindByID after Delete = (%v, %v), want (nil, nil)", got, err)
+	}
+	if got, err := repo.FindByEmail(ctx, user.Email); got != nil || err != nil {
+		t.Errorf("FindByEmail after Delete = (%v, %v), want (nil, nil)", got, err)
+	}
+	users, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	assertIDs(t, "FindAll", users, "00000000-0000-0000-0000-000000000002")
+	page := repository.UserPageQuery{Limit: 10, Sort: repository.UserSortByCreatedAt, Order: repository.SortAsc}
+	assertIDs(t, "FindPage", collectPages(t, repo, page), "00000000-0000-0000-0000-000000000002")
+
+	// IncludeDeleted を指定した場合は削除日時とともに取得できる
+	deleted, err := repo.FindByID(ctx, user.ID, repository.IncludeDeleted)
+	if err != nil || deleted == nil {
+		t.Fatalf("FindByID(IncludeDeleted) = (%v, %v)", deleted, err)
+	}
+	if !deleted.IsDeleted() || deleted.Version != 2 {
+		t.Errorf("FindByID(IncludeDeleted) = %+v, want deleted with version 2", deleted)
+	}
+	if got, err := repo.FindByEmail(ctx, user.Email, repository.IncludeDeleted); err != nil || got == nil || got.ID != user.ID {
+		t.Errorf("FindByEmail(IncludeDeleted) = (%v, %v), want %s", got, err, user.ID)
+	}
+	users, err = repo.FindAll(ctx, repository.IncludeDeleted)
+	if err != nil {
+		t.Fatalf("FindAll(IncludeDeleted): %v", err)
+	}
+	assertIDs(t, "FindAll(IncludeDeleted)", users, "00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	page.Filter.IncludeDeleted = true
+	assertIDs(t, "FindPage(IncludeDeleted)", collectPages(t, repo, page), "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002")
+
+	// 削除済みのユーザーは更新・再削除できない
+	deleted.Name = "Deleted"
+	if err := repo.Update(ctx, deleted); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Update(deleted) = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Delete(ctx, user.ID, 2); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Delete(deleted) = %v, want ErrUserNotFound", err)
+	}
+
+	if err := repo.Restore(ctx, user.ID, 1); !errors.Is(err, repository.ErrUserVersionConflict) {
+		t.Errorf("Restore(stale) = %v, want ErrUserVersionConflict", err)
+	}
+	if err := repo.Restore(ctx, user.ID, 2); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	got, err := repo.FindByID(ctx, user.ID)
+	if err != nil || got == nil {
+		t.Fatalf("FindByID after Restore = (%v, %v)", got, err)
+	}
+	if got.IsDeleted() || got.Version != 3 || got.Name != "Alice" {
+		t.Errorf("FindByID after Restore = %+v, want active Alice with version 3", got)
+	}
+}
+
+func testEmailReuseAfterDelete(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	if err := repo.Delete(ctx, user.ID, user.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// 削除済みのユーザーのメールアドレスは別のユーザーが使用できる
+	seedUser(t, repo, "00000000-0000-0000-0000-000000000002", "New Alice", "ALICE@example.com", baseTime)
+	if got, err := repo.FindByEmail(ctx, "alice@example.com"); err != nil || got == nil || got.ID != "00000000-0000-0000-0000-000000000002" {
+		t.Errorf("FindByEmail = (%v, %v), want new user", got, err)
+	}
+
+	// 同じメールアドレスのユーザーがいる間は復元できない
+	if err := repo.Restore(ctx, user.ID, 2); !errors.Is(err, repository.ErrEmailAlreadyExists) {
+		t.Errorf("Restore(email in use) = %v, want ErrEmailAlreadyExists", err)
+	}
+	if got, err := repo.FindByID(ctx, user.ID, repository.IncludeDeleted); err != nil || got == nil || !got.IsDeleted() {
+		t.Errorf("FindByID(IncludeDeleted) after failed Restore = (%v, %v), want deleted user", got, err)
+	}
+}
+
+func testPurge(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	deleted := seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com", baseTime)
+	seedUser(t, repo, "00000000-0000-0000-0000-000000000002", "Bob", "bob@example.com", baseTime)
+	if err := repo.Delete(ctx, deleted.ID, deleted.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// 削除日時が基準より新しいユーザーは残す
+	n, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil || n != 0 {
+		t.Errorf("Purge(before delete) = (%d, %v), want (0, nil)", n, err)
+	}
+
+	n, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Errorf("Purge = (%d, %v), want (1, nil)", n, err)
+	}
+	if got, err := repo.FindByID(ctx, deleted.ID, repository.IncludeDeleted); got != nil || err != nil {
+		t.Errorf("FindByID(IncludeDeleted) after Purge = (%v, %v), want (nil, nil)", got, err)
+	}
+	// 削除されていないユーザーは対象外
+	if got, err := repo.FindByID(ctx, "00000000-0000-0000-0000-000000000002"); err != nil || got == nil {
+		t.Errorf("FindByID(active) after Purge = (%v, %v), want user", got, err)
+	}
+}
+
+func testFindAllOrder(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	seedUser(t, repo, "00000000-0000-0000-0000-000000000001", "Old", "old@example.com", baseTime)
+	seedUser(t, repo, "00000000-0000-0000-0000-000000000002", "New", "new@example.com", baseTime.Add(2*time.Hour))
+	seedUser(t, repo, "00000000-0000-0000-0000-000000000003", "Mid", "mid@example.com", baseTime.Add(time.Hour))
+
+	users, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	assertIDs(t, "FindAll", users, "00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000003", "00000000-0000-0000-0000-000000000001")
+}
+
+func testFindPage(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 7; i++ {
+		id := fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1)
+		domain := "example.com"
+		if i%2 == 1 {
+			domain = "example.org"
+		}
+		// 同じ作成日時のユーザーを含め、IDによる順序の決定を確認する
+		seedUser(t, repo, id, fmt.Sprintf("User %d", i%4), fmt.Sprintf("user%d@%s", i, domain), baseTime.Add(time.Duration(i/2)*time.Minute))
+		ids = append(ids, id)
+	}
+
+	// 作成日時の新しい順に、重複や欠落なくページを辿れること
+	want := []string{ids[6], ids[5], ids[4], ids[3], ids[2], ids[1], ids[0]}
+	assertIDs(t, "FindPage(created_at desc)", collectPages(t, repo, repository.UserPageQuery{
+		Limit: 3,
+		Sort:  repository.UserSortByCreatedAt,
+		Order: repository.SortDesc,
+	}), want...)
+
+	// 名前の昇順（同じ名前はIDの昇順）
+	assertIDs(t, "FindPage(name asc)", collectPages(t, repo, repository.UserPageQuery{
+		Limit: 2,
+		Sort:  repository.UserSortByName,
+		Order: repository.SortAsc,
+	}), ids[0], ids[4], ids[1], ids[5], ids[2], ids[6], ids[3])
+
+	// 絞り込み条件（大文字小文字を区別しない）
+	after := baseTime.Add(time.Minute)
+	users, err := repo.FindPage(ctx, repository.UserPageQuery{
+		Limit: 10,
+		Sort:  repository.UserSortByEmail,
+		Order: repository.SortAsc,
+		Filter: repository.UserFilter{
+			EmailDomain:  "EXAMPLE.com",
+			NamePrefix:   "user",
+			CreatedAfter: &after,
+		},
+	})
+	if err != nil {
+		t.Fatalf("FindPage(filter): %v", err)
+	}
+	assertIDs(t, "FindPage(filter)", users, ids[2], ids[4], ids[6])
+}
+
+// collectPages はカーソルを辿ってすべてのページを取得します
+func collectPages(t *testing.T, repo repository.UserRepository, q repository.UserPageQuery) []*entity.User {
+	t.Helper()
+
+	var all []*entity.User
+	for page := 0; page < 100; page++ {
+		users, err := repo.FindPage(context.Background(), q)
+		if err != nil {
+			t.Fatalf("FindPage: %v", err)
+		}
+		all = append(all, users...)
+		if len(users) < q.Limit {
+			return all
+		}
+		q.After = repository.NewUserCursor(users[len(users)-1], q.Sort)
+	}
+	t.Fatal("FindPage: too many pages")
+	return nil
+}
+
+// assertIDs はユーザーのIDが期待した順序で並んでいることを確認します
+func assertIDs(t *testing.T, name string, users []*entity.User, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(users))
+	for i, u := range users {
+		got[i] = u.ID
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("%s IDs = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"project_template/backend/domain/entity"
+	"project_template/backend/usecase/validator"
+)
+
+// CreateAPIKeyInput は API キーの作成のための入力データです
+// Scopes には users:read, users:write を指定し、ExpiresAt を省略した場合は有効期限のないキーを作成します
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name" validate:"required,max=255"`
+	Scopes    []string   `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Normalize は入力値を検証前に正規化します
+func (in *CreateAPIKeyInput) Normalize() {
+	in.Name = validator.NormalizeText(in.Name)
+	for i, s := range in.Scopes {
+		in.Scopes[i] = strings.TrimSpace(s)
+	}
+}
+
+// RevokeAPIKeyInput は API キーの失効のための入力データです
+type RevokeAPIKeyInput struct {
+	ID string `json:"id" validate:"required,max=36"`
+}
+
+// APIKeyOutput は API キーの出力データです（キーそのものは含みません）
+// Prefix はキーの先頭部分で、どのキーかを利用者が見分けるために使用します
+type APIKeyOutput struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewAPIKeyOutput はエンティティからDTOへの変換を行います
+func NewAPIKeyOutput(key *entity.APIKey) *APIKeyOutput {
+	scopes := make([]string, len(key.Scopes))
+	for i, s := range key.Scopes {
+		scopes[i] = string(s)
+	}
+	return &APIKeyOutput{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     entity.APIKeyPrefix + key.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+// APIKeysOutput は API キー一覧の出力データです
+type APIKeysOutput struct {
+	APIKeys []*APIKeyOutput `json:"api_keys"`
+}
+
+// CreatedAPIKeyOutput は作成した API キーの出力データです
+// Key はキーそのもので、作成時のレスポンスでのみ返します（再取得はできません）
+type CreatedAPIKeyOutput struct {
+	APIKey *APIKeyOutput `json:"api_key"`
+	Key    string        `json:"key"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "strings"
+
+// LoginInput はパスワードによるログインのための入力データです
+// パスワードは入力された値のまま照合するため正規化しません
+type LoginInput struct {
+	Email    string `json:"email" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,max=1024"`
+}
+
+// Normalize は入力値を検証前に正規化します
+func (in *LoginInput) Normalize() {
+	in.Email = strings.TrimSpace(in.Email)
+}
+
+// RefreshInput はアクセストークンの再発行のための入力データです
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=1024"`
+}
+
+// LogoutInput はログアウトのための入力データです
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=1024"`
+}
+
+// TokenOutput はログインと再発行で発行したトークンの出力データです
+// ExpiresIn はアクセストークンの有効期間（秒）です
+type TokenOutput struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
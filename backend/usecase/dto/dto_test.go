@@ -0,0 +1,32 @@
+package dto_test
+
+import (
+	"testing"
+
+	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/validator"
+)
+
+// TestInputTags は入力データの validate タグの誤りを、リクエストを処理する前に検出します
+func TestInputTags(t *testing.T) {
+	inputs := []interface{}{
+		dto.CreateUserInput{},
+		dto.GetUserInput{},
+		dto.GetUsersInput{},
+		dto.UpdateUserInput{},
+		dto.DeleteUserInput{},
+		dto.RestoreUserInput{},
+		dto.CreateSessionInput{},
+		dto.RevokeSessionInput{},
+		dto.LoginInput{},
+		dto.RefreshInput{},
+		dto.LogoutInput{},
+		dto.CreateAPIKeyInput{},
+		dto.RevokeAPIKeyInput{},
+	}
+	for _, in := range inputs {
+		if err := validator.CheckTags(in); err != nil {
+			t.Errorf("%T: %v", in, err)
+		}
+	}
+}
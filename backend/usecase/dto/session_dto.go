@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"project_template/backend/domain/entity"
+)
+
+// CreateSessionInput は Cookie 認証のセッションを作成するログインのための入力データです
+// UserAgent と IPAddress はリクエストから設定し、セッション一覧で端末の識別に使用します
+type CreateSessionInput struct {
+	LoginInput
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// RevokeSessionInput はセッションの失効のための入力データです
+type RevokeSessionInput struct {
+	ID string `json:"id" validate:"required,max=36"`
+}
+
+// SessionOutput はセッションの出力データです
+// Current はリクエストの認証に使用しているセッションかどうかです
+type SessionOutput struct {
+	ID            string    `json:"id"`
+	UserAgent     string    `json:"user_agent"`
+	IPAddress     string    `json:"ip_address"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+	IdleExpiresAt time.Time `json:"idle_expires_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Current       bool      `json:"current"`
+}
+
+// NewSessionOutput はエンティティからDTOへの変換を行います
+func NewSessionOutput(session *entity.Session, currentID string) *SessionOutput {
+	return &SessionOutput{
+		ID:            session.ID,
+		UserAgent:     session.UserAgent,
+		IPAddress:     session.IPAddress,
+		CreatedAt:     session.CreatedAt,
+		LastSeenAt:    session.LastSeenAt,
+		IdleExpiresAt: session.IdleExpiresAt,
+		ExpiresAt:     session.ExpiresAt,
+		Current:       session.ID == currentID,
+	}
+}
+
+// SessionsOutput はセッション一覧の出力データです
+type SessionsOutput struct {
+	Sessions []*SessionOutput `json:"sessions"`
+}
+
+// CreatedSessionOutput は作成したセッションの出力データです
+// Token は Cookie に設定するセッショントークンで、レスポンスボディには含めません
+// CSRFToken は状態を変更するリクエストの X-CSRF-Token ヘッダーに指定させるトークンです
+type CreatedSessionOutput struct {
+	Session   *SessionOutput `json:"session"`
+	CSRFToken string         `json:"csrf_token"`
+	Token     string         `json:"-"`
+}
+
+// CurrentSessionOutput はリクエストの認証に使用しているセッションの出力データです
+// CSRFToken は状態を変更するリクエストの X-CSRF-Token ヘッダーに指定させるトークンです
+type CurrentSessionOutput struct {
+	Session   *SessionOutput `json:"session"`
+	CSRFToken string         `json:"csrf_token"`
+}
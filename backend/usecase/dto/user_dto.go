@@ -1,29 +1,106 @@
 package dto
 
 import (
+	"strings"
 	"time"
 
 	"project_template/backend/domain/entity"
+	"project_template/backend/usecase/validator"
 )
 
 // UserInput は新規ユーザー作成のための入力データです
+// Password は初期パスワードで、省略した場合はパスワードでログインできないユーザーを作成します
+// Role は省略した場合は一般ユーザー（member）です
 type CreateUserInput struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name     string `json:"name" validate:"required,max=255"`
+	Email    string `json:"email" validate:"required,max=255,email"`
+	Password string `json:"password"`
+	Role     string `json:"role" validate:"oneof=admin member read_only"`
+}
+
+// Normalize は入力値を検証前に正規化します
+// パスワードは入力された値のまま保存するため正規化しません
+func (in *CreateUserInput) Normalize() {
+	in.Name = validator.NormalizeText(in.Name)
+	in.Email = strings.TrimSpace(in.Email)
 }
 
 // GetUserInput はユーザー取得のための入力データです
+// IncludeDeleted が true の場合は削除済みのユーザーも取得します
 type GetUserInput struct {
-	ID string `json:"id"`
+	ID             string `json:"id" validate:"required,max=36"`
+	IncludeDeleted bool   `json:"include_deleted"`
+}
+
+// GetUsersInput はユーザー一覧取得のための入力データです
+// Cursor には前回の出力の NextCursor をそのまま指定します
+// IncludeDeleted が true の場合は削除済みのユーザーも含めます
+type GetUsersInput struct {
+	Limit          int        `json:"limit" validate:"min=0,max=100"`
+	Cursor         string     `json:"cursor" validate:"max=1024"`
+	Sort           string     `json:"sort"`
+	Order          string     `json:"order"`
+	EmailDomain    string     `json:"email_domain" validate:"max=255"`
+	NamePrefix     string     `json:"name_prefix" validate:"max=255"`
+	CreatedAfter   *time.Time `json:"created_after"`
+	CreatedBefore  *time.Time `json:"created_before"`
+	IncludeDeleted bool       `json:"include_deleted"`
+}
+
+// Normalize は入力値を検証前に正規化します
+func (in *GetUsersInput) Normalize() {
+	in.EmailDomain = strings.TrimSpace(in.EmailDomain)
+	in.NamePrefix = validator.NormalizeText(in.NamePrefix)
+}
+
+// UpdateUserInput はユーザー更新のための入力データです
+// Name, Email, Role が nil の場合は該当項目を変更しません
+// Version には取得時のバージョンを指定し、現在のバージョンと異なる場合は更新しません
+type UpdateUserInput struct {
+	ID      string  `json:"-" validate:"required,max=36"`
+	Version int64   `json:"-" validate:"min=1"`
+	Name    *string `json:"name" validate:"max=255"`
+	Email   *string `json:"email" validate:"max=255,email"`
+	Role    *string `json:"role" validate:"oneof=admin member read_only"`
+}
+
+// Normalize は入力値を検証前に正規化します
+func (in *UpdateUserInput) Normalize() {
+	if in.Name != nil {
+		name := validator.NormalizeText(*in.Name)
+		in.Name = &name
+	}
+	if in.Email != nil {
+		email := strings.TrimSpace(*in.Email)
+		in.Email = &email
+	}
+}
+
+// DeleteUserInput はユーザー削除のための入力データです
+// Version には取得時のバージョンを指定し、現在のバージョンと異なる場合は削除しません
+type DeleteUserInput struct {
+	ID      string `json:"id" validate:"required,max=36"`
+	Version int64  `json:"-" validate:"min=1"`
+}
+
+// RestoreUserInput は削除済みユーザーの復元のための入力データです
+// Version には取得時のバージョンを指定し、現在のバージョンと異なる場合は復元しません
+type RestoreUserInput struct {
+	ID      string `json:"id" validate:"required,max=36"`
+	Version int64  `json:"-" validate:"min=1"`
 }
 
 // UserOutput はユーザー情報の出力データです
+// DeletedAt は削除済みのユーザーの場合のみ出力します
 type UserOutput struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // NewUserOutput はエンティティからDTOへの変換を行います
@@ -32,23 +109,29 @@ func NewUserOutput(user *entity.User) *UserOutput {
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
+		Role:      string(user.Role),
+		Version:   user.Version,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
+		DeletedAt: user.DeletedAt,
 	}
 }
 
 // UsersOutput はユーザー一覧の出力データです
+// NextCursor は次ページが存在しない場合は空文字になります
 type UsersOutput struct {
-	Users []*UserOutput `json:"users"`
+	Users      []*UserOutput `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
 // NewUsersOutput はエンティティのスライスからDTOへの変換を行います
-func NewUsersOutput(users []*entity.User) *UsersOutput {
+func NewUsersOutput(users []*entity.User, nextCursor string) *UsersOutput {
 	userOutputs := make([]*UserOutput, len(users))
 	for i, user := range users {
 		userOutputs[i] = NewUserOutput(user)
 	}
 	return &UsersOutput{
-		Users: userOutputs,
+		Users:      userOutputs,
+		NextCursor: nextCursor,
 	}
 }
@@ -0,0 +1,175 @@
+package interactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/validator"
+)
+
+var (
+	ErrAPIKeyNotFound = repository.ErrAPIKeyNotFound
+	// ErrInvalidAPIKey は API キーが存在しない、失効している、または有効期限が切れている場合のエラーです
+	ErrInvalidAPIKey = apperror.Unauthorized("invalid API key")
+)
+
+const (
+	// apiKeyPrefixBytes は API キーの識別用の接頭辞のバイト数です（16進数で2倍の文字数）
+	apiKeyPrefixBytes = 6
+	// apiKeyTouchInterval は API キーの最終使用日時を更新する最小の間隔です
+	// リクエストごとの書き込みを避けるため、最終使用日時はこの間隔の精度で記録します
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyInteractor はサービス間の呼び出しに使用する API キーに関するユースケースを実装します
+// キーの作成・一覧の取得・失効は管理者のみが行えます
+type APIKeyInteractor struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewAPIKeyInteractor はAPIKeyInteractorを生成します
+func NewAPIKeyInteractor(apiKeyRepo repository.APIKeyRepository) *APIKeyInteractor {
+	return &APIKeyInteractor{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateAPIKey は API キーを作成します
+// キーそのものは出力データでのみ返し、保存するのはハッシュ値のみです
+func (i *APIKeyInteractor) CreateAPIKey(ctx context.Context, input *dto.CreateAPIKeyInput) (*dto.CreatedAPIKeyOutput, error) {
+	// 入力データの正規化と検証
+	input.Normalize()
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// 権限の確認
+	principal, err := auth.Authorize(ctx, auth.ActionManageAPIKeys, "")
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := newAPIKeyPrefix()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key prefix: %w", err)
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := entity.APIKeyPrefix + prefix + "_" + secret
+
+	scopes := make([]entity.APIKeyScope, len(input.Scopes))
+	for n, s := range input.Scopes {
+		scopes[n] = entity.APIKeyScope(s)
+	}
+	apiKey, err := entity.NewAPIKey(uuid.New().String(), input.Name, prefix, hashToken(key), scopes, principal.UserID, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedAPIKeyOutput{
+		APIKey: dto.NewAPIKeyOutput(apiKey),
+		Key:    key,
+	}, nil
+}
+
+// ListAPIKeys は失効したものを含むすべての API キーの一覧を返します
+func (i *APIKeyInteractor) ListAPIKeys(ctx context.Context) (*dto.APIKeysOutput, error) {
+	// 権限の確認
+	if _, err := auth.Authorize(ctx, auth.ActionManageAPIKeys, ""); err != nil {
+		return nil, err
+	}
+
+	keys, err := i.apiKeyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]*dto.APIKeyOutput, len(keys))
+	for n, key := range keys {
+		outputs[n] = dto.NewAPIKeyOutput(key)
+	}
+	return &dto.APIKeysOutput{APIKeys: outputs}, nil
+}
+
+// RevokeAPIKey は API キーを失効させます（既に失効している場合は何もしません）
+func (i *APIKeyInteractor) RevokeAPIKey(ctx context.Context, input *dto.RevokeAPIKeyInput) error {
+	// 入力データの検証
+	if err := validator.Validate(input); err != nil {
+		return err
+	}
+
+	// 権限の確認
+	if _, err := auth.Authorize(ctx, auth.ActionManageAPIKeys, ""); err != nil {
+		return err
+	}
+
+	return i.apiKeyRepo.Revoke(ctx, input.ID, time.Now())
+}
+
+// VerifyAPIKey は API キーを検証し、キーのスコープを持つ認証された主体を返します
+// 有効なキーの使用では、最終使用日時を更新します
+func (i *APIKeyInteractor) VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	prefix, ok := parseAPIKey(key)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	apiKey, err := i.apiKeyRepo.FindByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || subtle.ConstantTimeCompare([]byte(hashToken(key)), []byte(apiKey.SecretHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if apiKey.IsRevoked() || apiKey.IsExpired(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := i.apiKeyRepo.Touch(ctx, apiKey.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &auth.Principal{APIKeyID: apiKey.ID, Scopes: apiKey.Scopes}, nil
+}
+
+// newAPIKeyPrefix は API キーを識別するためのランダムな接頭辞を生成します
+// キーの区切り文字（_）を含まないよう16進数で表現します
+func newAPIKeyPrefix() (string, error) {
+	b := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parseAPIKey は "ak_<接頭辞>_<秘密の値>" の形式のキーから識別用の接頭辞を取り出します
+func parseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, entity.APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 2*apiKeyPrefixBytes || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
@@ -0,0 +1,289 @@
+package interactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/validator"
+)
+
+// ErrInvalidCredentials はメールアドレスまたはパスワードが正しくない場合のエラーです
+// どちらが誤っているかは区別せず、登録されているメールアドレスを推測できないようにします
+var ErrInvalidCredentials = apperror.Unauthorized("invalid email or password")
+
+// ErrInvalidRefreshToken はリフレッシュトークンが存在しない、失効している、または有効期限切れの場合のエラーです
+var ErrInvalidRefreshToken = apperror.Unauthorized("invalid refresh token")
+
+// ErrRefreshTokenReused は使用済みのリフレッシュトークンが再び使用された場合のエラーです
+// トークンが漏洩したとみなし、同じ系列のトークンをすべて失効させます
+var ErrRefreshTokenReused = apperror.Unauthorized("refresh token has already been used")
+
+const (
+	// tokenTypeBearer は発行するアクセストークンの種類です
+	tokenTypeBearer = "Bearer"
+	// opaqueTokenBytes はリフレッシュトークンやセッショントークンのランダムなバイト数です
+	opaqueTokenBytes = 32
+)
+
+// PasswordHasher はパスワードのハッシュ化と検証を行うインターフェースです
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) (bool, error)
+	// NeedsRehash はハッシュのパラメータが現在の設定と異なり、ハッシュ化し直す必要があるか判定します
+	NeedsRehash(hash string) bool
+}
+
+// AccessTokenIssuer はログインしたユーザーのアクセストークンを発行するインターフェースです
+type AccessTokenIssuer interface {
+	Issue(ctx context.Context, userID string, role entity.Role) (token string, expiresAt time.Time, err error)
+}
+
+// AuthInteractor はパスワードによる認証とトークンの発行に関するユースケースを実装します
+//
+// アクセストークンは有効期間の短い署名付きトークンで、失効させることはできません
+// リフレッシュトークンは使用するたびに新しいトークンに置き換え、ログアウトで系列ごと失効させます
+type AuthInteractor struct {
+	userRepo       repository.UserRepository
+	credentialRepo repository.CredentialRepository
+	refreshRepo    repository.RefreshTokenRepository
+	hasher         PasswordHasher
+	tokens         AccessTokenIssuer
+	txManager      repository.TxManager
+	refreshTTL     time.Duration
+	// dummyHash は存在しないユーザーのログインでも照合の時間をかけるためのハッシュです
+	dummyHash func() string
+}
+
+// NewAuthInteractor はAuthInteractorを生成します
+func NewAuthInteractor(
+	userRepo repository.UserRepository,
+	credentialRepo repository.CredentialRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	hasher PasswordHasher,
+	tokens AccessTokenIssuer,
+	txManager repository.TxManager,
+	refreshTTL time.Duration,
+) *AuthInteractor {
+	return &AuthInteractor{
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		refreshRepo:    refreshRepo,
+		hasher:         hasher,
+		tokens:         tokens,
+		txManager:      txManager,
+		refreshTTL:     refreshTTL,
+		dummyHash: sync.OnceValue(func() string {
+			hash, _ := hasher.Hash("dummy password for timing")
+			return hash
+		}),
+	}
+}
+
+// Login はメールアドレスとパスワードを照合し、アクセストークンと新しい系列のリフレッシュトークンを発行します
+func (i *AuthInteractor) Login(ctx context.Context, input *dto.LoginInput) (*dto.TokenOutput, error) {
+	user, err := i.VerifyCredentials(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := i.createRefreshToken(ctx, uuid.New().String(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return i.issue(ctx, user, refreshToken)
+}
+
+// VerifyCredentials はメールアドレスとパスワードを照合し、ログインするユーザーを返します
+// ハッシュのパラメータが変更されている場合は、照合に成功したパスワードを新しいパラメータでハッシュ化し直します
+func (i *AuthInteractor) VerifyCredentials(ctx context.Context, input *dto.LoginInput) (*entity.User, error) {
+	// 入力データの正規化と検証
+	input.Normalize()
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// 削除済みのユーザーはログインできない
+	user, err := i.userRepo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	var credential *entity.Credential
+	if user != nil {
+		credential, err = i.credentialRepo.FindByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if credential == nil {
+		// ユーザーが存在しない場合も同じ時間をかけ、応答時間から登録の有無を推測できないようにする
+		i.hasher.Verify(input.Password, i.dummyHash())
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := i.hasher.Verify(input.Password, credential.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	if i.hasher.NeedsRehash(credential.PasswordHash) {
+		// 再ハッシュに失敗してもログインは継続し、次回のログインで再試行する
+		if err := i.rehash(ctx, credential, input.Password); err != nil {
+			slog.WarnContext(ctx, "Failed to rehash password", "user_id", user.ID, "error", err)
+		}
+	}
+	return user, nil
+}
+
+// Refresh はリフレッシュトークンを新しいトークンに置き換え、アクセストークンを再発行します
+// 使用済みのトークンが使用された場合は、同じ系列のトークンをすべて失効させて ErrRefreshTokenReused を返します
+func (i *AuthInteractor) Refresh(ctx context.Context, input *dto.RefreshInput) (*dto.TokenOutput, error) {
+	// 入力データの検証
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	var (
+		user         *entity.User
+		refreshToken string
+		reused       *entity.RefreshToken
+	)
+	err := i.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := i.refreshRepo.FindByHash(ctx, hashToken(input.RefreshToken))
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if current == nil || current.IsRevoked() || current.IsExpired(now) {
+			return ErrInvalidRefreshToken
+		}
+
+		// 使用済みのトークンの再利用を検知した場合は系列を失効させ、その変更を確定させる
+		err = i.refreshRepo.MarkUsed(ctx, current.ID, now)
+		if current.IsUsed() || errors.Is(err, repository.ErrRefreshTokenNotActive) {
+			reused = current
+			return i.refreshRepo.RevokeFamily(ctx, current.FamilyID, now)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 削除されたユーザーのトークンは再発行しない
+		user, err = i.userRepo.FindByID(ctx, current.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrInvalidRefreshToken
+		}
+
+		refreshToken, err = i.createRefreshToken(ctx, current.FamilyID, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused != nil {
+		slog.WarnContext(ctx, "Refresh token reuse detected; revoked token family",
+			"user_id", reused.UserID, "family_id", reused.FamilyID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	return i.issue(ctx, user, refreshToken)
+}
+
+// issue はアクセストークンを発行し、リフレッシュトークンとともに出力データを生成します
+// アクセストークンには発行時点のユーザーのロールを含めます
+func (i *AuthInteractor) issue(ctx context.Context, user *entity.User, refreshToken string) (*dto.TokenOutput, error) {
+	token, expiresAt, err := i.tokens.Issue(ctx, user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TokenOutput{
+		AccessToken:  token,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(time.Until(expiresAt).Round(time.Second) / time.Second),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// createRefreshToken は系列に新しいリフレッシュトークンを保存し、トークンを返します
+// 保存するのはトークンのハッシュ値のみです
+func (i *AuthInteractor) createRefreshToken(ctx context.Context, familyID, userID string) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	refreshToken := entity.NewRefreshToken(uuid.New().String(), familyID, userID, hashToken(token), i.refreshTTL)
+	if err := i.refreshRepo.Create(ctx, refreshToken); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// newOpaqueToken は推測できないランダムなトークンを生成します
+func newOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken はトークンの保存・検索に使用するハッシュ値を返します
+// トークンは十分な長さのランダムな値のため、ソルトのない SHA-256 で推測できません
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// rehash はパスワードを現在のパラメータでハッシュ化し直して保存します
+func (i *AuthInteractor) rehash(ctx context.Context, credential *entity.Credential, password string) error {
+	hash, err := i.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	credential.ChangePasswordHash(hash)
+	return i.credentialRepo.Save(ctx, credential)
+}
+
+// Logout はリフレッシュトークンの系列を失効させます
+// 発行済みのアクセストークンは有効期限まで使用できます
+// 存在しない、または失効済みのトークンの場合も成功として扱います
+func (i *AuthInteractor) Logout(ctx context.Context, input *dto.LogoutInput) error {
+	// 入力データの検証
+	if err := validator.Validate(input); err != nil {
+		return err
+	}
+
+	token, err := i.refreshRepo.FindByHash(ctx, hashToken(input.RefreshToken))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.IsRevoked() {
+		return nil
+	}
+	return i.refreshRepo.RevokeFamily(ctx, token.FamilyID, time.Now())
+}
+
+// PurgeExpiredRefreshTokens は有効期限切れのリフレッシュトークンを削除し、削除した件数を返します
+func (i *AuthInteractor) PurgeExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	return i.refreshRepo.DeleteExpired(ctx, time.Now())
+}
@@ -0,0 +1,196 @@
+package interactor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project_template/backend/adapter/repository/memory"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/infrastructure/password"
+	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/interactor"
+)
+
+const testPassword = "correct horse battery staple"
+
+// testHashParams はテストを速くするため最小限のコストにした Argon2id のパラメータです
+var testHashParams = password.Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+// stubIssuer はユーザーIDを含む固定形式のアクセストークンを発行します
+type stubIssuer struct{}
+
+func (stubIssuer) Issue(ctx context.Context, userID string, role entity.Role) (string, time.Time, error) {
+	return "access-" + userID, time.Now().Add(time.Minute), nil
+}
+
+// authFixture はメモリのストレージとパスワードを登録したユーザーを持つ AuthInteractor です
+type authFixture struct {
+	users       repository.UserRepository
+	credentials repository.CredentialRepository
+	refresh     repository.RefreshTokenRepository
+	auth        *interactor.AuthInteractor
+	user        *entity.User
+}
+
+// newAuthFixture は registered のパラメータでハッシュ化したパスワードを登録し、current のパラメータで照合する AuthInteractor を生成します
+func newAuthFixture(t *testing.T, registered, current password.Params) *authFixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &authFixture{users: memory.NewUserRepository()}
+	f.credentials = memory.NewCredentialRepository(f.users)
+	f.refresh = memory.NewRefreshTokenRepository(f.users)
+
+	user, err := entity.NewUser("00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	if err := f.users.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	hash, err := password.NewArgon2id(registered).Hash(testPassword)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if err := f.credentials.Save(ctx, entity.NewCredential(user.ID, hash)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	f.user = user
+
+	f.auth = interactor.NewAuthInteractor(f.users, f.credentials, f.refresh, password.NewArgon2id(current), stubIssuer{}, memory.NewTxManager(), time.Hour)
+	return f
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t, testHashParams, testHashParams)
+
+	out, err := f.auth.Login(ctx, &dto.LoginInput{Email: " alice@example.com ", Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if out.AccessToken != "access-"+f.user.ID || out.TokenType != "Bearer" || out.RefreshToken == "" {
+		t.Errorf("Login = %+v, want access and refresh tokens", out)
+	}
+
+	// 登録されていないメールアドレスと誤ったパスワードは区別できない同じエラーにする
+	_, unknownErr := f.auth.Login(ctx, &dto.LoginInput{Email: "bob@example.com", Password: testPassword})
+	_, wrongErr := f.auth.Login(ctx, &dto.LoginInput{Email: "alice@example.com", Password: "wrong password"})
+	if !errors.Is(unknownErr, interactor.ErrInvalidCredentials) || !errors.Is(wrongErr, interactor.ErrInvalidCredentials) {
+		t.Fatalf("Login(unknown, wrong) = (%v, %v), want ErrInvalidCredentials", unknownErr, wrongErr)
+	}
+	if unknownErr.Error() != wrongErr.Error() {
+		t.Errorf("Login errors differ: %q and %q", unknownErr, wrongErr)
+	}
+}
+
+func TestLoginDeletedUser(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t, testHashParams, testHashParams)
+	if err := f.users.Delete(ctx, f.user.ID, f.user.Version); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// 削除済みのユーザーは正しいパスワードでもログインできない
+	if _, err := f.auth.Login(ctx, &dto.LoginInput{Email: "alice@example.com", Password: testPassword}); !errors.Is(err, interactor.ErrInvalidCredentials) {
+		t.Errorf("Login(deleted) = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLoginRehashesOutdatedHash(t *testing.T) {
+	ctx := context.Background()
+	current := testHashParams
+	current.Iterations = 2
+	f := newAuthFixture(t, testHashParams, current)
+	hasher := password.NewArgon2id(current)
+
+	before, err := f.credentials.FindByUserID(ctx, f.user.ID)
+	if err != nil || !hasher.NeedsRehash(before.PasswordHash) {
+		t.Fatalf("registered hash = (%v, %v), want outdated params", before, err)
+	}
+
+	// 誤ったパスワードではハッシュ化し直さない
+	f.auth.Login(ctx, &dto.LoginInput{Email: "alice@example.com", Password: "wrong password"})
+	if got, _ := f.credentials.FindByUserID(ctx, f.user.ID); got.PasswordHash != before.PasswordHash {
+		t.Error("hash changed after a failed login")
+	}
+
+	if _, err := f.auth.Login(ctx, &dto.LoginInput{Email: "alice@example.com", Password: testPassword}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	after, err := f.credentials.FindByUserID(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("FindByUserID: %v", err)
+	}
+	if hasher.NeedsRehash(after.PasswordHash) {
+		t.Errorf("hash %q still uses outdated params after login", after.PasswordHash)
+	}
+	if ok, err := hasher.Verify(testPassword, after.PasswordHash); err != nil || !ok {
+		t.Errorf("Verify(rehashed) = (%v, %v), want (true, nil)", ok, err)
+	}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t, testHashParams, testHashParams)
+	login, err := f.auth.Login(ctx, &dto.LoginInput{Email: "alice@example.com", Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	refreshed, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == login.RefreshToken {
+		t.Errorf("Refresh returned refresh token %q, want a new one", refreshed.RefreshToken)
+	}
+	if refreshed.AccessToken != "access-"+f.user.ID {
+		t.Errorf("AccessToken = %q, want one for %s", refreshed.AccessToken, f.user.ID)
+	}
+
+	// 置き換えたトークンで続けて再発行できる
+	if _, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: refreshed.RefreshToken}); err != nil {
+		t.Errorf("Refresh(rotated) = %v, want nil", err)
+	}
+	if _, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: "unknown"}); !errors.Is(err, interactor.ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(unknown) = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t, testHashParams, testHashParams)
+	login := func() *dto.TokenOutput {
+		out, err := f.auth.Login(ctx, &dto.LoginInput{Email: "alice@example.com", Password: testPassword})
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		return out
+	}
+	stolen := login()
+	otherDevice := login()
+
+	rotated, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: stolen.RefreshToken})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	// 使用済みのトークンの再利用は漏洩とみなし、系列のトークンをすべて失効させる
+	if _, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: stolen.RefreshToken}); !errors.Is(err, interactor.ErrRefreshTokenReused) {
+		t.Fatalf("Refresh(reused) = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: rotated.RefreshToken}); !errors.Is(err, interactor.ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(latest in revoked family) = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: stolen.RefreshToken}); !errors.Is(err, interactor.ErrInvalidRefreshToken) {
+		t.Errorf("Refresh(reused again) = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// 別のログインで発行した系列は影響を受けない
+	if _, err := f.auth.Refresh(ctx, &dto.RefreshInput{RefreshToken: otherDevice.RefreshToken}); err != nil {
+		t.Errorf("Refresh(other family) = %v, want nil", err)
+	}
+}
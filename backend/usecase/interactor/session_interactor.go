@@ -0,0 +1,210 @@
+package interactor
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/validator"
+)
+
+// ErrInvalidSession はセッションが存在しない、または失効している場合のエラーです
+var ErrInvalidSession = apperror.Unauthorized("invalid or expired session")
+
+// ErrUnauthenticated は認証が必要なユースケースを認証されていないリクエストから呼び出した場合のエラーです
+var ErrUnauthenticated = auth.ErrUnauthenticated
+
+// sessionTouchInterval はセッションの最終アクセス日時を更新する最小の間隔です
+// リクエストごとの書き込みを避けるため、無操作による有効期限はこの間隔の精度で延長します
+const sessionTouchInterval = time.Minute
+
+// CredentialVerifier はメールアドレスとパスワードを照合するインターフェースです
+type CredentialVerifier interface {
+	VerifyCredentials(ctx context.Context, input *dto.LoginInput) (*entity.User, error)
+}
+
+// SessionInteractor は Cookie 認証のサーバー側セッションに関するユースケースを実装します
+type SessionInteractor struct {
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	credentials     CredentialVerifier
+	idleTimeout     time.Duration
+	absoluteTimeout time.Duration
+}
+
+// NewSessionInteractor はSessionInteractorを生成します
+// idleTimeout は無操作で失効するまでの時間、absoluteTimeout はログインから失効するまでの時間です
+func NewSessionInteractor(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	credentials CredentialVerifier,
+	idleTimeout time.Duration,
+	absoluteTimeout time.Duration,
+) *SessionInteractor {
+	return &SessionInteractor{
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		credentials:     credentials,
+		idleTimeout:     idleTimeout,
+		absoluteTimeout: absoluteTimeout,
+	}
+}
+
+// CreateSession はメールアドレスとパスワードを照合し、新しいセッションを作成します
+func (i *SessionInteractor) CreateSession(ctx context.Context, input *dto.CreateSessionInput) (*dto.CreatedSessionOutput, error) {
+	user, err := i.credentials.VerifyCredentials(ctx, &input.LoginInput)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+	csrfToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+
+	session := entity.NewSession(
+		uuid.New().String(),
+		user.ID,
+		hashToken(token),
+		csrfToken,
+		truncate(input.UserAgent, 255),
+		input.IPAddress,
+		i.idleTimeout,
+		i.absoluteTimeout,
+	)
+	if err := i.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedSessionOutput{
+		Session:   dto.NewSessionOutput(session, session.ID),
+		CSRFToken: csrfToken,
+		Token:     token,
+	}, nil
+}
+
+// VerifySession はセッショントークンを検証し、認証された主体とセッションの CSRF トークンを返します
+// 有効なセッションへのアクセスでは、無操作による有効期限を延長します
+func (i *SessionInteractor) VerifySession(ctx context.Context, token string) (*auth.Principal, string, error) {
+	session, err := i.sessionRepo.FindByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	if session == nil || session.IsExpired(now) {
+		return nil, "", ErrInvalidSession
+	}
+
+	// 削除されたユーザーのセッションは使用できない
+	// ロールは変更がすぐに反映されるよう、セッションではなくユーザーから取得する
+	user, err := i.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user == nil {
+		return nil, "", ErrInvalidSession
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		session.Touch(now, i.idleTimeout)
+		if err := i.sessionRepo.Touch(ctx, session.ID, session.LastSeenAt, session.IdleExpiresAt); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return &auth.Principal{UserID: user.ID, SessionID: session.ID, Role: user.Role}, session.CSRFToken, nil
+}
+
+// ListSessions は認証されたユーザーの有効なセッションの一覧を返します
+func (i *SessionInteractor) ListSessions(ctx context.Context) (*dto.SessionsOutput, error) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	sessions, err := i.sessionRepo.ListByUserID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	outputs := make([]*dto.SessionOutput, 0, len(sessions))
+	for _, session := range sessions {
+		if session.IsExpired(now) {
+			continue
+		}
+		outputs = append(outputs, dto.NewSessionOutput(session, principal.SessionID))
+	}
+	return &dto.SessionsOutput{Sessions: outputs}, nil
+}
+
+// GetCurrentSession はリクエストの認証に使用しているセッションと、その CSRF トークンを返します
+// ページの再読み込みなどで CSRF トークンを失ったクライアントが取得し直すために使用します
+// セッション以外（アクセストークン・API キー）で認証されたリクエストでは ErrSessionNotFound を返します
+func (i *SessionInteractor) GetCurrentSession(ctx context.Context) (*dto.CurrentSessionOutput, error) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if principal.SessionID == "" {
+		return nil, repository.ErrSessionNotFound
+	}
+
+	sessions, err := i.sessionRepo.ListByUserID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, session := range sessions {
+		if session.ID == principal.SessionID && !session.IsExpired(now) {
+			return &dto.CurrentSessionOutput{
+				Session:   dto.NewSessionOutput(session, principal.SessionID),
+				CSRFToken: session.CSRFToken,
+			}, nil
+		}
+	}
+	return nil, repository.ErrSessionNotFound
+}
+
+// RevokeSession は認証されたユーザーのセッションを失効させます
+// 他のユーザーのセッションは存在しないものとして ErrSessionNotFound を返します
+func (i *SessionInteractor) RevokeSession(ctx context.Context, input *dto.RevokeSessionInput) error {
+	// 入力データの検証
+	if err := validator.Validate(input); err != nil {
+		return err
+	}
+
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	return i.sessionRepo.Delete(ctx, principal.UserID, input.ID)
+}
+
+// PurgeExpiredSessions は失効したセッションを削除し、削除した件数を返します
+func (i *SessionInteractor) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	return i.sessionRepo.DeleteExpired(ctx, time.Now())
+}
+
+// truncate は文字列を最大 n バイトに切り詰めます（UTF-8 の文字の途中では切りません）
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
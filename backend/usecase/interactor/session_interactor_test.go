@@ -0,0 +1,76 @@
+package interactor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project_template/backend/adapter/repository/memory"
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/interactor"
+)
+
+// stubCredentials は常に user を認証済みとして返します
+type stubCredentials struct {
+	user *entity.User
+}
+
+func (c stubCredentials) VerifyCredentials(ctx context.Context, input *dto.LoginInput) (*entity.User, error) {
+	return c.user, nil
+}
+
+func TestGetCurrentSessionReturnsCSRFToken(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepository()
+	user, err := entity.NewUser("00000000-0000-0000-0000-000000000001", "Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	sessions := interactor.NewSessionInteractor(users, memory.NewSessionRepository(users), stubCredentials{user: user}, time.Hour, 24*time.Hour)
+
+	created, err := sessions.CreateSession(ctx, &dto.CreateSessionInput{})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	principal, csrfToken, err := sessions.VerifySession(ctx, created.Token)
+	if err != nil {
+		t.Fatalf("VerifySession: %v", err)
+	}
+
+	// ログイン後に CSRF トークンを失っても、セッションの Cookie だけで取得し直せる
+	got, err := sessions.GetCurrentSession(auth.WithPrincipal(ctx, principal))
+	if err != nil {
+		t.Fatalf("GetCurrentSession: %v", err)
+	}
+	if got.CSRFToken != created.CSRFToken || got.CSRFToken != csrfToken {
+		t.Errorf("CSRFToken = %q, want %q", got.CSRFToken, created.CSRFToken)
+	}
+	if got.Session.ID != created.Session.ID || !got.Session.Current {
+		t.Errorf("Session = %+v, want current session %s", got.Session, created.Session.ID)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"unauthenticated", ctx, interactor.ErrUnauthenticated},
+		// アクセストークンや API キーで認証されたリクエストにはセッションがない
+		{"bearer token", auth.WithPrincipal(ctx, &auth.Principal{UserID: user.ID, Role: user.Role}), repository.ErrSessionNotFound},
+		{"revoked session", auth.WithPrincipal(ctx, &auth.Principal{UserID: user.ID, SessionID: "revoked", Role: user.Role}), repository.ErrSessionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sessions.GetCurrentSession(tt.ctx); !errors.Is(err, tt.want) {
+				t.Errorf("GetCurrentSession = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package interactor
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"project_template/backend/domain/repository"
+)
+
+// userCursorPayload はクライアントに返す不透明なカーソルの中身です
+// 発行時の並び替え条件と絞り込み条件のハッシュ値を含め、異なる条件での再利用を検出します
+type userCursorPayload struct {
+	Sort   repository.UserSortKey `json:"s"`
+	Order  repository.SortOrder   `json:"o"`
+	Filter string                 `json:"f"`
+	ID     string                 `json:"id"`
+	Str    string                 `json:"k,omitempty"`
+	Time   *time.Time             `json:"t,omitempty"`
+}
+
+// encodeUserCursor はページの起点をURLセーフな文字列に変換します
+func encodeUserCursor(query repository.UserPageQuery, c *repository.UserCursor) string {
+	p := userCursorPayload{
+		Sort:   query.Sort,
+		Order:  query.Order,
+		Filter: userFilterHash(query.Filter),
+		ID:     c.ID,
+		Str:    c.StringKey,
+	}
+	if !c.TimeKey.IsZero() {
+		t := c.TimeKey
+		p.Time = &t
+	}
+	b, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeUserCursor はカーソル文字列を解析し、並び替え条件と絞り込み条件が発行時と一致するか検証します
+func decodeUserCursor(s string, query repository.UserPageQuery) (*repository.UserCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var p userCursorPayload
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if p.ID == "" || p.Sort != query.Sort || p.Order != query.Order || p.Filter != userFilterHash(query.Filter) {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &repository.UserCursor{
+		ID:        p.ID,
+		StringKey: p.Str,
+	}
+	if p.Time != nil {
+		c.TimeKey = *p.Time
+	}
+	return c, nil
+}
+
+// userFilterHash は絞り込み条件を識別する短いハッシュ値を返します
+// 条件そのものはカーソルに含めず、一致するかどうかの判定にのみ使用します
+func userFilterHash(f repository.UserFilter) string {
+	h := sha256.New()
+	for _, v := range []string{
+		f.EmailDomain,
+		f.NamePrefix,
+		formatFilterTime(f.CreatedAfter),
+		formatFilterTime(f.CreatedBefore),
+		strconv.FormatBool(f.IncludeDeleted),
+	} {
+		// 区切りの NUL により項目の境界を曖昧にしない
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:12])
+}
+
+// formatFilterTime は日時の条件をタイムゾーンによらない文字列に変換します（未指定の場合は空）
+func formatFilterTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,146 @@
+package interactor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"project_template/backend/domain/repository"
+)
+
+func TestUserCursorRoundTrip(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		query  repository.UserPageQuery
+		cursor repository.UserCursor
+	}{
+		{
+			name:   "time key",
+			query:  repository.UserPageQuery{Sort: repository.UserSortByCreatedAt, Order: repository.SortDesc},
+			cursor: repository.UserCursor{ID: "user-1", TimeKey: time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)},
+		},
+		{
+			name: "string key with filters",
+			query: repository.UserPageQuery{
+				Sort:  repository.UserSortByName,
+				Order: repository.SortAsc,
+				Filter: repository.UserFilter{
+					EmailDomain:    "example.com",
+					NamePrefix:     "a",
+					CreatedAfter:   &after,
+					IncludeDeleted: true,
+				},
+			},
+			cursor: repository.UserCursor{ID: "user-2", StringKey: "alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeUserCursor(encodeUserCursor(tt.query, &tt.cursor), tt.query)
+			if err != nil {
+				t.Fatalf("decodeUserCursor: %v", err)
+			}
+			if got.ID != tt.cursor.ID || got.StringKey != tt.cursor.StringKey || !got.TimeKey.Equal(tt.cursor.TimeKey) {
+				t.Errorf("decodeUserCursor = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestUserCursorRejectsDifferentQuery(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	base := repository.UserPageQuery{
+		Sort:  repository.UserSortByCreatedAt,
+		Order: repository.SortDesc,
+		Filter: repository.UserFilter{
+			EmailDomain:   "example.com",
+			NamePrefix:    "a",
+			CreatedAfter:  &after,
+			CreatedBefore: &before,
+		},
+	}
+	cursor := encodeUserCursor(base, &repository.UserCursor{ID: "user-1", TimeKey: after})
+
+	// 同じ時刻であればタイムゾーンが異なっても同じ条件とみなす
+	sameInstant := base
+	afterJST := after.In(time.FixedZone("JST", 9*60*60))
+	sameInstant.Filter.CreatedAfter = &afterJST
+	if _, err := decodeUserCursor(cursor, sameInstant); err != nil {
+		t.Errorf("decodeUserCursor(same instant in another zone) = %v, want nil", err)
+	}
+
+	later := after.Add(time.Second)
+	tests := []struct {
+		name   string
+		modify func(q *repository.UserPageQuery)
+	}{
+		{"sort", func(q *repository.UserPageQuery) { q.Sort = repository.UserSortByName }},
+		{"order", func(q *repository.UserPageQuery) { q.Order = repository.SortAsc }},
+		{"email domain", func(q *repository.UserPageQuery) { q.Filter.EmailDomain = "example.org" }},
+		{"name prefix", func(q *repository.UserPageQuery) { q.Filter.NamePrefix = "b" }},
+		// 項目の境界をずらしても同じハッシュ値にならない
+		{"shifted fields", func(q *repository.UserPageQuery) { q.Filter.EmailDomain, q.Filter.NamePrefix = "example.coma", "" }},
+		{"created after", func(q *repository.UserPageQuery) { q.Filter.CreatedAfter = &later }},
+		{"created after removed", func(q *repository.UserPageQuery) { q.Filter.CreatedAfter = nil }},
+		{"created before", func(q *repository.UserPageQuery) { q.Filter.CreatedBefore = &later }},
+		{"include deleted", func(q *repository.UserPageQuery) { q.Filter.IncludeDeleted = true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := base
+			tt.modify(&query)
+			if _, err := decodeUserCursor(cursor, query); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeUserCursor = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestUserCursorRejectsTampering(t *testing.T) {
+	query := repository.UserPageQuery{
+		Sort:   repository.UserSortByEmail,
+		Order:  repository.SortAsc,
+		Filter: repository.UserFilter{EmailDomain: "example.com"},
+	}
+	valid := encodeUserCursor(query, &repository.UserCursor{ID: "user-1", StringKey: "a@example.com"})
+
+	// 正しく発行されたカーソルの中身を書き換えて再エンコードする
+	rewrite := func(modify func(p *userCursorPayload)) string {
+		b, err := base64.RawURLEncoding.DecodeString(valid)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		var p userCursorPayload
+		if err := json.Unmarshal(b, &p); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		modify(&p)
+		b, _ = json.Marshal(p)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("not json"))},
+		{"empty id", rewrite(func(p *userCursorPayload) { p.ID = "" })},
+		{"filter hash removed", rewrite(func(p *userCursorPayload) { p.Filter = "" })},
+		{"filter hash replaced", rewrite(func(p *userCursorPayload) {
+			p.Filter = userFilterHash(repository.UserFilter{EmailDomain: "example.org"})
+		})},
+		{"sort replaced", rewrite(func(p *userCursorPayload) { p.Sort = repository.UserSortByName })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeUserCursor(tt.cursor, query); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeUserCursor = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
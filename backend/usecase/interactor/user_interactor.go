@@ -3,40 +3,101 @@ package interactor
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/auth"
 	"project_template/backend/domain/entity"
 	"project_template/backend/domain/repository"
 	"project_template/backend/domain/services"
 	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/validator"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound        = repository.ErrUserNotFound
+	ErrUserVersionConflict = repository.ErrUserVersionConflict
+	ErrUserNotDeleted      = repository.ErrUserNotDeleted
+	ErrInvalidCursor       = apperror.InvalidArgument("invalid cursor")
+	ErrInvalidListQuery    = apperror.InvalidArgument("invalid list query")
+	ErrForbidden           = auth.ErrForbidden
+	ErrCannotChangeOwnRole = auth.ErrCannotChangeOwnRole
 )
 
+const (
+	// defaultUsersLimit は一覧取得の件数が未指定の場合の件数です
+	defaultUsersLimit = 20
+	// maxUsersLimit は一覧取得で一度に返す最大件数です
+	maxUsersLimit = 100
+)
+
+// UserMetrics はユーザーに関するドメインのメトリクスを記録するインターフェースです
+type UserMetrics interface {
+	UserCreated()
+	EmailConflict()
+}
+
 // UserInteractor はユーザーに関するユースケースを実装します
+// 作成・更新・削除は、存在や一意性の確認と書き込みを1つのトランザクションで実行します
+// 各ユースケースは ctx の認証された主体のロールで auth パッケージのポリシーを確認し、
+// 許可されていない場合は ErrForbidden を返します
 type UserInteractor struct {
-	userRepo    repository.UserRepository
-	userService services.UserServiceInterface
+	userRepo       repository.UserRepository
+	credentialRepo repository.CredentialRepository
+	userService    services.UserServiceInterface
+	passwordPolicy *services.PasswordPolicy
+	hasher         PasswordHasher
+	txManager      repository.TxManager
+	metrics        UserMetrics
 }
 
 // NewUserInteractor はUserInteractorを生成します
 func NewUserInteractor(
 	userRepo repository.UserRepository,
+	credentialRepo repository.CredentialRepository,
 	userService services.UserServiceInterface,
+	passwordPolicy *services.PasswordPolicy,
+	hasher PasswordHasher,
+	txManager repository.TxManager,
+	metrics UserMetrics,
 ) *UserInteractor {
 	return &UserInteractor{
-		userRepo:    userRepo,
-		userService: userService,
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		userService:    userService,
+		passwordPolicy: passwordPolicy,
+		hasher:         hasher,
+		txManager:      txManager,
+		metrics:        metrics,
 	}
 }
 
 // GetUser はユーザー情報を取得します
 func (i *UserInteractor) GetUser(ctx context.Context, input *dto.GetUserInput) (*dto.UserOutput, error) {
+	// 入力データの検証
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// 権限の確認
+	if _, err := auth.Authorize(ctx, auth.ActionReadUser, input.ID); err != nil {
+		return nil, err
+	}
+	if input.IncludeDeleted {
+		if _, err := auth.Authorize(ctx, auth.ActionReadDeleted, input.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// リポジトリからユーザーを取得
-	user, err := i.userRepo.FindByID(ctx, input.ID)
+	var opts []repository.FindOption
+	if input.IncludeDeleted {
+		opts = append(opts, repository.IncludeDeleted)
+	}
+	user, err := i.userRepo.FindByID(ctx, input.ID, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -48,35 +109,376 @@ func (i *UserInteractor) GetUser(ctx context.Context, input *dto.GetUserInput) (
 	return dto.NewUserOutput(user), nil
 }
 
-// GetUsers はすべてのユーザー情報を取得します
-func (i *UserInteractor) GetUsers(ctx context.Context) (*dto.UsersOutput, error) {
-	// リポジトリからすべてのユーザーを取得
-	users, err := i.userRepo.FindAll(ctx)
+// GetUsers は条件に一致するユーザー情報をページ単位で取得します
+func (i *UserInteractor) GetUsers(ctx context.Context, input *dto.GetUsersInput) (*dto.UsersOutput, error) {
+	// 入力データの正規化と検証
+	input.Normalize()
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// 権限の確認
+	if _, err := auth.Authorize(ctx, auth.ActionListUsers, ""); err != nil {
+		return nil, err
+	}
+	if input.IncludeDeleted {
+		if _, err := auth.Authorize(ctx, auth.ActionReadDeleted, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	query, err := newUserPageQuery(input)
 	if err != nil {
 		return nil, err
 	}
 
+	// 次ページの有無を判定するため1件多く取得する
+	limit := query.Limit
+	query.Limit = limit + 1
+	users, err := i.userRepo.FindPage(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextCursor string
+	if len(users) > limit {
+		users = users[:limit]
+		last := repository.NewUserCursor(users[limit-1], query.Sort)
+		nextCursor = encodeUserCursor(query, last)
+	}
+
 	// ドメインオブジェクトをDTOに変換して返却
-	return dto.NewUsersOutput(users), nil
+	return dto.NewUsersOutput(users, nextCursor), nil
+}
+
+// newUserPageQuery は入力データを検証し、リポジトリの検索条件に変換します
+func newUserPageQuery(input *dto.GetUsersInput) (repository.UserPageQuery, error) {
+	query := repository.UserPageQuery{
+		Limit: input.Limit,
+		Sort:  repository.UserSortKey(input.Sort),
+		Order: repository.SortOrder(input.Order),
+		Filter: repository.UserFilter{
+			EmailDomain:    input.EmailDomain,
+			NamePrefix:     input.NamePrefix,
+			CreatedAfter:   input.CreatedAfter,
+			CreatedBefore:  input.CreatedBefore,
+			IncludeDeleted: input.IncludeDeleted,
+		},
+	}
+
+	// 未指定の項目はデフォルト値を使用
+	if query.Limit == 0 {
+		query.Limit = defaultUsersLimit
+	}
+	if query.Sort == "" {
+		query.Sort = repository.UserSortByCreatedAt
+	}
+	if query.Order == "" {
+		query.Order = repository.SortDesc
+	}
+
+	if query.Limit < 0 || query.Limit > maxUsersLimit || !query.Sort.Valid() || !query.Order.Valid() {
+		return query, ErrInvalidListQuery
+	}
+
+	if input.Cursor != "" {
+		after, err := decodeUserCursor(input.Cursor, query)
+		if err != nil {
+			return query, err
+		}
+		query.After = after
+	}
+
+	return query, nil
 }
 
 // CreateUser は新規ユーザーを作成します
 func (i *UserInteractor) CreateUser(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error) {
-	// メールアドレスの一意性を確認
-	if !i.userService.ValidateUniqueEmail(ctx, input.Email) {
-		return nil, services.ErrEmailAlreadyExists
+	// 入力データの正規化と検証
+	input.Normalize()
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// 権限の確認
+	if _, err := auth.Authorize(ctx, auth.ActionCreateUser, ""); err != nil {
+		return nil, err
+	}
+	if input.Role != "" {
+		if _, err := auth.Authorize(ctx, auth.ActionChangeRole, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return i.createUser(ctx, input)
+}
+
+// EnsureAdmin は入力データのメールアドレスのユーザーが存在しない場合に、管理者として作成します
+// 起動時に最初の管理者を用意するためのもので、認可の確認は行いません
+// 作成したユーザーを返し、すでに同じメールアドレスのユーザーが存在する場合は、ロールを変更せずに nil を返します
+func (i *UserInteractor) EnsureAdmin(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error) {
+	// 入力データの正規化と検証
+	input.Role = string(entity.RoleAdmin)
+	input.Normalize()
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	existing, err := i.userRepo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, nil
+	}
+
+	output, err := i.createUser(ctx, input)
+	if err != nil {
+		// 同時に起動した他のインスタンスが作成した場合
+		if errors.Is(err, services.ErrEmailAlreadyExists) {
+			return nil, nil
+		}
+		return nil, err
 	}
+	return output, nil
+}
 
+// createUser は検証済みの入力データからユーザーを作成します
+func (i *UserInteractor) createUser(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error) {
 	// ユーザーエンティティを作成
 	userID := uuid.New().String()
-	user := entity.NewUser(userID, input.Name, input.Email)
+	user, err := entity.NewUser(userID, input.Name, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	if input.Role != "" {
+		if err := user.ChangeRole(entity.Role(input.Role)); err != nil {
+			return nil, err
+		}
+	}
+
+	// 初期パスワードが指定された場合は、ポリシーを確認してハッシュ化する
+	// ハッシュ化には時間がかかるため、トランザクションの開始前に行う
+	var credential *entity.Credential
+	if input.Password != "" {
+		if err := i.passwordPolicy.Validate(input.Password, input.Email); err != nil {
+			return nil, err
+		}
+		hash, err := i.hasher.Hash(input.Password)
+		if err != nil {
+			return nil, err
+		}
+		credential = entity.NewCredential(userID, hash)
+	}
+
+	err = i.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// メールアドレスの一意性を確認
+		// 同時登録による重複はリポジトリが一意制約違反として ErrEmailAlreadyExists を返す
+		unique, err := i.userService.ValidateUniqueEmail(ctx, input.Email)
+		if err != nil {
+			return err
+		}
+		if !unique {
+			return services.ErrEmailAlreadyExists
+		}
+
+		// リポジトリに保存
+		if err := i.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		if credential != nil {
+			return i.credentialRepo.Save(ctx, credential)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyExists) {
+			i.metrics.EmailConflict()
+		}
+		return nil, err
+	}
+	i.metrics.UserCreated()
+
+	// ドメインオブジェクトをDTOに変換して返却
+	return dto.NewUserOutput(user), nil
+}
+
+// UpdateUser は既存ユーザーの情報を更新します
+func (i *UserInteractor) UpdateUser(ctx context.Context, input *dto.UpdateUserInput) (*dto.UserOutput, error) {
+	// 入力データの正規化と検証
+	input.Normalize()
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// 権限の確認
+	principal, err := auth.Authorize(ctx, auth.ActionUpdateUser, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Role != nil {
+		if _, err := auth.Authorize(ctx, auth.ActionChangeRole, input.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var user *entity.User
+	err = i.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// リポジトリから更新対象のユーザーを取得
+		var err error
+		user, err = i.userRepo.FindByID(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		// 取得後に他の操作で変更されている場合は、その変更を上書きしない
+		if user.Version != input.Version {
+			return ErrUserVersionConflict
+		}
 
-	// リポジトリに保存
-	err := i.userRepo.Create(ctx, user)
+		if input.Email != nil && !strings.EqualFold(*input.Email, user.Email) {
+			// 変更後のメールアドレスの一意性を確認
+			unique, err := i.userService.ValidateUniqueEmail(ctx, *input.Email)
+			if err != nil {
+				return err
+			}
+			if !unique {
+				return services.ErrEmailAlreadyExists
+			}
+		}
+
+		// エンティティの振る舞いを通して変更を適用
+		if input.Name != nil {
+			if err := user.ChangeName(*input.Name); err != nil {
+				return err
+			}
+		}
+		if input.Email != nil {
+			if err := user.ChangeEmail(*input.Email); err != nil {
+				return err
+			}
+		}
+		if input.Role != nil && entity.Role(*input.Role) != user.Role {
+			if err := principal.AuthorizeRoleChange(user.ID); err != nil {
+				return err
+			}
+			if err := user.ChangeRole(entity.Role(*input.Role)); err != nil {
+				return err
+			}
+		}
+
+		// リポジトリに保存
+		return i.userRepo.Update(ctx, user)
+	})
 	if err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyExists) {
+			i.metrics.EmailConflict()
+		}
 		return nil, err
 	}
 
 	// ドメインオブジェクトをDTOに変換して返却
 	return dto.NewUserOutput(user), nil
 }
+
+// DeleteUser はユーザーを削除します
+// 削除したユーザーは保持期間が経過するまで RestoreUser で復元できます
+func (i *UserInteractor) DeleteUser(ctx context.Context, input *dto.DeleteUserInput) error {
+	// 入力データの検証
+	if err := validator.Validate(input); err != nil {
+		return err
+	}
+
+	// 権限の確認
+	if _, err := auth.Authorize(ctx, auth.ActionDeleteUser, input.ID); err != nil {
+		return err
+	}
+
+	return i.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// 削除対象のユーザーが存在するか確認
+		user, err := i.userRepo.FindByID(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if user.Version != input.Version {
+			return ErrUserVersionConflict
+		}
+
+		// リポジトリから削除
+		return i.userRepo.Delete(ctx, input.ID, input.Version)
+	})
+}
+
+// RestoreUser は削除済みのユーザーを復元します
+func (i *UserInteractor) RestoreUser(ctx context.Context, input *dto.RestoreUserInput) (*dto.UserOutput, error) {
+	// 入力データの検証
+	if err := validator.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// 権限の確認
+	if _, err := auth.Authorize(ctx, auth.ActionRestoreUser, input.ID); err != nil {
+		return nil, err
+	}
+
+	var user *entity.User
+	err := i.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// 復元対象のユーザーを取得
+		var err error
+		user, err = i.userRepo.FindByID(ctx, input.ID, repository.IncludeDeleted)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if !user.IsDeleted() {
+			return ErrUserNotDeleted
+		}
+		if user.Version != input.Version {
+			return ErrUserVersionConflict
+		}
+
+		// 削除後に同じメールアドレスで作成されたユーザーがいる場合は復元できない
+		unique, err := i.userService.ValidateUniqueEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+		if !unique {
+			return services.ErrEmailAlreadyExists
+		}
+
+		// リポジトリで復元し、更新後の状態を取得
+		if err := i.userRepo.Restore(ctx, input.ID, input.Version); err != nil {
+			return err
+		}
+		user, err = i.userRepo.FindByID(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyExists) {
+			i.metrics.EmailConflict()
+		}
+		return nil, err
+	}
+
+	// ドメインオブジェクトをDTOに変換して返却
+	return dto.NewUserOutput(user), nil
+}
+
+// PurgeDeletedUsers は削除から retention 以上経過したユーザーを完全に削除し、削除した件数を返します
+// 定期実行のワーカーから呼び出すため、認可の確認は行いません
+func (i *UserInteractor) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	return i.userRepo.Purge(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,167 @@
+package interactor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"project_template/backend/adapter/repository/memory"
+	"project_template/backend/domain/apperror"
+	"project_template/backend/domain/auth"
+	"project_template/backend/domain/entity"
+	"project_template/backend/domain/repository"
+	"project_template/backend/domain/services"
+	"project_template/backend/usecase/dto"
+	"project_template/backend/usecase/interactor"
+)
+
+// countingMetrics は記録されたメトリクスを数えます
+type countingMetrics struct {
+	created, conflicts atomic.Int64
+}
+
+func (m *countingMetrics) UserCreated()   { m.created.Add(1) }
+func (m *countingMetrics) EmailConflict() { m.conflicts.Add(1) }
+
+// adminContext は管理者として認証されたコンテキストを返します
+func adminContext() context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{
+		UserID: "00000000-0000-0000-0000-000000000001",
+		Role:   entity.RoleAdmin,
+	})
+}
+
+// newUserInteractor はメモリのストレージを使用する UserInteractor を生成します
+func newUserInteractor(users repository.UserRepository, metrics interactor.UserMetrics) *interactor.UserInteractor {
+	return interactor.NewUserInteractor(
+		users,
+		memory.NewCredentialRepository(users),
+		services.NewUserService(users),
+		services.NewPasswordPolicy(12, 128),
+		nil, // パスワードを指定しないためハッシュ化は行わない
+		memory.NewTxManager(),
+		metrics,
+	)
+}
+
+// TestCreateUserConcurrentSameEmail はユースケースのエラーとメトリクスの扱いを確認します
+// メモリ実装のトランザクションは直列化されるため、一意制約による検出は各リポジトリの ConcurrentCreateSameEmail で確認します
+func TestCreateUserConcurrentSameEmail(t *testing.T) {
+	users := memory.NewUserRepository()
+	metrics := &countingMetrics{}
+	userInteractor := newUserInteractor(users, metrics)
+
+	const n = 20
+	ctx := adminContext()
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = userInteractor.CreateUser(ctx, &dto.CreateUserInput{
+				Name:  fmt.Sprintf("User %d", i),
+				Email: "same@example.com",
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// 同じメールアドレスの同時登録は1件のみ成功し、残りは一意制約違反になる
+	succeeded := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, services.ErrEmailAlreadyExists):
+		default:
+			t.Errorf("CreateUser #%d = %v, want nil or ErrEmailAlreadyExists", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("succeeded = %d, want 1", succeeded)
+	}
+	if got := metrics.created.Load(); got != 1 {
+		t.Errorf("UserCreated recorded %d times, want 1", got)
+	}
+	if got := metrics.conflicts.Load(); got != n-1 {
+		t.Errorf("EmailConflict recorded %d times, want %d", got, n-1)
+	}
+}
+
+func TestEnsureAdmin(t *testing.T) {
+	ctx := context.Background()
+	userInteractor := newUserInteractor(memory.NewUserRepository(), &countingMetrics{})
+	input := func() *dto.CreateUserInput {
+		return &dto.CreateUserInput{Name: "admin", Email: "Admin@example.com"}
+	}
+
+	admin, err := userInteractor.EnsureAdmin(ctx, input())
+	if err != nil || admin == nil {
+		t.Fatalf("EnsureAdmin = (%v, %v), want created user", admin, err)
+	}
+	if admin.ID == "" || admin.Role != string(entity.RoleAdmin) {
+		t.Errorf("EnsureAdmin = %+v, want admin with ID", admin)
+	}
+
+	// 既に存在する場合は作成せず nil を返す
+	if again, err := userInteractor.EnsureAdmin(ctx, input()); err != nil || again != nil {
+		t.Errorf("EnsureAdmin(existing) = (%v, %v), want (nil, nil)", again, err)
+	}
+}
+
+func TestUpdateUserCannotChangeOwnRole(t *testing.T) {
+	userInteractor := newUserInteractor(memory.NewUserRepository(), &countingMetrics{})
+	admin, err := userInteractor.EnsureAdmin(context.Background(), &dto.CreateUserInput{Name: "admin", Email: "admin@example.com"})
+	if err != nil || admin == nil {
+		t.Fatalf("EnsureAdmin = (%v, %v)", admin, err)
+	}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: admin.ID, Role: entity.RoleAdmin})
+	role := func(r entity.Role) *string {
+		s := string(r)
+		return &s
+	}
+
+	// 管理者は自身を降格できない
+	_, err = userInteractor.UpdateUser(ctx, &dto.UpdateUserInput{ID: admin.ID, Version: admin.Version, Role: role(entity.RoleMember)})
+	if !errors.Is(err, interactor.ErrCannotChangeOwnRole) {
+		t.Errorf("UpdateUser(own role) = %v, want ErrCannotChangeOwnRole", err)
+	}
+
+	// ロールを変更しない更新は許可する
+	updated, err := userInteractor.UpdateUser(ctx, &dto.UpdateUserInput{ID: admin.ID, Version: admin.Version, Role: role(entity.RoleAdmin)})
+	if err != nil || updated.Role != string(entity.RoleAdmin) {
+		t.Errorf("UpdateUser(same role) = (%v, %v), want admin", updated, err)
+	}
+}
+
+func TestGetUsersRejectsCursorFromOtherFilter(t *testing.T) {
+	ctx := adminContext()
+	userInteractor := newUserInteractor(memory.NewUserRepository(), &countingMetrics{})
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.org"} {
+		if _, err := userInteractor.CreateUser(ctx, &dto.CreateUserInput{Name: email, Email: email}); err != nil {
+			t.Fatalf("CreateUser(%s): %v", email, err)
+		}
+	}
+
+	page, err := userInteractor.GetUsers(ctx, &dto.GetUsersInput{Limit: 1, EmailDomain: "example.com"})
+	if err != nil || page.NextCursor == "" {
+		t.Fatalf("GetUsers = (%+v, %v), want next cursor", page, err)
+	}
+
+	// 同じ条件では次のページを取得できる
+	if _, err := userInteractor.GetUsers(ctx, &dto.GetUsersInput{Limit: 1, EmailDomain: "example.com", Cursor: page.NextCursor}); err != nil {
+		t.Errorf("GetUsers(same filter) = %v, want nil", err)
+	}
+	// 異なる絞り込み条件では 400 になる
+	_, err = userInteractor.GetUsers(ctx, &dto.GetUsersInput{Limit: 1, EmailDomain: "example.org", Cursor: page.NextCursor})
+	if !errors.Is(err, interactor.ErrInvalidCursor) || apperror.KindOf(err) != apperror.KindInvalidArgument {
+		t.Errorf("GetUsers(other filter) = %v, want ErrInvalidCursor", err)
+	}
+}
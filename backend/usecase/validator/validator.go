@@ -0,0 +1,241 @@
+package validator
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"project_template/backend/domain/apperror"
+)
+
+// ErrValidation は入力データの検証に失敗した場合のエラーです
+var ErrValidation = apperror.Validation("validation failed")
+
+// Validate は構造体の validate タグに従って各フィールドを検証します
+// 違反はすべて収集し、フィールドごとの FieldError を持つ検証エラーとして返します
+//
+// 利用できるルール:
+//   - required: 空文字・nil・ゼロ値を許可しない
+//   - max=N:    文字列の場合は文字数、数値の場合は値の上限
+//   - min=N:    文字列の場合は文字数、数値の場合は値の下限
+//   - email:    RFC 5322 のアドレス形式（表示名なし）
+//   - oneof=A B: 空白区切りの値のいずれか（空文字は許可）
+//
+// ポインタのフィールドが nil の場合は required 以外のルールを適用しません
+// タグの誤り（未知のルール、不正なパラメーター、型に適用できないルール）は検証エラーではなく通常のエラーとして返します
+func Validate(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validator: expected struct, got %s", rv.Kind())
+	}
+
+	var fields []apperror.FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		rules, err := parseRules(sf)
+		if err != nil {
+			return err
+		}
+		if len(rules) > 0 {
+			fields = append(fields, validateField(fieldName(sf), rv.Field(i), rules)...)
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(ErrValidation.Message, fields...)
+	}
+	return nil
+}
+
+// CheckTags は構造体の validate タグがすべて有効か確認します
+// タグの誤りは Validate でもエラーになりますが、リクエストを処理する前にテストで検出するために使用します
+func CheckTags(v interface{}) error {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return fmt.Errorf("validator: expected struct, got %v", rt)
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		if _, err := parseRules(rt.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rule は validate タグの1つのルールです
+type rule struct {
+	name  string
+	param string
+}
+
+// parseRules はフィールドの validate タグを解析し、各ルールがフィールドの型に適用できるか確認します
+func parseRules(sf reflect.StructField) ([]rule, error) {
+	tag := sf.Tag.Get("validate")
+	if tag == "" || tag == "-" {
+		return nil, nil
+	}
+
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var rules []rule
+	for _, s := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(s, "=")
+		if err := checkRule(name, param, t); err != nil {
+			return nil, fmt.Errorf("validator: field %s: %w", sf.Name, err)
+		}
+		rules = append(rules, rule{name: name, param: param})
+	}
+	return rules, nil
+}
+
+// checkRule はルールのパラメーターと、ルールを型 t に適用できるかを確認します
+func checkRule(name, param string, t reflect.Type) error {
+	switch name {
+	case "required":
+		return nil
+	case "max", "min":
+		if _, err := strconv.Atoi(param); err != nil {
+			return fmt.Errorf("invalid %s parameter %q", name, param)
+		}
+		switch t.Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return nil
+		}
+	case "email":
+		if t.Kind() == reflect.String {
+			return nil
+		}
+	case "oneof":
+		if len(strings.Fields(param)) == 0 {
+			return fmt.Errorf("oneof requires at least one value")
+		}
+		if t.Kind() == reflect.String {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", name)
+	}
+	return fmt.Errorf("%s is not supported for %s", name, t.Kind())
+}
+
+// validateField は1つのフィールドに解析済みのルールを順に適用します
+func validateField(name string, fv reflect.Value, rules []rule) []apperror.FieldError {
+	var errs []apperror.FieldError
+	for _, r := range rules {
+		if r.name == "required" {
+			if isEmpty(fv) {
+				errs = append(errs, apperror.FieldError{Field: name, Rule: "required", Message: "must not be empty"})
+				// 値がない場合、他のルールは評価しない
+				return errs
+			}
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				return errs
+			}
+			fv = fv.Elem()
+		}
+
+		if msg, ok := applyRule(r, fv); !ok {
+			errs = append(errs, apperror.FieldError{Field: name, Rule: r.name, Message: msg})
+		}
+	}
+	return errs
+}
+
+// applyRule は checkRule で確認済みのルールを評価し、違反した場合はメッセージと false を返します
+func applyRule(r rule, fv reflect.Value) (string, bool) {
+	switch r.name {
+	case "max", "min":
+		n, _ := strconv.Atoi(r.param)
+		var size int
+		if fv.Kind() == reflect.String {
+			size = utf8.RuneCountInString(fv.String())
+		} else {
+			size = int(fv.Int())
+		}
+		if r.name == "max" && size > n {
+			return fmt.Sprintf("must be at most %d", n), false
+		}
+		if r.name == "min" && size < n {
+			return fmt.Sprintf("must be at least %d", n), false
+		}
+	case "email":
+		if s := fv.String(); s != "" && !IsEmail(s) {
+			return "must be a valid email address", false
+		}
+	case "oneof":
+		if s := fv.String(); s != "" && !containsWord(r.param, s) {
+			return "must be one of " + r.param, false
+		}
+	}
+	return "", true
+}
+
+// containsWord は空白区切りの list に s が含まれるか判定します
+func containsWord(list, s string) bool {
+	for _, w := range strings.Fields(list) {
+		if w == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty はフィールドが未入力か判定します
+func isEmpty(fv reflect.Value) bool {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return true
+		}
+		fv = fv.Elem()
+	}
+	if fv.Kind() == reflect.String {
+		return strings.TrimSpace(fv.String()) == ""
+	}
+	return fv.IsZero()
+}
+
+// fieldName はエラーに表示するフィールド名を json タグから決定します
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// IsEmail はRFC 5322 のアドレス形式か判定します
+// "表示名 <address>" の形式は受け付けません
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return false
+	}
+	return addr.Name == "" && addr.Address == s
+}
+
+// NormalizeText はテキストをUnicode NFCに正規化し、制御文字と前後の空白を取り除きます
+func NormalizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(norm.NFC.String(s))
+}
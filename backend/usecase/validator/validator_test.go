@@ -0,0 +1,165 @@
+package validator_test
+
+import (
+	"strings"
+	"testing"
+
+	"project_template/backend/domain/apperror"
+	"project_template/backend/usecase/validator"
+)
+
+// sample はすべてのルールを使用する検証対象です
+type sample struct {
+	Name    string  `json:"name" validate:"required,max=5"`
+	Code    string  `json:"code" validate:"min=2"`
+	Age     int     `json:"age" validate:"min=1,max=120"`
+	Email   string  `json:"email" validate:"email"`
+	Role    string  `json:"role" validate:"oneof=admin member"`
+	Nick    *string `json:"nick" validate:"max=3"`
+	Contact *string `json:"contact,omitempty" validate:"required,email"`
+	NoJSON  string  `validate:"max=1"`
+	Skipped string  `json:"skipped" validate:"-"`
+	Free    string  `json:"free"`
+}
+
+func ptr(s string) *string { return &s }
+
+// valid はすべてのルールを満たす値を返します
+func valid() sample {
+	return sample{Name: "Alice", Code: "ab", Age: 30, Email: "a@example.com", Role: "admin", Contact: ptr("c@example.com")}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(s *sample)
+		want   []apperror.FieldError
+	}{
+		{"valid", func(s *sample) {}, nil},
+		{"required empty", func(s *sample) { s.Name = "" }, []apperror.FieldError{{Field: "name", Rule: "required", Message: "must not be empty"}}},
+		{"required blank", func(s *sample) { s.Name = "  " }, []apperror.FieldError{{Field: "name", Rule: "required", Message: "must not be empty"}}},
+		// 値がない場合は他のルールを評価しない
+		{"required nil pointer", func(s *sample) { s.Contact = nil }, []apperror.FieldError{{Field: "contact", Rule: "required", Message: "must not be empty"}}},
+		// max と min は文字列ではバイト数ではなく文字数
+		{"max string runes", func(s *sample) { s.Name = "あいうえお" }, nil},
+		{"max string", func(s *sample) { s.Name = "Alice!" }, []apperror.FieldError{{Field: "name", Rule: "max", Message: "must be at most 5"}}},
+		{"min string", func(s *sample) { s.Code = "a" }, []apperror.FieldError{{Field: "code", Rule: "min", Message: "must be at least 2"}}},
+		{"min int", func(s *sample) { s.Age = 0 }, []apperror.FieldError{{Field: "age", Rule: "min", Message: "must be at least 1"}}},
+		{"max int", func(s *sample) { s.Age = 121 }, []apperror.FieldError{{Field: "age", Rule: "max", Message: "must be at most 120"}}},
+		{"email", func(s *sample) { s.Email = "not-an-email" }, []apperror.FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}}},
+		{"email display name", func(s *sample) { s.Email = "Alice <a@example.com>" }, []apperror.FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}}},
+		{"email empty", func(s *sample) { s.Email = "" }, nil},
+		{"oneof", func(s *sample) { s.Role = "owner" }, []apperror.FieldError{{Field: "role", Rule: "oneof", Message: "must be one of admin member"}}},
+		{"oneof empty", func(s *sample) { s.Role = "" }, nil},
+		// nil のポインタには required 以外のルールを適用しない
+		{"nil pointer", func(s *sample) { s.Nick = nil }, nil},
+		{"pointer", func(s *sample) { s.Nick = ptr("long") }, []apperror.FieldError{{Field: "nick", Rule: "max", Message: "must be at most 3"}}},
+		{"pointer email", func(s *sample) { s.Contact = ptr("bad") }, []apperror.FieldError{{Field: "contact", Rule: "email", Message: "must be a valid email address"}}},
+		// json タグがない場合はフィールド名を使用する
+		{"field without json tag", func(s *sample) { s.NoJSON = "ab" }, []apperror.FieldError{{Field: "NoJSON", Rule: "max", Message: "must be at most 1"}}},
+		{"skipped and untagged", func(s *sample) { s.Skipped, s.Free = strings.Repeat("x", 100), strings.Repeat("x", 100) }, nil},
+		// 違反はすべて収集する
+		{"multiple", func(s *sample) { s.Name, s.Age, s.Role = "", 0, "owner" }, []apperror.FieldError{
+			{Field: "name", Rule: "required", Message: "must not be empty"},
+			{Field: "age", Rule: "min", Message: "must be at least 1"},
+			{Field: "role", Rule: "oneof", Message: "must be one of admin member"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(&s)
+			err := validator.Validate(&s)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			appErr, ok := apperror.As(err)
+			if !ok || appErr.Kind != apperror.KindValidation || appErr.Message != validator.ErrValidation.Message {
+				t.Fatalf("Validate = %v, want validation error", err)
+			}
+			if len(appErr.Fields) != len(tt.want) {
+				t.Fatalf("Fields = %+v, want %+v", appErr.Fields, tt.want)
+			}
+			for i := range tt.want {
+				if appErr.Fields[i] != tt.want[i] {
+					t.Errorf("Fields[%d] = %+v, want %+v", i, appErr.Fields[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRejectsInvalidTags(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"unknown rule", &struct {
+			Name string `validate:"requird"`
+		}{}, `unknown rule "requird"`},
+		{"invalid parameter", &struct {
+			Name string `validate:"max=ten"`
+		}{}, `invalid max parameter "ten"`},
+		{"missing parameter", &struct {
+			Name string `validate:"min"`
+		}{}, `invalid min parameter ""`},
+		{"max on unsupported kind", &struct {
+			Tags []string `validate:"max=3"`
+		}{}, "max is not supported for slice"},
+		{"email on int", &struct {
+			Age int `validate:"email"`
+		}{}, "email is not supported for int"},
+		{"oneof without values", &struct {
+			Role string `validate:"oneof="`
+		}{}, "oneof requires at least one value"},
+		{"oneof on pointer to int", &struct {
+			Age *int `validate:"oneof=1 2"`
+		}{}, "oneof is not supported for int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// タグの誤りはパニックではなく、検証エラーでもない通常のエラーになる
+			err := validator.Validate(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate = %v, want error containing %q", err, tt.want)
+			}
+			if _, ok := apperror.As(err); ok {
+				t.Errorf("Validate = %v, want non-validation error", err)
+			}
+			if err := validator.CheckTags(tt.v); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CheckTags = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	if err := validator.CheckTags(sample{}); err != nil {
+		t.Errorf("CheckTags(sample) = %v, want nil", err)
+	}
+	if err := validator.Validate("not a struct"); err == nil {
+		t.Error("Validate(string) = nil, want error")
+	}
+	if err := validator.CheckTags(42); err == nil {
+		t.Error("CheckTags(int) = nil, want error")
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  Alice  ", "Alice"},
+		{"A\x00li\tce\n", "Alice"},
+		// NFD の濁点を結合した NFC の文字にする
+		{"\u30ab\u3099", "\u30ac"},
+		{"\xff\xfeAlice", "Alice"},
+	}
+	for _, tt := range tests {
+		if got := validator.NormalizeText(tt.in); got != tt.want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}